@@ -18,13 +18,19 @@ package cmd
 
 import (
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
+	"regexp"
 	"slices"
+	"strconv"
 	"strings"
+	"text/tabwriter"
 
 	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw"
 	"github.com/samber/lo"
 	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
 	"k8s.io/cli-runtime/pkg/printers"
 	"k8s.io/client-go/tools/clientcmd"
@@ -47,6 +53,11 @@ type PrintRunner struct {
 	// The path to the input yaml config file. Value assigned via --input-file flag
 	inputFile string
 
+	// The path to a kustomization directory. Value assigned via --kustomize flag.
+	// When set, the kustomization is built in memory and the resulting objects
+	// are read instead of an input file.
+	kustomizeDir string
+
 	// The namespace used to query Gateway API objects. Value assigned via
 	// --namespace/-n flag.
 	// On absence, the current user active namespace is used.
@@ -67,6 +78,19 @@ type PrintRunner struct {
 
 	// Provider specific flags --<provider>-<flag>.
 	providerSpecificFlags map[string]*string
+
+	// contexts are the kubeconfig contexts to read resources from. Value
+	// assigned via --context flag. When empty, the current context is used.
+	contexts []string
+
+	// outputDir is the directory where the resources converted from every
+	// context are written. Value assigned via --output-dir flag.
+	outputDir string
+
+	// preserveIngressAddresses indicates whether the load balancer addresses of
+	// the Ingresses are copied to the generated Gateways. Value assigned via
+	// --preserve-ingress-addresses flag.
+	preserveIngressAddresses bool
 }
 
 // PrintGatewayAPIObjects performs necessary steps to digest and print
@@ -78,31 +102,152 @@ func (pr *PrintRunner) PrintGatewayAPIObjects(cmd *cobra.Command, _ []string) er
 	if err != nil {
 		return fmt.Errorf("failed to initialize resrouce printer: %w", err)
 	}
+
+	if len(pr.contexts) > 0 {
+		return pr.printClusters(cmd)
+	}
+
 	err = pr.initializeNamespaceFilter()
 	if err != nil {
 		return fmt.Errorf("failed to initialize namespace filter: %w", err)
 	}
 
-	gatewayResources, err := i2gw.ToGatewayAPIResources(cmd.Context(), pr.namespaceFilter, pr.inputFile, pr.providers, pr.getProviderSpecificFlags())
+	var (
+		gatewayResources   []i2gw.GatewayResources
+		notificationTables map[string]string
+	)
+	if pr.kustomizeDir != "" {
+		gatewayResources, notificationTables, err = i2gw.ToGatewayAPIResourcesFromKustomization(cmd.Context(), pr.namespaceFilter, pr.kustomizeDir, pr.providers, pr.getProviderSpecificFlags(), pr.getConversionOptions())
+	} else {
+		gatewayResources, notificationTables, err = i2gw.ToGatewayAPIResources(cmd.Context(), pr.namespaceFilter, pr.inputFile, pr.providers, pr.getProviderSpecificFlags(), pr.getConversionOptions())
+	}
+	printNotificationTables(os.Stderr, notificationTables)
 	if err != nil {
 		return err
 	}
 
-	pr.outputResult(gatewayResources)
+	pr.outputResult(os.Stdout, gatewayResources, pr.namespaceFilter)
 
 	return nil
 }
 
-func (pr *PrintRunner) outputResult(gatewayResources []i2gw.GatewayResources) {
+// printClusters converts the resources of every context given with --context.
+// The resources of each cluster are written to their own file in the output
+// directory, followed by a summary of the Ingresses found across the clusters.
+// With a single context and no output directory, the resources are printed out.
+func (pr *PrintRunner) printClusters(cmd *cobra.Command) error {
+	var clusterContexts []i2gw.ClusterContext
+	for _, kubeContext := range pr.contexts {
+		namespace, err := pr.namespaceFilterForContext(kubeContext)
+		if err != nil {
+			return fmt.Errorf("failed to initialize namespace filter for context %s: %w", kubeContext, err)
+		}
+		clusterContexts = append(clusterContexts, i2gw.ClusterContext{Name: kubeContext, Namespace: namespace})
+	}
+
+	clusterResources, err := i2gw.ToGatewayAPIResourcesFromContexts(cmd.Context(), clusterContexts, pr.providers, pr.getProviderSpecificFlags(), pr.getConversionOptions())
+	if err != nil {
+		return err
+	}
+	for _, cr := range clusterResources {
+		printNotificationTables(os.Stderr, cr.NotificationTables)
+	}
+
+	if pr.outputDir == "" {
+		pr.outputResult(os.Stdout, clusterResources[0].GatewayResources, clusterResources[0].Context.Namespace)
+		return nil
+	}
+
+	if err := os.MkdirAll(pr.outputDir, 0o755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+	for _, cr := range clusterResources {
+		filename := filepath.Join(pr.outputDir, fmt.Sprintf("%s.%s", contextFileName(cr.Context.Name), pr.outputFileExtension()))
+		f, err := os.Create(filename)
+		if err != nil {
+			return fmt.Errorf("failed to create output file for context %s: %w", cr.Context.Name, err)
+		}
+		pr.outputResult(f, cr.GatewayResources, cr.Context.Namespace)
+		if err := f.Close(); err != nil {
+			return fmt.Errorf("failed to write output file for context %s: %w", cr.Context.Name, err)
+		}
+		fmt.Printf("# Wrote resources converted from context %s to %s\n", cr.Context.Name, filename)
+	}
+
+	printClusterSummary(os.Stdout, clusterResources)
+	return nil
+}
+
+// printNotificationTables prints the notification tables of every provider,
+// sorted by provider name.
+func printNotificationTables(w io.Writer, notificationTables map[string]string) {
+	providers := lo.Keys(notificationTables)
+	slices.Sort(providers)
+	for _, provider := range providers {
+		fmt.Fprintln(w, notificationTables[provider])
+	}
+}
+
+func (pr *PrintRunner) outputFileExtension() string {
+	if pr.outputFormat == "json" {
+		return "json"
+	}
+	return "yaml"
+}
+
+var unsafeFileNameChars = regexp.MustCompile(`[^a-zA-Z0-9._-]+`)
+
+// contextFileName turns a kubeconfig context name, which may contain characters
+// such as ':' or '/', into a file name.
+func contextFileName(kubeContext string) string {
+	return unsafeFileNameChars.ReplaceAllString(kubeContext, "-")
+}
+
+// printClusterSummary prints which Ingress patterns and annotations are used in
+// which cluster, as the number of Ingresses using them in every cluster.
+func printClusterSummary(w io.Writer, clusterResources []i2gw.ClusterResources) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	defer tw.Flush()
+
+	header := []string{"# INGRESS PATTERN/ANNOTATION"}
+	totals := []string{"# Ingresses"}
+	patterns := sets.New[string]()
+	annotations := sets.New[string]()
+	for _, cr := range clusterResources {
+		header = append(header, cr.Context.Name)
+		totals = append(totals, strconv.Itoa(cr.IngressSummary.Ingresses))
+		patterns.Insert(lo.Keys(cr.IngressSummary.Patterns)...)
+		annotations.Insert(lo.Keys(cr.IngressSummary.Annotations)...)
+	}
+	fmt.Fprintln(tw, strings.Join(header, "\t"))
+	fmt.Fprintln(tw, strings.Join(totals, "\t"))
+
+	for _, pattern := range sets.List(patterns) {
+		row := []string{fmt.Sprintf("# %s", pattern)}
+		for _, cr := range clusterResources {
+			row = append(row, strconv.Itoa(cr.IngressSummary.Patterns[pattern]))
+		}
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	for _, annotation := range sets.List(annotations) {
+		row := []string{fmt.Sprintf("# %s", annotation)}
+		for _, cr := range clusterResources {
+			row = append(row, strconv.Itoa(cr.IngressSummary.Annotations[annotation]))
+		}
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+}
+
+func (pr *PrintRunner) outputResult(w io.Writer, gatewayResources []i2gw.GatewayResources, namespaceFilter string) {
 	resourceCount := 0
 
 	for _, r := range gatewayResources {
 		resourceCount += len(r.GatewayClasses)
 		for _, gatewayClass := range r.GatewayClasses {
 			gatewayClass := gatewayClass
-			err := pr.resourcePrinter.PrintObj(&gatewayClass, os.Stdout)
+			err := pr.resourcePrinter.PrintObj(&gatewayClass, w)
 			if err != nil {
-				fmt.Printf("# Error printing %s GatewayClass: %v\n", gatewayClass.Name, err)
+				fmt.Fprintf(w, "# Error printing %s GatewayClass: %v\n", gatewayClass.Name, err)
 			}
 		}
 	}
@@ -111,9 +256,9 @@ func (pr *PrintRunner) outputResult(gatewayResources []i2gw.GatewayResources) {
 		resourceCount += len(r.Gateways)
 		for _, gateway := range r.Gateways {
 			gateway := gateway
-			err := pr.resourcePrinter.PrintObj(&gateway, os.Stdout)
+			err := pr.resourcePrinter.PrintObj(&gateway, w)
 			if err != nil {
-				fmt.Printf("# Error printing %s Gateway: %v\n", gateway.Name, err)
+				fmt.Fprintf(w, "# Error printing %s Gateway: %v\n", gateway.Name, err)
 			}
 		}
 	}
@@ -122,9 +267,20 @@ func (pr *PrintRunner) outputResult(gatewayResources []i2gw.GatewayResources) {
 		resourceCount += len(r.HTTPRoutes)
 		for _, httpRoute := range r.HTTPRoutes {
 			httpRoute := httpRoute
-			err := pr.resourcePrinter.PrintObj(&httpRoute, os.Stdout)
+			err := pr.resourcePrinter.PrintObj(&httpRoute, w)
 			if err != nil {
-				fmt.Printf("# Error printing %s HTTPRoute: %v\n", httpRoute.Name, err)
+				fmt.Fprintf(w, "# Error printing %s HTTPRoute: %v\n", httpRoute.Name, err)
+			}
+		}
+	}
+
+	for _, r := range gatewayResources {
+		resourceCount += len(r.GRPCRoutes)
+		for _, grpcRoute := range r.GRPCRoutes {
+			grpcRoute := grpcRoute
+			err := pr.resourcePrinter.PrintObj(&grpcRoute, w)
+			if err != nil {
+				fmt.Fprintf(w, "# Error printing %s GRPCRoute: %v\n", grpcRoute.Name, err)
 			}
 		}
 	}
@@ -133,9 +289,9 @@ func (pr *PrintRunner) outputResult(gatewayResources []i2gw.GatewayResources) {
 		resourceCount += len(r.TLSRoutes)
 		for _, tlsRoute := range r.TLSRoutes {
 			tlsRoute := tlsRoute
-			err := pr.resourcePrinter.PrintObj(&tlsRoute, os.Stdout)
+			err := pr.resourcePrinter.PrintObj(&tlsRoute, w)
 			if err != nil {
-				fmt.Printf("# Error printing %s TLSRoute: %v\n", tlsRoute.Name, err)
+				fmt.Fprintf(w, "# Error printing %s TLSRoute: %v\n", tlsRoute.Name, err)
 			}
 		}
 	}
@@ -144,9 +300,9 @@ func (pr *PrintRunner) outputResult(gatewayResources []i2gw.GatewayResources) {
 		resourceCount += len(r.TCPRoutes)
 		for _, tcpRoute := range r.TCPRoutes {
 			tcpRoute := tcpRoute
-			err := pr.resourcePrinter.PrintObj(&tcpRoute, os.Stdout)
+			err := pr.resourcePrinter.PrintObj(&tcpRoute, w)
 			if err != nil {
-				fmt.Printf("# Error printing %s TCPRoute: %v\n", tcpRoute.Name, err)
+				fmt.Fprintf(w, "# Error printing %s TCPRoute: %v\n", tcpRoute.Name, err)
 			}
 		}
 	}
@@ -155,9 +311,20 @@ func (pr *PrintRunner) outputResult(gatewayResources []i2gw.GatewayResources) {
 		resourceCount += len(r.UDPRoutes)
 		for _, udpRoute := range r.UDPRoutes {
 			udpRoute := udpRoute
-			err := pr.resourcePrinter.PrintObj(&udpRoute, os.Stdout)
+			err := pr.resourcePrinter.PrintObj(&udpRoute, w)
 			if err != nil {
-				fmt.Printf("# Error printing %s UDPRoute: %v\n", udpRoute.Name, err)
+				fmt.Fprintf(w, "# Error printing %s UDPRoute: %v\n", udpRoute.Name, err)
+			}
+		}
+	}
+
+	for _, r := range gatewayResources {
+		resourceCount += len(r.BackendTLSPolicies)
+		for _, backendTLSPolicy := range r.BackendTLSPolicies {
+			backendTLSPolicy := backendTLSPolicy
+			err := pr.resourcePrinter.PrintObj(&backendTLSPolicy, w)
+			if err != nil {
+				fmt.Fprintf(w, "# Error printing %s BackendTLSPolicy: %v\n", backendTLSPolicy.Name, err)
 			}
 		}
 	}
@@ -166,19 +333,30 @@ func (pr *PrintRunner) outputResult(gatewayResources []i2gw.GatewayResources) {
 		resourceCount += len(r.ReferenceGrants)
 		for _, referenceGrant := range r.ReferenceGrants {
 			referenceGrant := referenceGrant
-			err := pr.resourcePrinter.PrintObj(&referenceGrant, os.Stdout)
+			err := pr.resourcePrinter.PrintObj(&referenceGrant, w)
+			if err != nil {
+				fmt.Fprintf(w, "# Error printing %s ReferenceGrant: %v\n", referenceGrant.Name, err)
+			}
+		}
+	}
+
+	for _, r := range gatewayResources {
+		resourceCount += len(r.ExtensionResources)
+		for _, extensionResource := range r.ExtensionResources {
+			extensionResource := extensionResource
+			err := pr.resourcePrinter.PrintObj(&extensionResource, w)
 			if err != nil {
-				fmt.Printf("# Error printing %s ReferenceGrant: %v\n", referenceGrant.Name, err)
+				fmt.Fprintf(w, "# Error printing %s %s: %v\n", extensionResource.GetName(), extensionResource.GetKind(), err)
 			}
 		}
 	}
 
 	if resourceCount == 0 {
 		msg := "No resources found"
-		if pr.namespaceFilter != "" {
-			msg = fmt.Sprintf("%s in %s namespace", msg, pr.namespaceFilter)
+		if namespaceFilter != "" {
+			msg = fmt.Sprintf("%s in %s namespace", msg, namespaceFilter)
 		}
-		fmt.Println(msg)
+		fmt.Fprintln(w, msg)
 	}
 }
 
@@ -202,7 +380,7 @@ func (pr *PrintRunner) initializeResourcePrinter() error {
 // 1. If the --all-namespaces flag is used, it processes all resources, regardless of whether they are from the cluster or file.
 // 2. If namespace is specified, it filters resources based on that namespace.
 // 3. If no namespace is specified and reading from the cluster, it attempts to get the namespace from the cluster; if unsuccessful, initialization fails.
-// 4. If no namespace is specified and reading from a file, it attempts to get the namespace from the cluster; if unsuccessful, it reads all resources.
+// 4. If no namespace is specified and reading from a file or a kustomization, it attempts to get the namespace from the cluster; if unsuccessful, it reads all resources.
 func (pr *PrintRunner) initializeNamespaceFilter() error {
 	// When we should use all namespaces, empty string is used as the filter.
 	if pr.allNamespaces {
@@ -213,7 +391,7 @@ func (pr *PrintRunner) initializeNamespaceFilter() error {
 	// If namespace flag is not specified, try to use the default namespace from the cluster
 	if pr.namespace == "" {
 		ns, err := getNamespaceInCurrentContext()
-		if err != nil && pr.inputFile == "" {
+		if err != nil && pr.inputFile == "" && pr.kustomizeDir == "" {
 			// When asked to read from the cluster, but getting the current namespace
 			// failed for whatever reason - do not process the request.
 			return err
@@ -228,6 +406,20 @@ func (pr *PrintRunner) initializeNamespaceFilter() error {
 	return nil
 }
 
+// namespaceFilterForContext returns the namespace filter to use when reading
+// from the cluster of the given kubeconfig context. It follows the same rules as
+// initializeNamespaceFilter, using the namespace of the given context instead of
+// the current one.
+func (pr *PrintRunner) namespaceFilterForContext(kubeContext string) (string, error) {
+	if pr.allNamespaces {
+		return "", nil
+	}
+	if pr.namespace != "" {
+		return pr.namespace, nil
+	}
+	return getNamespaceInContext(kubeContext)
+}
+
 func newPrintCommand() *cobra.Command {
 	pr := &PrintRunner{}
 	var printFlags genericclioptions.JSONYamlPrintFlags
@@ -244,6 +436,9 @@ func newPrintCommand() *cobra.Command {
 			if openAPIExist && len(pr.providers) != 1 {
 				return fmt.Errorf("openapi3 must be the only provider when specified")
 			}
+			if len(pr.contexts) > 1 && pr.outputDir == "" {
+				return fmt.Errorf("--output-dir is required when more than one context is specified")
+			}
 			return nil
 		},
 	}
@@ -254,6 +449,9 @@ func newPrintCommand() *cobra.Command {
 	cmd.Flags().StringVar(&pr.inputFile, "input-file", "",
 		`Path to the manifest file. When set, the tool will read ingresses from the file instead of reading from the cluster. Supported files are yaml and json.`)
 
+	cmd.Flags().StringVar(&pr.kustomizeDir, "kustomize", "",
+		`Path to a kustomization directory. When set, the tool will build the kustomization and read ingresses from the resulting objects instead of reading from the cluster.`)
+
 	cmd.Flags().StringVarP(&pr.namespace, "namespace", "n", "",
 		`If present, the namespace scope for this CLI request.`)
 
@@ -261,6 +459,18 @@ func newPrintCommand() *cobra.Command {
 		`If present, list the requested object(s) across all namespaces. Namespace in current context is ignored even
 if specified with --namespace.`)
 
+	cmd.Flags().StringSliceVar(&pr.contexts, "context", []string{},
+		`The kubeconfig contexts to read resources from. Can be repeated or comma-separated. When more than one context is
+specified, the resources of every cluster are converted separately and written to --output-dir.`)
+
+	cmd.Flags().StringVar(&pr.outputDir, "output-dir", "",
+		`Directory where the resources converted from every context are written, one file per context. A summary of the
+Ingress patterns and annotations used in every cluster is printed out.`)
+
+	cmd.Flags().BoolVar(&pr.preserveIngressAddresses, "preserve-ingress-addresses", false,
+		`If present, the load balancer addresses found in the status of the Ingresses are set as the addresses of the
+generated Gateways.`)
+
 	cmd.Flags().StringSliceVar(&pr.providers, "providers", []string{},
 		fmt.Sprintf("If present, the tool will try to convert only resources related to the specified providers, supported values are %v.", i2gw.GetSupportedProviders()))
 
@@ -274,17 +484,33 @@ if specified with --namespace.`)
 
 	_ = cmd.MarkFlagRequired("providers")
 	cmd.MarkFlagsMutuallyExclusive("namespace", "all-namespaces")
+	cmd.MarkFlagsMutuallyExclusive("input-file", "kustomize")
+	cmd.MarkFlagsMutuallyExclusive("input-file", "context")
+	cmd.MarkFlagsMutuallyExclusive("kustomize", "context")
 	return cmd
 }
 
 // getNamespaceInCurrentContext returns the namespace in the current active context of the user.
 func getNamespaceInCurrentContext() (string, error) {
+	return getNamespaceInContext("")
+}
+
+// getNamespaceInContext returns the namespace of the given kubeconfig context.
+// An empty context name stands for the current active context.
+func getNamespaceInContext(kubeContext string) (string, error) {
 	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
 
-	kubeConfig := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules, &clientcmd.ConfigOverrides{})
-	currentNamespace, _, err := kubeConfig.Namespace()
+	kubeConfig := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules, &clientcmd.ConfigOverrides{CurrentContext: kubeContext})
+	namespace, _, err := kubeConfig.Namespace()
+
+	return namespace, err
+}
 
-	return currentNamespace, err
+// getConversionOptions returns the conversion options input by the user.
+func (pr *PrintRunner) getConversionOptions() i2gw.ConversionOptions {
+	return i2gw.ConversionOptions{
+		PreserveIngressAddresses: pr.preserveIngressAddresses,
+	}
 }
 
 // getProviderSpecificFlags returns the provider specific flags input by the user.
@@ -17,6 +17,7 @@ limitations under the License.
 package cmd
 
 import (
+	"bytes"
 	"fmt"
 	"log"
 	"os"
@@ -25,6 +26,7 @@ import (
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw"
 	"k8s.io/cli-runtime/pkg/printers"
 )
 
@@ -294,3 +296,105 @@ func Test_getProviderSpecificFlags(t *testing.T) {
 		})
 	}
 }
+
+func Test_namespaceFilterForContext(t *testing.T) {
+	destroy, err := setupKubeConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer destroy()
+
+	testCases := []struct {
+		name                    string
+		kubeContext             string
+		namespace               string
+		allNamespaces           bool
+		expectedNamespaceFilter string
+	}{
+		{
+			name:                    "Namespace of the given context",
+			kubeContext:             "example",
+			expectedNamespaceFilter: "non-default-ns",
+		},
+		{
+			name:                    "Context without namespace",
+			kubeContext:             "kind-i2gw",
+			expectedNamespaceFilter: "default",
+		},
+		{
+			name:                    "Namespace flag overrides the context namespace",
+			kubeContext:             "example",
+			namespace:               "other",
+			expectedNamespaceFilter: "other",
+		},
+		{
+			name:                    "All namespaces",
+			kubeContext:             "example",
+			allNamespaces:           true,
+			expectedNamespaceFilter: "",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			pr := PrintRunner{
+				namespace:     tc.namespace,
+				allNamespaces: tc.allNamespaces,
+			}
+			namespaceFilter, err := pr.namespaceFilterForContext(tc.kubeContext)
+			if err != nil {
+				t.Fatalf("Expected no error but got %v", err)
+			}
+			if namespaceFilter != tc.expectedNamespaceFilter {
+				t.Errorf("namespaceFilterForContext(%q) = %q, expected %q", tc.kubeContext, namespaceFilter, tc.expectedNamespaceFilter)
+			}
+		})
+	}
+}
+
+func Test_contextFileName(t *testing.T) {
+	testCases := map[string]string{
+		"kind-i2gw": "kind-i2gw",
+		"arn:aws:eks:us-east-1:123456789012:cluster/prod": "arn-aws-eks-us-east-1-123456789012-cluster-prod",
+		"gke_project_europe-west1_cluster.a":              "gke_project_europe-west1_cluster.a",
+	}
+	for kubeContext, expected := range testCases {
+		if actual := contextFileName(kubeContext); actual != expected {
+			t.Errorf("contextFileName(%q) = %q, expected %q", kubeContext, actual, expected)
+		}
+	}
+}
+
+func Test_printClusterSummary(t *testing.T) {
+	clusterResources := []i2gw.ClusterResources{
+		{
+			Context: i2gw.ClusterContext{Name: "cluster-a"},
+			IngressSummary: i2gw.IngressSummary{
+				Ingresses:   3,
+				Patterns:    map[string]int{"ingressClass=nginx": 3, "tls": 1},
+				Annotations: map[string]int{"nginx.ingress.kubernetes.io/rewrite-target": 2},
+			},
+		},
+		{
+			Context: i2gw.ClusterContext{Name: "cluster-b"},
+			IngressSummary: i2gw.IngressSummary{
+				Ingresses:   1,
+				Patterns:    map[string]int{"ingressClass=nginx": 1},
+				Annotations: map[string]int{},
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	printClusterSummary(&buf, clusterResources)
+
+	expected := `# INGRESS PATTERN/ANNOTATION                  cluster-a  cluster-b
+# Ingresses                                   3          1
+# ingressClass=nginx                          3          1
+# tls                                         1          0
+# nginx.ingress.kubernetes.io/rewrite-target  2          0
+`
+	if diff := cmp.Diff(expected, buf.String()); diff != "" {
+		t.Errorf("Unexpected cluster summary (-want +got):\n%s", diff)
+	}
+}
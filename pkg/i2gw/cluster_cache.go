@@ -0,0 +1,191 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package i2gw
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// DefaultListPageSize is the number of objects requested from the API server
+// in a single List call. It matches the chunk size used by kubectl.
+const DefaultListPageSize int64 = 500
+
+// ClusterCache reads objects from the cluster in pages and shares them between
+// all the providers, so that every GroupVersionKind is listed at most once per
+// run, no matter how many providers ask for it.
+//
+// When the cache is not shared (a single provider is reading from the cluster),
+// objects are not retained: every page is handed to the caller and dropped, so
+// that large clusters can be processed with bounded memory.
+type ClusterCache struct {
+	reader   client.Reader
+	pageSize int64
+	shared   bool
+
+	mu      sync.Mutex
+	entries map[schema.GroupVersionKind]*clusterCacheEntry
+}
+
+// maxListRestarts is the number of times the listing of a GroupVersionKind is
+// restarted when its continue token expires between two pages.
+const maxListRestarts = 3
+
+type clusterCacheEntry struct {
+	mu     sync.Mutex
+	loaded bool
+	items  []unstructured.Unstructured
+}
+
+// NewClusterCache returns a ClusterCache reading from the given client.
+// A non-positive pageSize falls back to DefaultListPageSize.
+func NewClusterCache(reader client.Reader, pageSize int64, shared bool) *ClusterCache {
+	if pageSize <= 0 {
+		pageSize = DefaultListPageSize
+	}
+	return &ClusterCache{
+		reader:   reader,
+		pageSize: pageSize,
+		shared:   shared,
+		entries:  map[schema.GroupVersionKind]*clusterCacheEntry{},
+	}
+}
+
+// List returns all the objects of the given GroupVersionKind. The objects are
+// read from the cluster on first use, and served from memory afterwards.
+// Concurrent callers asking for the same GroupVersionKind wait for the same
+// List to complete instead of issuing their own. Only successful lists are
+// kept, so that a failed one, e.g. because its context was canceled, is
+// retried by the next caller.
+func (c *ClusterCache) List(ctx context.Context, gvk schema.GroupVersionKind) ([]unstructured.Unstructured, error) {
+	c.mu.Lock()
+	entry, ok := c.entries[gvk]
+	if !ok {
+		entry = &clusterCacheEntry{}
+		c.entries[gvk] = entry
+	}
+	c.mu.Unlock()
+
+	entry.mu.Lock()
+	defer entry.mu.Unlock()
+	if entry.loaded {
+		return entry.items, nil
+	}
+	var items []unstructured.Unstructured
+	err := c.listPages(ctx, gvk, func() {
+		items = nil
+	}, func(list *unstructured.UnstructuredList) error {
+		items = append(items, list.Items...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	entry.items, entry.loaded = items, true
+	return entry.items, nil
+}
+
+// Stream calls fn for every object of the given GroupVersionKind.
+// If the cache is shared, fn is given copies of the objects served through
+// List, so that a provider changing them does not affect the others.
+// Otherwise they are read page by page, and only the current page is kept in
+// memory.
+func (c *ClusterCache) Stream(ctx context.Context, gvk schema.GroupVersionKind, fn func(*unstructured.Unstructured) error) error {
+	if c.shared {
+		items, err := c.List(ctx, gvk)
+		if err != nil {
+			return err
+		}
+		for i := range items {
+			if err := fn(items[i].DeepCopy()); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	// The API server lists the objects in the order of their keys, so when
+	// the listing restarts, the objects up to the last one delivered are
+	// skipped, so that fn is called once for every object.
+	var lastKey string
+	restarted := false
+	return c.listPages(ctx, gvk, func() {
+		restarted = true
+	}, func(list *unstructured.UnstructuredList) error {
+		for i := range list.Items {
+			key := objectKey(&list.Items[i])
+			if restarted && key <= lastKey {
+				continue
+			}
+			lastKey = key
+			if err := fn(&list.Items[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// objectKey returns the key of the object in the storage of the API server,
+// relative to its resource, which orders the lists.
+func objectKey(obj *unstructured.Unstructured) string {
+	if obj.GetNamespace() == "" {
+		return obj.GetName()
+	}
+	return obj.GetNamespace() + "/" + obj.GetName()
+}
+
+// listPages lists all the objects of the given GroupVersionKind, calling
+// pageFn once for every page returned by the API server. Like kubectl, the
+// listing restarts from the first page when the continue token expired, after
+// calling restartFn.
+func (c *ClusterCache) listPages(ctx context.Context, gvk schema.GroupVersionKind, restartFn func(), pageFn func(*unstructured.UnstructuredList) error) error {
+	var continueToken string
+	restarts := 0
+	for {
+		list := &unstructured.UnstructuredList{}
+		list.SetGroupVersionKind(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+
+		opts := []client.ListOption{client.Limit(c.pageSize)}
+		if continueToken != "" {
+			opts = append(opts, client.Continue(continueToken))
+		}
+		if err := c.reader.List(ctx, list, opts...); err != nil {
+			if apierrors.IsResourceExpired(err) && continueToken != "" && restarts < maxListRestarts {
+				restarts++
+				continueToken = ""
+				restartFn()
+				continue
+			}
+			return fmt.Errorf("failed to list %s: %w", gvk.GroupKind().String(), err)
+		}
+		if err := pageFn(list); err != nil {
+			return err
+		}
+
+		continueToken = list.GetContinue()
+		if continueToken == "" {
+			return nil
+		}
+	}
+}
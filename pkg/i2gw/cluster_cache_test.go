@@ -0,0 +1,250 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package i2gw
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	networkingv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// pagingReader is a client.Reader serving a fixed number of objects in pages,
+// honoring the Limit and Continue list options. The first failures List calls
+// fail, and the first continue token equal to expire has expired.
+type pagingReader struct {
+	client.Reader
+	total     int
+	failures  int32
+	expire    string
+	listCalls atomic.Int32
+	maxLimit  atomic.Int64
+	expired   atomic.Bool
+}
+
+func (r *pagingReader) List(_ context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	if r.listCalls.Add(1) <= r.failures {
+		return context.Canceled
+	}
+	listOpts := &client.ListOptions{}
+	listOpts.ApplyOptions(opts)
+	if r.expire != "" && listOpts.Continue == r.expire && r.expired.CompareAndSwap(false, true) {
+		return apierrors.NewResourceExpired("continue token expired")
+	}
+	if listOpts.Limit > r.maxLimit.Load() {
+		r.maxLimit.Store(listOpts.Limit)
+	}
+
+	start := 0
+	if listOpts.Continue != "" {
+		var err error
+		if start, err = strconv.Atoi(listOpts.Continue); err != nil {
+			return err
+		}
+	}
+	end := start + int(listOpts.Limit)
+	if end >= r.total {
+		end = r.total
+	}
+
+	ul := list.(*unstructured.UnstructuredList)
+	for i := start; i < end; i++ {
+		obj := unstructured.Unstructured{}
+		obj.SetGroupVersionKind(networkingv1.SchemeGroupVersion.WithKind("Ingress"))
+		obj.SetNamespace("default")
+		// Like the API server, the objects are listed in the order of their
+		// keys.
+		obj.SetName(fmt.Sprintf("ingress-%04d", i))
+		ul.Items = append(ul.Items, obj)
+	}
+	if end < r.total {
+		ul.SetContinue(strconv.Itoa(end))
+	}
+	return nil
+}
+
+func Test_ClusterCache(t *testing.T) {
+	ingressGVK := networkingv1.SchemeGroupVersion.WithKind("Ingress")
+
+	testCases := []struct {
+		name              string
+		total             int
+		pageSize          int64
+		shared            bool
+		consumers         int
+		expectedListCalls int32
+	}{{
+		name:              "shared cache lists every page once for all consumers",
+		total:             25,
+		pageSize:          10,
+		shared:            true,
+		consumers:         4,
+		expectedListCalls: 3,
+	}, {
+		name:              "non shared cache streams pages for every consumer",
+		total:             25,
+		pageSize:          10,
+		shared:            false,
+		consumers:         2,
+		expectedListCalls: 6,
+	}, {
+		name:              "default page size",
+		total:             1200,
+		pageSize:          0,
+		shared:            true,
+		consumers:         1,
+		expectedListCalls: 3,
+	}, {
+		name:              "empty list",
+		total:             0,
+		pageSize:          10,
+		shared:            true,
+		consumers:         2,
+		expectedListCalls: 1,
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			reader := &pagingReader{total: tc.total}
+			cache := NewClusterCache(reader, tc.pageSize, tc.shared)
+
+			var wg sync.WaitGroup
+			counts := make([]int, tc.consumers)
+			errs := make([]error, tc.consumers)
+			for i := 0; i < tc.consumers; i++ {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					errs[i] = cache.Stream(context.Background(), ingressGVK, func(*unstructured.Unstructured) error {
+						counts[i]++
+						return nil
+					})
+				}(i)
+			}
+			wg.Wait()
+
+			for i := 0; i < tc.consumers; i++ {
+				if errs[i] != nil {
+					t.Errorf("Consumer %d got unexpected error: %v", i, errs[i])
+				}
+				if counts[i] != tc.total {
+					t.Errorf("Consumer %d expected %d objects, got %d", i, tc.total, counts[i])
+				}
+			}
+			if got := reader.listCalls.Load(); got != tc.expectedListCalls {
+				t.Errorf("Expected %d List calls, got %d", tc.expectedListCalls, got)
+			}
+			expectedPageSize := tc.pageSize
+			if expectedPageSize == 0 {
+				expectedPageSize = DefaultListPageSize
+			}
+			if got := reader.maxLimit.Load(); got != expectedPageSize {
+				t.Errorf("Expected pages of %d objects, got %d", expectedPageSize, got)
+			}
+		})
+	}
+}
+
+func Test_ClusterCache_retries(t *testing.T) {
+	ingressGVK := networkingv1.SchemeGroupVersion.WithKind("Ingress")
+
+	testCases := []struct {
+		name              string
+		reader            *pagingReader
+		shared            bool
+		expectedErrors    int
+		expectedListCalls int32
+	}{{
+		name:              "failed list is not cached",
+		reader:            &pagingReader{total: 25, failures: 1},
+		shared:            true,
+		expectedErrors:    1,
+		expectedListCalls: 4,
+	}, {
+		name:              "shared list restarts when the continue token expires",
+		reader:            &pagingReader{total: 25, expire: "20"},
+		shared:            true,
+		expectedListCalls: 6,
+	}, {
+		name:              "streaming restarts when the continue token expires",
+		reader:            &pagingReader{total: 25, expire: "20"},
+		shared:            false,
+		expectedListCalls: 9,
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cache := NewClusterCache(tc.reader, 10, tc.shared)
+
+			// The consumers run one after the other, so that only the first
+			// one gets the failure.
+			var errs, count int
+			for i := 0; i < 2; i++ {
+				seen := map[string]bool{}
+				err := cache.Stream(context.Background(), ingressGVK, func(obj *unstructured.Unstructured) error {
+					if seen[obj.GetName()] {
+						t.Errorf("Object %s streamed twice", obj.GetName())
+					}
+					seen[obj.GetName()] = true
+					return nil
+				})
+				if err != nil {
+					errs++
+					continue
+				}
+				count = len(seen)
+			}
+
+			if errs != tc.expectedErrors {
+				t.Errorf("Expected %d errors, got %d", tc.expectedErrors, errs)
+			}
+			if count != tc.reader.total {
+				t.Errorf("Expected %d objects, got %d", tc.reader.total, count)
+			}
+			if got := tc.reader.listCalls.Load(); got != tc.expectedListCalls {
+				t.Errorf("Expected %d List calls, got %d", tc.expectedListCalls, got)
+			}
+		})
+	}
+}
+
+func Test_ClusterCache_sharedCopies(t *testing.T) {
+	ingressGVK := networkingv1.SchemeGroupVersion.WithKind("Ingress")
+	cache := NewClusterCache(&pagingReader{total: 3}, 10, true)
+
+	if err := cache.Stream(context.Background(), ingressGVK, func(obj *unstructured.Unstructured) error {
+		obj.SetName("changed")
+		return nil
+	}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := cache.Stream(context.Background(), ingressGVK, func(obj *unstructured.Unstructured) error {
+		if obj.GetName() == "changed" {
+			t.Errorf("Expected the objects changed by another consumer to be left intact in the cache")
+		}
+		return nil
+	}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+}
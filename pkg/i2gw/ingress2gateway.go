@@ -21,8 +21,12 @@ import (
 	"fmt"
 	"maps"
 
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	"golang.org/x/sync/errgroup"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/client-go/rest"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/config"
 	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
@@ -30,27 +34,140 @@ import (
 	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
 )
 
-func ToGatewayAPIResources(ctx context.Context, namespace string, inputFile string, providers []string, providerSpecificFlags map[string]map[string]string) ([]GatewayResources, error) {
-	var clusterClient client.Client
+// ToGatewayAPIResources reads the resources of the given providers from the input
+// file, or from the cluster of the current kubeconfig context if no input file is
+// given, and converts them to Gateway API resources. Along with the resources, it
+// returns the notifications dispatched during the conversion, rendered as one
+// table per provider.
+func ToGatewayAPIResources(ctx context.Context, namespace string, inputFile string, providers []string, providerSpecificFlags map[string]map[string]string, options ConversionOptions) ([]GatewayResources, map[string]string, error) {
+	return convertInput(ctx, namespace, inputFile, nil, providers, providerSpecificFlags, options)
+}
+
+// ToGatewayAPIResourcesFromKustomization builds the kustomization in the given
+// directory, the same way `kustomize build <dir>` does, and converts the
+// resources of the given providers found in the resulting objects, like
+// ToGatewayAPIResources does for an input file.
+func ToGatewayAPIResourcesFromKustomization(ctx context.Context, namespace string, kustomizeDir string, providers []string, providerSpecificFlags map[string]map[string]string, options ConversionOptions) ([]GatewayResources, map[string]string, error) {
+	manifest, err := RenderKustomization(kustomizeDir)
+	if err != nil {
+		return nil, nil, err
+	}
+	return convertInput(ctx, namespace, kustomizeDir, manifest, providers, providerSpecificFlags, options)
+}
+
+// convertInput reads the resources of the given providers from inputManifest if
+// it is not nil, from the input file if it is given, or from the cluster of the
+// current kubeconfig context otherwise, and converts them.
+func convertInput(ctx context.Context, namespace string, inputFile string, inputManifest []byte, providers []string, providerSpecificFlags map[string]map[string]string, options ConversionOptions) ([]GatewayResources, map[string]string, error) {
+	var (
+		clusterClient client.Client
+		clusterCache  *ClusterCache
+	)
 
 	if inputFile == "" {
 		conf, err := config.GetConfig()
 		if err != nil {
-			return nil, fmt.Errorf("failed to get client config: %w", err)
+			return nil, nil, fmt.Errorf("failed to get client config: %w", err)
+		}
+
+		clusterClient, err = newClusterClient(conf)
+		if err != nil {
+			return nil, nil, err
+		}
+		// Objects only need to be kept in memory when more than one provider
+		// consumes them, otherwise they are streamed page by page.
+		clusterCache = NewClusterCache(client.NewNamespacedClient(clusterClient, namespace), DefaultListPageSize, len(providers) > 1)
+	}
+
+	gatewayResources, err := toGatewayAPIResources(ctx, clusterClient, clusterCache, namespace, inputFile, inputManifest, providers, providerSpecificFlags, options)
+	notificationTables := notifications.NotificationAggr.CreateNotificationTables()
+	if err != nil {
+		return nil, notificationTables, err
+	}
+	return gatewayResources, notificationTables, nil
+}
+
+// ClusterContext identifies a cluster to read resources from, by the name of its
+// kubeconfig context, and the namespace to read resources from in that cluster.
+type ClusterContext struct {
+	Name      string
+	Namespace string
+}
+
+// ClusterResources contains the Gateway API resources converted from a single
+// cluster, along with the notifications dispatched during its conversion and a
+// summary of the Ingresses found in that cluster.
+type ClusterResources struct {
+	Context            ClusterContext
+	GatewayResources   []GatewayResources
+	NotificationTables map[string]string
+	IngressSummary     IngressSummary
+}
+
+// ToGatewayAPIResourcesFromContexts reads and converts the resources of every
+// given kubeconfig context. Every cluster is converted on its own, so that the
+// resources of different clusters are never merged together.
+func ToGatewayAPIResourcesFromContexts(ctx context.Context, contexts []ClusterContext, providers []string, providerSpecificFlags map[string]map[string]string, options ConversionOptions) ([]ClusterResources, error) {
+	var clusterResources []ClusterResources
+	for _, clusterContext := range contexts {
+		conf, err := config.GetConfigWithContext(clusterContext.Name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get client config for context %s: %w", clusterContext.Name, err)
+		}
+
+		clusterClient, err := newClusterClient(conf)
+		if err != nil {
+			return nil, fmt.Errorf("context %s: %w", clusterContext.Name, err)
 		}
+		// The Ingresses are consumed by the summary in addition to the
+		// providers, so they are always kept in memory.
+		clusterCache := NewClusterCache(client.NewNamespacedClient(clusterClient, clusterContext.Namespace), DefaultListPageSize, true)
 
-		cl, err := client.New(conf, client.Options{})
+		gatewayResources, err := toGatewayAPIResources(ctx, clusterClient, clusterCache, clusterContext.Namespace, "", nil, providers, providerSpecificFlags, options)
+		notificationTables := notifications.NotificationAggr.CreateNotificationTables()
 		if err != nil {
-			return nil, fmt.Errorf("failed to create client: %w", err)
+			return nil, fmt.Errorf("context %s: %w", clusterContext.Name, err)
 		}
-		clusterClient = client.NewNamespacedClient(cl, namespace)
+
+		summary, err := summarizeIngresses(ctx, clusterCache)
+		if err != nil {
+			return nil, fmt.Errorf("context %s: %w", clusterContext.Name, err)
+		}
+
+		clusterResources = append(clusterResources, ClusterResources{
+			Context:            clusterContext,
+			GatewayResources:   gatewayResources,
+			NotificationTables: notificationTables,
+			IngressSummary:     summary,
+		})
 	}
+	return clusterResources, nil
+}
+
+func newClusterClient(conf *rest.Config) (client.Client, error) {
+	cl, err := client.New(conf, client.Options{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create client: %w", err)
+	}
+	return cl, nil
+}
 
-	providerByName, err := constructProviders(&ProviderConf{
-		Client:                clusterClient,
+// toGatewayAPIResources reads and converts the resources of the given providers.
+// clusterClient is not scoped to a namespace, and is nil when reading from a file.
+// inputManifest, when not nil, is read instead of the content of the input file.
+func toGatewayAPIResources(ctx context.Context, clusterClient client.Client, clusterCache *ClusterCache, namespace string, inputFile string, inputManifest []byte, providers []string, providerSpecificFlags map[string]map[string]string, options ConversionOptions) ([]GatewayResources, error) {
+	conf := &ProviderConf{
+		Cache:                 clusterCache,
 		Namespace:             namespace,
 		ProviderSpecificFlags: providerSpecificFlags,
-	}, providers)
+		InputManifest:         inputManifest,
+		ConversionOptions:     options,
+	}
+	if clusterClient != nil {
+		conf.Client = client.NewNamespacedClient(clusterClient, namespace)
+		conf.ClusterReader = clusterClient
+	}
+	providerByName, err := constructProviders(conf, providers)
 	if err != nil {
 		return nil, err
 	}
@@ -90,13 +207,21 @@ func readProviderResourcesFromFile(ctx context.Context, providerByName map[Provi
 	return nil
 }
 
+// readProviderResourcesFromCluster reads the resources of all the providers
+// concurrently. Resources shared between providers, such as Ingresses, are read
+// only once through the ClusterCache in the ProviderConf.
 func readProviderResourcesFromCluster(ctx context.Context, providerByName map[ProviderName]Provider) error {
+	g, ctx := errgroup.WithContext(ctx)
 	for name, provider := range providerByName {
-		if err := provider.ReadResourcesFromCluster(ctx); err != nil {
-			return fmt.Errorf("failed to read %s resources from the cluster: %w", name, err)
-		}
+		name, provider := name, provider
+		g.Go(func() error {
+			if err := provider.ReadResourcesFromCluster(ctx); err != nil {
+				return fmt.Errorf("failed to read %s resources from the cluster: %w", name, err)
+			}
+			return nil
+		})
 	}
-	return nil
+	return g.Wait()
 }
 
 // constructProviders constructs a map of concrete Provider implementations
@@ -136,7 +261,8 @@ func GetSupportedProviders() []string {
 
 // MergeGatewayResources accept multiple GatewayResources and create a unique Resource struct
 // built as follows:
-//   - GatewayClasses, *Routes, and ReferenceGrants are grouped into the same maps
+//   - GatewayClasses, *Routes, ReferenceGrants and ExtensionResources are grouped
+//     into the same maps
 //   - Gateways may have the same NamespaceName even if they come from different
 //     ingresses, as they have a their GatewayClass' name as name. For this reason,
 //     if there are mutiple gateways named the same, their listeners are merged into
@@ -145,13 +271,16 @@ func GetSupportedProviders() []string {
 // This behavior is likely to change after https://github.com/kubernetes-sigs/gateway-api/pull/1863 takes place.
 func MergeGatewayResources(gatewayResources ...GatewayResources) (GatewayResources, field.ErrorList) {
 	mergedGatewayResources := GatewayResources{
-		Gateways:        make(map[types.NamespacedName]gatewayv1.Gateway),
-		GatewayClasses:  make(map[types.NamespacedName]gatewayv1.GatewayClass),
-		HTTPRoutes:      make(map[types.NamespacedName]gatewayv1.HTTPRoute),
-		TLSRoutes:       make(map[types.NamespacedName]gatewayv1alpha2.TLSRoute),
-		TCPRoutes:       make(map[types.NamespacedName]gatewayv1alpha2.TCPRoute),
-		UDPRoutes:       make(map[types.NamespacedName]gatewayv1alpha2.UDPRoute),
-		ReferenceGrants: make(map[types.NamespacedName]gatewayv1beta1.ReferenceGrant),
+		Gateways:           make(map[types.NamespacedName]gatewayv1.Gateway),
+		GatewayClasses:     make(map[types.NamespacedName]gatewayv1.GatewayClass),
+		HTTPRoutes:         make(map[types.NamespacedName]gatewayv1.HTTPRoute),
+		GRPCRoutes:         make(map[types.NamespacedName]gatewayv1alpha2.GRPCRoute),
+		TLSRoutes:          make(map[types.NamespacedName]gatewayv1alpha2.TLSRoute),
+		TCPRoutes:          make(map[types.NamespacedName]gatewayv1alpha2.TCPRoute),
+		UDPRoutes:          make(map[types.NamespacedName]gatewayv1alpha2.UDPRoute),
+		BackendTLSPolicies: make(map[types.NamespacedName]gatewayv1alpha2.BackendTLSPolicy),
+		ReferenceGrants:    make(map[types.NamespacedName]gatewayv1beta1.ReferenceGrant),
+		ExtensionResources: make(map[ExtensionResourceKey]unstructured.Unstructured),
 	}
 	var errs field.ErrorList
 	mergedGatewayResources.Gateways, errs = mergeGateways(gatewayResources)
@@ -161,10 +290,13 @@ func MergeGatewayResources(gatewayResources ...GatewayResources) (GatewayResourc
 	for _, gr := range gatewayResources {
 		maps.Copy(mergedGatewayResources.GatewayClasses, gr.GatewayClasses)
 		maps.Copy(mergedGatewayResources.HTTPRoutes, gr.HTTPRoutes)
+		maps.Copy(mergedGatewayResources.GRPCRoutes, gr.GRPCRoutes)
 		maps.Copy(mergedGatewayResources.TLSRoutes, gr.TLSRoutes)
 		maps.Copy(mergedGatewayResources.TCPRoutes, gr.TCPRoutes)
 		maps.Copy(mergedGatewayResources.UDPRoutes, gr.UDPRoutes)
+		maps.Copy(mergedGatewayResources.BackendTLSPolicies, gr.BackendTLSPolicies)
 		maps.Copy(mergedGatewayResources.ReferenceGrants, gr.ReferenceGrants)
+		maps.Copy(mergedGatewayResources.ExtensionResources, gr.ExtensionResources)
 	}
 	return mergedGatewayResources, errs
 }
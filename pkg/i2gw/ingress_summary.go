@@ -0,0 +1,115 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package i2gw
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	networkingv1 "k8s.io/api/networking/v1"
+	networkingv1beta1 "k8s.io/api/networking/v1beta1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// IngressSummary describes which Ingress patterns and annotations are used in a
+// cluster. Every entry maps a pattern or an annotation key to the number of
+// Ingresses using it.
+type IngressSummary struct {
+	Ingresses   int
+	Patterns    map[string]int
+	Annotations map[string]int
+}
+
+// summarizeIngresses builds the IngressSummary of all the Ingresses in the cache,
+// regardless of their class.
+func summarizeIngresses(ctx context.Context, cache *ClusterCache) (IngressSummary, error) {
+	summary := IngressSummary{
+		Patterns:    map[string]int{},
+		Annotations: map[string]int{},
+	}
+	err := cache.Stream(ctx, networkingv1.SchemeGroupVersion.WithKind("Ingress"), func(obj *unstructured.Unstructured) error {
+		var ingress networkingv1.Ingress
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.UnstructuredContent(), &ingress); err != nil {
+			return fmt.Errorf("failed to parse ingress %s/%s: %w", obj.GetNamespace(), obj.GetName(), err)
+		}
+		summary.add(ingress)
+		return nil
+	})
+	if err != nil {
+		return IngressSummary{}, fmt.Errorf("failed to summarize ingresses: %w", err)
+	}
+	return summary, nil
+}
+
+func (s *IngressSummary) add(ingress networkingv1.Ingress) {
+	s.Ingresses++
+	for key := range ingress.Annotations {
+		// The ingress class annotation is reported as a pattern instead.
+		if key == networkingv1beta1.AnnotationIngressClass {
+			continue
+		}
+		s.Annotations[key]++
+	}
+	for pattern := range ingressPatterns(ingress) {
+		s.Patterns[pattern]++
+	}
+}
+
+// ingressPatterns returns the notable patterns used by the given Ingress.
+// Each pattern is reported once per Ingress.
+func ingressPatterns(ingress networkingv1.Ingress) sets.Set[string] {
+	patterns := sets.New[string]()
+
+	ingressClass := "<none>"
+	if ingress.Spec.IngressClassName != nil && *ingress.Spec.IngressClassName != "" {
+		ingressClass = *ingress.Spec.IngressClassName
+	} else if class := ingress.Annotations[networkingv1beta1.AnnotationIngressClass]; class != "" {
+		ingressClass = class
+	}
+	patterns.Insert(fmt.Sprintf("ingressClass=%s", ingressClass))
+
+	if len(ingress.Spec.TLS) > 0 {
+		patterns.Insert("tls")
+	}
+	if ingress.Spec.DefaultBackend != nil {
+		patterns.Insert("defaultBackend")
+	}
+	for _, rule := range ingress.Spec.Rules {
+		if rule.Host == "" {
+			patterns.Insert("host=<none>")
+		} else if strings.HasPrefix(rule.Host, "*.") {
+			patterns.Insert("host=wildcard")
+		}
+		if rule.HTTP == nil {
+			continue
+		}
+		for _, path := range rule.HTTP.Paths {
+			if path.PathType != nil {
+				patterns.Insert(fmt.Sprintf("pathType=%s", *path.PathType))
+			}
+			if path.Backend.Resource != nil {
+				patterns.Insert("backend=resource")
+			} else if path.Backend.Service != nil && path.Backend.Service.Port.Name != "" {
+				patterns.Insert("backend=namedPort")
+			}
+		}
+	}
+	return patterns
+}
@@ -0,0 +1,92 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package i2gw
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+)
+
+func Test_IngressSummary(t *testing.T) {
+	prefix := networkingv1.PathTypePrefix
+	implementationSpecific := networkingv1.PathTypeImplementationSpecific
+
+	ingresses := []networkingv1.Ingress{
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "a",
+				Annotations: map[string]string{
+					"kubernetes.io/ingress.class":                "nginx",
+					"nginx.ingress.kubernetes.io/rewrite-target": "/",
+				},
+			},
+			Spec: networkingv1.IngressSpec{
+				TLS: []networkingv1.IngressTLS{{Hosts: []string{"*.example.com"}}},
+				Rules: []networkingv1.IngressRule{{
+					Host: "*.example.com",
+					IngressRuleValue: networkingv1.IngressRuleValue{HTTP: &networkingv1.HTTPIngressRuleValue{
+						Paths: []networkingv1.HTTPIngressPath{
+							{Path: "/a", PathType: &prefix},
+							{Path: "/b", PathType: &prefix},
+							{Path: "/c(/|$)(.*)", PathType: &implementationSpecific},
+						},
+					}},
+				}},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "b"},
+			Spec: networkingv1.IngressSpec{
+				IngressClassName: ptr.To("nginx"),
+				DefaultBackend: &networkingv1.IngressBackend{
+					Service: &networkingv1.IngressServiceBackend{Name: "svc", Port: networkingv1.ServiceBackendPort{Name: "http"}},
+				},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "c"},
+		},
+	}
+
+	summary := IngressSummary{Patterns: map[string]int{}, Annotations: map[string]int{}}
+	for _, ingress := range ingresses {
+		summary.add(ingress)
+	}
+
+	expected := IngressSummary{
+		Ingresses: 3,
+		Patterns: map[string]int{
+			"ingressClass=nginx":              2,
+			"ingressClass=<none>":             1,
+			"tls":                             1,
+			"host=wildcard":                   1,
+			"pathType=Prefix":                 1,
+			"pathType=ImplementationSpecific": 1,
+			"defaultBackend":                  1,
+		},
+		Annotations: map[string]int{
+			"nginx.ingress.kubernetes.io/rewrite-target": 1,
+		},
+	}
+	if diff := cmp.Diff(expected, summary); diff != "" {
+		t.Errorf("Unexpected summary (-want +got):\n%s", diff)
+	}
+}
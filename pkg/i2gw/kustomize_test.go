@@ -0,0 +1,73 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package i2gw
+
+import (
+	"testing"
+
+	networkingv1 "k8s.io/api/networking/v1"
+	"sigs.k8s.io/yaml"
+)
+
+func Test_RenderKustomization(t *testing.T) {
+	testCases := []struct {
+		name          string
+		dir           string
+		expectedName  string
+		expectedHost  string
+		expectedError bool
+	}{{
+		name:         "base",
+		dir:          "testdata/kustomize/base",
+		expectedName: "example",
+		expectedHost: "foo.example.com",
+	}, {
+		name:         "overlay with namePrefix and patches",
+		dir:          "testdata/kustomize/overlay",
+		expectedName: "prod-example",
+		expectedHost: "prod.example.com",
+	}, {
+		name:          "missing kustomization",
+		dir:           "testdata/kustomize/missing",
+		expectedError: true,
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			manifest, err := RenderKustomization(tc.dir)
+			if tc.expectedError {
+				if err == nil {
+					t.Errorf("Expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error but got %v", err)
+			}
+			var ingress networkingv1.Ingress
+			if err := yaml.Unmarshal(manifest, &ingress); err != nil {
+				t.Fatalf("failed to unmarshal rendered ingress: %v", err)
+			}
+			if ingress.Name != tc.expectedName {
+				t.Errorf("Expected Ingress name %s, got %s", tc.expectedName, ingress.Name)
+			}
+			if len(ingress.Spec.Rules) != 1 || ingress.Spec.Rules[0].Host != tc.expectedHost {
+				t.Errorf("Expected Ingress host %s, got %+v", tc.expectedHost, ingress.Spec.Rules)
+			}
+		})
+	}
+}
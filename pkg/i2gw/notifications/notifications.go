@@ -0,0 +1,116 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package notifications
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// MessageType is the severity of a Notification.
+type MessageType string
+
+const (
+	// InfoNotification reports a conversion detail the user should be aware of.
+	InfoNotification MessageType = "INFO"
+	// WarningNotification reports a behavior that changed, or was not fully
+	// converted, and should be reviewed by the user.
+	WarningNotification MessageType = "WARNING"
+	// ErrorNotification reports a configuration that could not be converted and
+	// requires a manual action from the user.
+	ErrorNotification MessageType = "ERROR"
+)
+
+// Notification is a message emitted by a provider during the conversion, which
+// does not prevent the conversion from completing, but should be reported to
+// the user along with the converted resources.
+type Notification struct {
+	Type           MessageType
+	Message        string
+	CallingObjects []client.Object
+}
+
+// NotificationAggregator collects the notifications dispatched by the providers,
+// grouped by provider name. It is safe for concurrent use.
+type NotificationAggregator struct {
+	mutex         sync.Mutex
+	Notifications map[string][]Notification
+}
+
+// NotificationAggr is the aggregator every provider dispatches its notifications to.
+var NotificationAggr = NotificationAggregator{Notifications: map[string][]Notification{}}
+
+// NewNotification returns a Notification of the given type, optionally bound
+// to the objects that caused it.
+func NewNotification(mType MessageType, message string, callingObjects ...client.Object) Notification {
+	return Notification{Type: mType, Message: message, CallingObjects: callingObjects}
+}
+
+// DispatchNotification records the notification under the given provider name.
+func (na *NotificationAggregator) DispatchNotification(notification Notification, providerName string) {
+	na.mutex.Lock()
+	defer na.mutex.Unlock()
+	na.Notifications[providerName] = append(na.Notifications[providerName], notification)
+}
+
+// CreateNotificationTables renders the aggregated notifications as one table per
+// provider, keyed by the provider name, and clears the aggregator, so that the
+// notifications of a conversion are not reported again by the next one.
+func (na *NotificationAggregator) CreateNotificationTables() map[string]string {
+	na.mutex.Lock()
+	defer na.mutex.Unlock()
+
+	tables := make(map[string]string, len(na.Notifications))
+	for providerName, providerNotifications := range na.Notifications {
+		tables[providerName] = notificationTable(providerName, providerNotifications)
+	}
+	na.Notifications = map[string][]Notification{}
+	return tables
+}
+
+func notificationTable(providerName string, providerNotifications []Notification) string {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "Notifications from %s:\n", strings.ToUpper(providerName))
+
+	tw := tabwriter.NewWriter(&buf, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "MESSAGE TYPE\tNOTIFICATION\tCALLING OBJECT")
+	for _, n := range providerNotifications {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", n.Type, n.Message, callingObjectsString(n.CallingObjects))
+	}
+	tw.Flush()
+	return buf.String()
+}
+
+func callingObjectsString(objects []client.Object) string {
+	var names []string
+	for _, object := range objects {
+		kind := object.GetObjectKind().GroupVersionKind().Kind
+		name := fmt.Sprintf("%s/%s", object.GetNamespace(), object.GetName())
+		if kind != "" {
+			name = fmt.Sprintf("%s: %s", kind, name)
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,64 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package notifications
+
+import (
+	"strings"
+	"testing"
+
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func Test_CreateNotificationTables(t *testing.T) {
+	aggr := NotificationAggregator{Notifications: map[string][]Notification{}}
+
+	ingress := &networkingv1.Ingress{
+		TypeMeta:   metav1.TypeMeta{Kind: "Ingress", APIVersion: "networking.k8s.io/v1"},
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "example"},
+	}
+	aggr.DispatchNotification(NewNotification(WarningNotification, "something to review", ingress), "ingress-nginx")
+	aggr.DispatchNotification(NewNotification(InfoNotification, "something to know"), "ingress-nginx")
+	aggr.DispatchNotification(NewNotification(ErrorNotification, "something to fix"), "kong")
+
+	tables := aggr.CreateNotificationTables()
+	if len(tables) != 2 {
+		t.Fatalf("Expected 2 tables, got %d: %+v", len(tables), tables)
+	}
+
+	nginxTable := tables["ingress-nginx"]
+	for _, expected := range []string{
+		"Notifications from INGRESS-NGINX:",
+		"MESSAGE TYPE",
+		"WARNING",
+		"something to review",
+		"Ingress: default/example",
+		"INFO",
+		"something to know",
+	} {
+		if !strings.Contains(nginxTable, expected) {
+			t.Errorf("Expected table to contain %q, got:\n%s", expected, nginxTable)
+		}
+	}
+	if strings.Contains(nginxTable, "something to fix") {
+		t.Errorf("Expected notifications of other providers to be left out, got:\n%s", nginxTable)
+	}
+
+	if tables := aggr.CreateNotificationTables(); len(tables) != 0 {
+		t.Errorf("Expected the aggregator to be cleared, got: %+v", tables)
+	}
+}
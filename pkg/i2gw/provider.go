@@ -18,9 +18,12 @@ package i2gw
 
 import (
 	"context"
+	"os"
 	"sync"
 
 	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -44,9 +47,42 @@ type ProviderConstructor func(conf *ProviderConf) Provider
 // ProviderConf contains all the configuration required for every concrete
 // Provider implementation.
 type ProviderConf struct {
-	Client                client.Client
+	// Client reads resources from the namespace the run is scoped to.
+	Client client.Client
+	// ClusterReader reads resources regardless of the namespace the run is
+	// scoped to, such as the Service of the ingress controller. It is nil when
+	// resources are read from a file.
+	ClusterReader client.Reader
+	// Cache is used by the providers to read resources from the cluster.
+	// It is shared between all the providers of a run, and is nil when
+	// resources are read from a file.
+	Cache                 *ClusterCache
 	Namespace             string
 	ProviderSpecificFlags map[string]map[string]string
+
+	// InputManifest holds the objects to read instead of the content of the
+	// input file, such as a rendered kustomization. The providers read their
+	// input with ReadInputFile.
+	InputManifest []byte
+
+	ConversionOptions
+}
+
+// ReadInputFile returns the content of the input file, or the InputManifest
+// when it is set.
+func (c *ProviderConf) ReadInputFile(filename string) ([]byte, error) {
+	if c.InputManifest != nil {
+		return c.InputManifest, nil
+	}
+	return os.ReadFile(filename)
+}
+
+// ConversionOptions contains the conversion options that apply to every provider.
+type ConversionOptions struct {
+	// PreserveIngressAddresses copies the load balancer addresses found in the
+	// status of the Ingresses to the addresses of the generated Gateways, so that
+	// the Gateways keep the network identity of the Ingresses.
+	PreserveIngressAddresses bool
 }
 
 // The Provider interface specifies the required functionality which needs to be
@@ -94,11 +130,26 @@ type GatewayResources struct {
 	GatewayClasses map[types.NamespacedName]gatewayv1.GatewayClass
 
 	HTTPRoutes map[types.NamespacedName]gatewayv1.HTTPRoute
+	GRPCRoutes map[types.NamespacedName]gatewayv1alpha2.GRPCRoute
 	TLSRoutes  map[types.NamespacedName]gatewayv1alpha2.TLSRoute
 	TCPRoutes  map[types.NamespacedName]gatewayv1alpha2.TCPRoute
 	UDPRoutes  map[types.NamespacedName]gatewayv1alpha2.UDPRoute
 
+	BackendTLSPolicies map[types.NamespacedName]gatewayv1alpha2.BackendTLSPolicy
+
 	ReferenceGrants map[types.NamespacedName]gatewayv1beta1.ReferenceGrant
+
+	// ExtensionResources are the resources of a Gateway implementation, such
+	// as its policies, generated for the features with no Gateway API
+	// equivalent.
+	ExtensionResources map[ExtensionResourceKey]unstructured.Unstructured
+}
+
+// ExtensionResourceKey is the key of the extension resources, whose kinds
+// are not known by the project.
+type ExtensionResourceKey struct {
+	schema.GroupKind
+	types.NamespacedName
 }
 
 // FeatureParser is a function that reads the Ingresses, and applies
@@ -30,8 +30,8 @@ type converter struct {
 }
 
 // newConverter returns an apisix converter instance.
-func newConverter() *converter {
-	return &converter{
+func newConverter(conf *i2gw.ProviderConf) *converter {
+	c := &converter{
 		featureParsers: []i2gw.FeatureParser{
 			httpToHTTPSFeature,
 		},
@@ -39,6 +39,10 @@ func newConverter() *converter {
 			// The list of the implementationSpecific ingress fields options comes here.
 		},
 	}
+	if conf.PreserveIngressAddresses {
+		c.featureParsers = append(c.featureParsers, common.IngressStatusAddressesFeature(Name))
+	}
+	return c
 }
 
 func (c *converter) convert(storage *storage) (i2gw.GatewayResources, field.ErrorList) {
@@ -60,5 +64,9 @@ func (c *converter) convert(storage *storage) (i2gw.GatewayResources, field.Erro
 		errs = append(errs, parseErrs...)
 	}
 
+	if storage.ControllerService != nil {
+		errs = append(errs, common.ControllerServiceFeature(Name, storage.ControllerService)(ingressList, &gatewayResources)...)
+	}
+
 	return gatewayResources, errs
 }
@@ -40,11 +40,16 @@ func (r *resourceReader) readResourcesFromCluster(ctx context.Context) (*storage
 	// read apisix related resources from cluster.
 	storage := newResourcesStorage()
 
-	ingresses, err := common.ReadIngressesFromCluster(ctx, r.conf.Client, sets.New(ApisixIngressClass))
+	ingresses, err := common.ReadIngressesFromCluster(ctx, r.conf.Cache, sets.New(ApisixIngressClass))
 	if err != nil {
 		return nil, err
 	}
 	storage.Ingresses = ingresses
+
+	storage.ControllerService, err = common.ReadControllerServiceFromCluster(ctx, r.conf, Name)
+	if err != nil {
+		return nil, err
+	}
 	return storage, nil
 }
 
@@ -52,10 +57,15 @@ func (r *resourceReader) readResourcesFromFile(filename string) (*storage, error
 	// read apisix related resources from file.
 	storage := newResourcesStorage()
 
-	ingresses, err := common.ReadIngressesFromFile(filename, r.conf.Namespace, sets.New[string](ApisixIngressClass))
+	ingresses, err := common.ReadIngressesFromFile(filename, r.conf, sets.New[string](ApisixIngressClass))
 	if err != nil {
 		return nil, err
 	}
 	storage.Ingresses = ingresses
+
+	storage.ControllerService, err = common.ReadControllerServiceFromFile(filename, r.conf, Name)
+	if err != nil {
+		return nil, err
+	}
 	return storage, nil
 }
@@ -0,0 +1,234 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+// MaxGatewayAddresses is the maximum number of addresses a Gateway can have.
+const MaxGatewayAddresses = 16
+
+// IngressStatusAddressesFeature returns a FeatureParser copying the load
+// balancer addresses found in the status of the Ingresses to the addresses of
+// the Gateway every Ingress is converted to. The addresses of all the Ingresses
+// converted to the same Gateway are merged, and conflicts are reported as
+// notifications of the given provider.
+func IngressStatusAddressesFeature(providerName i2gw.ProviderName) i2gw.FeatureParser {
+	return func(ingresses []networkingv1.Ingress, gatewayResources *i2gw.GatewayResources) field.ErrorList {
+		return setGatewayAddressesFromIngressStatus(providerName, ingresses, gatewayResources)
+	}
+}
+
+type gatewayAddresses struct {
+	addresses []gatewayv1.GatewayAddress
+	ingresses []client.Object
+	// statuses holds the addresses reported by every Ingress, to detect
+	// Ingresses of the same Gateway reporting different addresses.
+	statuses sets.Set[string]
+}
+
+func setGatewayAddressesFromIngressStatus(providerName i2gw.ProviderName, ingresses []networkingv1.Ingress, gatewayResources *i2gw.GatewayResources) field.ErrorList {
+	// Sort the Ingresses so that the order of the merged addresses does not
+	// depend on the order the Ingresses were read in.
+	sortedIngresses := make([]networkingv1.Ingress, len(ingresses))
+	copy(sortedIngresses, ingresses)
+	sort.Slice(sortedIngresses, func(i, j int) bool {
+		if sortedIngresses[i].Namespace != sortedIngresses[j].Namespace {
+			return sortedIngresses[i].Namespace < sortedIngresses[j].Namespace
+		}
+		return sortedIngresses[i].Name < sortedIngresses[j].Name
+	})
+
+	var gatewayKeys []types.NamespacedName
+	addressesByGateway := map[types.NamespacedName]*gatewayAddresses{}
+	gatewaysByAddress := map[string][]types.NamespacedName{}
+	for i := range sortedIngresses {
+		ingress := &sortedIngresses[i]
+		addresses := ingressStatusAddresses(*ingress)
+		if len(addresses) == 0 {
+			continue
+		}
+
+		key := types.NamespacedName{Namespace: ingress.Namespace, Name: GetIngressClass(*ingress)}
+		if _, ok := gatewayResources.Gateways[key]; !ok {
+			continue
+		}
+		gwAddresses, ok := addressesByGateway[key]
+		if !ok {
+			gwAddresses = &gatewayAddresses{statuses: sets.New[string]()}
+			addressesByGateway[key] = gwAddresses
+			gatewayKeys = append(gatewayKeys, key)
+		}
+		gwAddresses.ingresses = append(gwAddresses.ingresses, ingress)
+		gwAddresses.statuses.Insert(addressesString(addresses))
+
+		for _, address := range addresses {
+			if containsAddress(gwAddresses.addresses, address) {
+				continue
+			}
+			gwAddresses.addresses = append(gwAddresses.addresses, address)
+			gatewaysByAddress[address.Value] = append(gatewaysByAddress[address.Value], key)
+		}
+	}
+
+	for _, key := range gatewayKeys {
+		gwAddresses := addressesByGateway[key]
+		if len(gwAddresses.statuses) > 1 {
+			notifications.NotificationAggr.DispatchNotification(notifications.NewNotification(
+				notifications.WarningNotification,
+				fmt.Sprintf("Ingresses converted to Gateway %s report different load balancer addresses, the Gateway gets all of them: %s", key, addressesString(gwAddresses.addresses)),
+				gwAddresses.ingresses...,
+			), string(providerName))
+		}
+
+		gateway := gatewayResources.Gateways[key]
+		if dropped := appendGatewayAddresses(&gateway, gwAddresses.addresses); len(dropped) > 0 {
+			notifyDroppedAddresses(providerName, key, dropped, gwAddresses.ingresses...)
+		}
+		gatewayResources.Gateways[key] = gateway
+	}
+
+	addressValues := make([]string, 0, len(gatewaysByAddress))
+	for value := range gatewaysByAddress {
+		addressValues = append(addressValues, value)
+	}
+	sort.Strings(addressValues)
+	for _, value := range addressValues {
+		keys := gatewaysByAddress[value]
+		if len(keys) < 2 {
+			continue
+		}
+		var gatewayNames []string
+		var callingObjects []client.Object
+		for _, key := range keys {
+			gatewayNames = append(gatewayNames, key.String())
+			callingObjects = append(callingObjects, addressesByGateway[key].ingresses...)
+		}
+		notifications.NotificationAggr.DispatchNotification(notifications.NewNotification(
+			notifications.WarningNotification,
+			fmt.Sprintf("load balancer address %s is reported by Ingresses converted to different Gateways (%s), only one of them can claim it", value, strings.Join(gatewayNames, ", ")),
+			callingObjects...,
+		), string(providerName))
+	}
+
+	return nil
+}
+
+// appendGatewayAddresses adds the addresses missing from the Gateway, up to
+// MaxGatewayAddresses, and returns the ones left out.
+func appendGatewayAddresses(gateway *gatewayv1.Gateway, addresses []gatewayv1.GatewayAddress) []gatewayv1.GatewayAddress {
+	var dropped []gatewayv1.GatewayAddress
+	for _, address := range addresses {
+		if containsAddress(gateway.Spec.Addresses, address) {
+			continue
+		}
+		if len(gateway.Spec.Addresses) >= MaxGatewayAddresses {
+			dropped = append(dropped, address)
+			continue
+		}
+		gateway.Spec.Addresses = append(gateway.Spec.Addresses, address)
+	}
+	return dropped
+}
+
+// notifyDroppedAddresses reports the addresses left out of the Gateway, as it
+// already has MaxGatewayAddresses.
+func notifyDroppedAddresses(providerName i2gw.ProviderName, key types.NamespacedName, dropped []gatewayv1.GatewayAddress, callingObjects ...client.Object) {
+	notifications.NotificationAggr.DispatchNotification(notifications.NewNotification(
+		notifications.WarningNotification,
+		fmt.Sprintf("a gateway cannot have more than %d addresses, these addresses were left out of Gateway %s: %s", MaxGatewayAddresses, key, addressesString(dropped)),
+		callingObjects...,
+	), string(providerName))
+}
+
+// ingressStatusAddresses returns the load balancer addresses of the Ingress
+// status as Gateway addresses, in the order they are reported.
+func ingressStatusAddresses(ingress networkingv1.Ingress) []gatewayv1.GatewayAddress {
+	var addresses []gatewayv1.GatewayAddress
+	for _, lb := range ingress.Status.LoadBalancer.Ingress {
+		var address gatewayv1.GatewayAddress
+		switch {
+		case lb.IP != "":
+			address = gatewayv1.GatewayAddress{Type: PtrTo(gatewayv1.IPAddressType), Value: lb.IP}
+		case lb.Hostname != "":
+			address = gatewayv1.GatewayAddress{Type: PtrTo(gatewayv1.HostnameAddressType), Value: lb.Hostname}
+		default:
+			continue
+		}
+		if !containsAddress(addresses, address) {
+			addresses = append(addresses, address)
+		}
+	}
+	return addresses
+}
+
+func containsAddress(addresses []gatewayv1.GatewayAddress, address gatewayv1.GatewayAddress) bool {
+	for _, a := range addresses {
+		if sameAddress(a, address) {
+			return true
+		}
+	}
+	return false
+}
+
+// sameAddress compares the addresses by meaning rather than by spelling, so
+// that the overlapping statuses of Ingresses are merged: IPs are compared
+// parsed, and hostnames regardless of case.
+func sameAddress(a, b gatewayv1.GatewayAddress) bool {
+	if addressType(a) != addressType(b) {
+		return false
+	}
+	switch addressType(a) {
+	case gatewayv1.IPAddressType:
+		ipA, ipB := net.ParseIP(a.Value), net.ParseIP(b.Value)
+		if ipA != nil && ipB != nil {
+			return ipA.Equal(ipB)
+		}
+	case gatewayv1.HostnameAddressType:
+		return strings.EqualFold(a.Value, b.Value)
+	}
+	return a.Value == b.Value
+}
+
+func addressType(address gatewayv1.GatewayAddress) gatewayv1.AddressType {
+	if address.Type == nil {
+		return gatewayv1.IPAddressType
+	}
+	return *address.Type
+}
+
+func addressesString(addresses []gatewayv1.GatewayAddress) string {
+	values := make([]string, 0, len(addresses))
+	for _, address := range addresses {
+		values = append(values, address.Value)
+	}
+	sort.Strings(values)
+	return strings.Join(values, ",")
+}
@@ -0,0 +1,184 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+func Test_IngressStatusAddressesFeature(t *testing.T) {
+	ingress := func(namespace, name, class string, lbs ...networkingv1.IngressLoadBalancerIngress) networkingv1.Ingress {
+		return networkingv1.Ingress{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+			Spec:       networkingv1.IngressSpec{IngressClassName: PtrTo(class)},
+			Status: networkingv1.IngressStatus{
+				LoadBalancer: networkingv1.IngressLoadBalancerStatus{Ingress: lbs},
+			},
+		}
+	}
+	ip := func(value string) networkingv1.IngressLoadBalancerIngress {
+		return networkingv1.IngressLoadBalancerIngress{IP: value}
+	}
+	hostname := func(value string) networkingv1.IngressLoadBalancerIngress {
+		return networkingv1.IngressLoadBalancerIngress{Hostname: value}
+	}
+	ipAddress := func(value string) gatewayv1.GatewayAddress {
+		return gatewayv1.GatewayAddress{Type: PtrTo(gatewayv1.IPAddressType), Value: value}
+	}
+	hostnameAddress := func(value string) gatewayv1.GatewayAddress {
+		return gatewayv1.GatewayAddress{Type: PtrTo(gatewayv1.HostnameAddressType), Value: value}
+	}
+	gatewayKey := func(namespace, name string) types.NamespacedName {
+		return types.NamespacedName{Namespace: namespace, Name: name}
+	}
+
+	addresses := func(lbs []networkingv1.IngressLoadBalancerIngress) []gatewayv1.GatewayAddress {
+		var addresses []gatewayv1.GatewayAddress
+		for _, lb := range lbs {
+			addresses = append(addresses, ipAddress(lb.IP))
+		}
+		return addresses
+	}
+
+	var manyIPs []networkingv1.IngressLoadBalancerIngress
+	for i := 0; i <= MaxGatewayAddresses; i++ {
+		manyIPs = append(manyIPs, ip(fmt.Sprintf("10.0.0.%d", i)))
+	}
+
+	testCases := []struct {
+		name                  string
+		ingresses             []networkingv1.Ingress
+		gateways              []types.NamespacedName
+		expectedAddresses     map[types.NamespacedName][]gatewayv1.GatewayAddress
+		expectedErrors        int
+		expectedNotifications []string
+	}{{
+		name: "ip and hostname addresses",
+		ingresses: []networkingv1.Ingress{
+			ingress("default", "a", "nginx", ip("1.2.3.4"), hostname("lb.example.com")),
+		},
+		gateways: []types.NamespacedName{gatewayKey("default", "nginx")},
+		expectedAddresses: map[types.NamespacedName][]gatewayv1.GatewayAddress{
+			gatewayKey("default", "nginx"): {ipAddress("1.2.3.4"), hostnameAddress("lb.example.com")},
+		},
+	}, {
+		name: "ingresses of the same gateway reporting the same address",
+		ingresses: []networkingv1.Ingress{
+			ingress("default", "b", "nginx", ip("1.2.3.4")),
+			ingress("default", "a", "nginx", ip("1.2.3.4")),
+		},
+		gateways: []types.NamespacedName{gatewayKey("default", "nginx")},
+		expectedAddresses: map[types.NamespacedName][]gatewayv1.GatewayAddress{
+			gatewayKey("default", "nginx"): {ipAddress("1.2.3.4")},
+		},
+	}, {
+		name: "ingresses of the same gateway reporting different addresses are merged",
+		ingresses: []networkingv1.Ingress{
+			ingress("default", "b", "nginx", ip("5.6.7.8")),
+			ingress("default", "a", "nginx", ip("1.2.3.4")),
+		},
+		gateways: []types.NamespacedName{gatewayKey("default", "nginx")},
+		expectedAddresses: map[types.NamespacedName][]gatewayv1.GatewayAddress{
+			gatewayKey("default", "nginx"): {ipAddress("1.2.3.4"), ipAddress("5.6.7.8")},
+		},
+		expectedNotifications: []string{"report different load balancer addresses"},
+	}, {
+		name: "same address on different gateways",
+		ingresses: []networkingv1.Ingress{
+			ingress("default", "a", "nginx", ip("1.2.3.4")),
+			ingress("other", "a", "nginx", ip("1.2.3.4")),
+		},
+		gateways: []types.NamespacedName{gatewayKey("default", "nginx"), gatewayKey("other", "nginx")},
+		expectedAddresses: map[types.NamespacedName][]gatewayv1.GatewayAddress{
+			gatewayKey("default", "nginx"): {ipAddress("1.2.3.4")},
+			gatewayKey("other", "nginx"):   {ipAddress("1.2.3.4")},
+		},
+		expectedNotifications: []string{"load balancer address 1.2.3.4 is reported by Ingresses converted to different Gateways"},
+	}, {
+		name: "ingress without status",
+		ingresses: []networkingv1.Ingress{
+			ingress("default", "a", "nginx"),
+		},
+		gateways: []types.NamespacedName{gatewayKey("default", "nginx")},
+		expectedAddresses: map[types.NamespacedName][]gatewayv1.GatewayAddress{
+			gatewayKey("default", "nginx"): nil,
+		},
+	}, {
+		name: "too many addresses",
+		ingresses: []networkingv1.Ingress{
+			ingress("default", "a", "nginx", manyIPs...),
+		},
+		gateways: []types.NamespacedName{gatewayKey("default", "nginx")},
+		expectedAddresses: map[types.NamespacedName][]gatewayv1.GatewayAddress{
+			gatewayKey("default", "nginx"): addresses(manyIPs[:MaxGatewayAddresses]),
+		},
+		expectedNotifications: []string{"these addresses were left out of Gateway default/nginx: 10.0.0.16"},
+	}, {
+		name: "ingresses of the same gateway reporting overlapping addresses",
+		ingresses: []networkingv1.Ingress{
+			ingress("default", "a", "nginx", ip("2001:db8::1"), hostname("LB.example.com")),
+			ingress("default", "b", "nginx", ip("2001:db8:0:0::1"), hostname("lb.example.com"), ip("1.2.3.4")),
+		},
+		gateways: []types.NamespacedName{gatewayKey("default", "nginx")},
+		expectedAddresses: map[types.NamespacedName][]gatewayv1.GatewayAddress{
+			gatewayKey("default", "nginx"): {ipAddress("2001:db8::1"), hostnameAddress("LB.example.com"), ipAddress("1.2.3.4")},
+		},
+		expectedNotifications: []string{"report different load balancer addresses"},
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			gatewayResources := i2gw.GatewayResources{Gateways: map[types.NamespacedName]gatewayv1.Gateway{}}
+			for _, key := range tc.gateways {
+				gatewayResources.Gateways[key] = gatewayv1.Gateway{
+					ObjectMeta: metav1.ObjectMeta{Namespace: key.Namespace, Name: key.Name},
+				}
+			}
+
+			errs := IngressStatusAddressesFeature("test")(tc.ingresses, &gatewayResources)
+			if len(errs) != tc.expectedErrors {
+				t.Errorf("Expected %d errors, got %d: %+v", tc.expectedErrors, len(errs), errs)
+			}
+
+			for key, expectedAddresses := range tc.expectedAddresses {
+				if diff := cmp.Diff(expectedAddresses, gatewayResources.Gateways[key].Spec.Addresses); diff != "" {
+					t.Errorf("Unexpected addresses for Gateway %s, \n want: %+v\n got: %+v\n diff (-want +got):\n%s", key, expectedAddresses, gatewayResources.Gateways[key].Spec.Addresses, diff)
+				}
+			}
+
+			tables := notifications.NotificationAggr.CreateNotificationTables()
+			for _, expected := range tc.expectedNotifications {
+				if !strings.Contains(tables["test"], expected) {
+					t.Errorf("Expected notification %q, got:\n%s", expected, tables["test"])
+				}
+			}
+			if len(tc.expectedNotifications) == 0 && len(tables) != 0 {
+				t.Errorf("Expected no notifications, got: %+v", tables)
+			}
+		})
+	}
+}
@@ -0,0 +1,287 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+// ControllerServiceFlag is the name of the provider-specific flag naming the
+// Service of the ingress controller, as namespace/name.
+const ControllerServiceFlag = "controller-service"
+
+// ControllerServiceFlagDescription is the description of ControllerServiceFlag.
+const ControllerServiceFlagDescription = "The Service of the ingress controller, as namespace/name. When set, its static load balancer IPs, internal load balancer annotations and labels are set on the generated Gateways."
+
+// MaxGatewayInfrastructureEntries is the maximum number of labels, and of
+// annotations, of a Gateway infrastructure.
+const MaxGatewayInfrastructureEntries = 8
+
+// staticIPAnnotations are the Service annotations pinning the IPs of the load
+// balancer, holding a comma separated list of IPs.
+var staticIPAnnotations = []string{
+	"metallb.universe.tf/loadBalancerIPs",
+	"service.beta.kubernetes.io/azure-load-balancer-ipv4",
+	"service.beta.kubernetes.io/azure-load-balancer-ipv6",
+}
+
+// loadBalancerAnnotations are the Service annotations configuring an internal
+// load balancer, carried over to the Gateway infrastructure.
+var loadBalancerAnnotations = []string{
+	"cloud.google.com/load-balancer-type",
+	"networking.gke.io/internal-load-balancer-allow-global-access",
+	"networking.gke.io/internal-load-balancer-subnet",
+	"networking.gke.io/load-balancer-type",
+	"service.beta.kubernetes.io/aws-load-balancer-internal",
+	"service.beta.kubernetes.io/aws-load-balancer-scheme",
+	"service.beta.kubernetes.io/aws-load-balancer-subnets",
+	"service.beta.kubernetes.io/azure-load-balancer-internal",
+	"service.beta.kubernetes.io/azure-load-balancer-internal-subnet",
+	"service.beta.kubernetes.io/oci-load-balancer-internal",
+}
+
+// ParseNamespacedName parses a namespace/name value.
+func ParseNamespacedName(value string) (types.NamespacedName, error) {
+	parts := strings.Split(value, "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return types.NamespacedName{}, fmt.Errorf("%q is not in the namespace/name format", value)
+	}
+	return types.NamespacedName{Namespace: parts[0], Name: parts[1]}, nil
+}
+
+// controllerServiceKey returns the name of the controller Service given with
+// the ControllerServiceFlag of the provider, and false if the flag is not set.
+func controllerServiceKey(conf *i2gw.ProviderConf, providerName i2gw.ProviderName) (types.NamespacedName, bool, error) {
+	value := conf.ProviderSpecificFlags[string(providerName)][ControllerServiceFlag]
+	if value == "" {
+		return types.NamespacedName{}, false, nil
+	}
+	key, err := ParseNamespacedName(value)
+	if err != nil {
+		return types.NamespacedName{}, false, fmt.Errorf("invalid %s-%s flag: %w", providerName, ControllerServiceFlag, err)
+	}
+	return key, true, nil
+}
+
+// ReadControllerServiceFromCluster reads the controller Service given with the
+// ControllerServiceFlag of the provider from the cluster. It returns nil if the
+// flag is not set.
+func ReadControllerServiceFromCluster(ctx context.Context, conf *i2gw.ProviderConf, providerName i2gw.ProviderName) (*corev1.Service, error) {
+	key, ok, err := controllerServiceKey(conf, providerName)
+	if !ok || err != nil {
+		return nil, err
+	}
+	return ReadServiceFromCluster(ctx, conf.ClusterReader, key)
+}
+
+// ReadControllerServiceFromFile reads the controller Service given with the
+// ControllerServiceFlag of the provider from the file. It returns nil if the
+// flag is not set.
+func ReadControllerServiceFromFile(filename string, conf *i2gw.ProviderConf, providerName i2gw.ProviderName) (*corev1.Service, error) {
+	key, ok, err := controllerServiceKey(conf, providerName)
+	if !ok || err != nil {
+		return nil, err
+	}
+	return ReadServiceFromFile(filename, conf, key)
+}
+
+// ReadServiceFromCluster reads the Service with the given name from the cluster.
+func ReadServiceFromCluster(ctx context.Context, reader client.Reader, key types.NamespacedName) (*corev1.Service, error) {
+	if reader == nil {
+		return nil, fmt.Errorf("failed to get service %s: no cluster reader", key)
+	}
+	service := &corev1.Service{}
+	if err := reader.Get(ctx, key, service); err != nil {
+		return nil, fmt.Errorf("failed to get service %s from the cluster: %w", key, err)
+	}
+	return service, nil
+}
+
+// ReadServiceFromFile reads the Service with the given name from the file.
+// The Service is looked up regardless of the namespace the run is scoped to.
+func ReadServiceFromFile(filename string, conf *i2gw.ProviderConf, key types.NamespacedName) (*corev1.Service, error) {
+	stream, err := conf.ReadInputFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file %v: %w", filename, err)
+	}
+
+	unstructuredObjects, err := ExtractObjectsFromReader(bytes.NewReader(stream), key.Namespace)
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract objects: %w", err)
+	}
+
+	for _, f := range unstructuredObjects {
+		if f.GroupVersionKind().Kind != "Service" || f.GetName() != key.Name {
+			continue
+		}
+		var service corev1.Service
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(f.UnstructuredContent(), &service); err != nil {
+			return nil, fmt.Errorf("failed to parse service %s: %w", key, err)
+		}
+		return &service, nil
+	}
+	return nil, fmt.Errorf("service %s not found in file %v", key, filename)
+}
+
+// ControllerServiceFeature returns a FeatureParser setting the static load
+// balancer IPs of the controller Service as addresses of every generated
+// Gateway, and its internal load balancer annotations and labels as the
+// Gateway infrastructure, so that the new data plane keeps the network
+// identity of the controller.
+func ControllerServiceFeature(providerName i2gw.ProviderName, service *corev1.Service) i2gw.FeatureParser {
+	return func(_ []networkingv1.Ingress, gatewayResources *i2gw.GatewayResources) field.ErrorList {
+		return setGatewaysFromControllerService(providerName, service, gatewayResources)
+	}
+}
+
+func setGatewaysFromControllerService(providerName i2gw.ProviderName, service *corev1.Service, gatewayResources *i2gw.GatewayResources) field.ErrorList {
+	addresses, invalidIPs := controllerServiceAddresses(service)
+	for _, ip := range invalidIPs {
+		notifications.NotificationAggr.DispatchNotification(notifications.NewNotification(
+			notifications.WarningNotification,
+			fmt.Sprintf("ignoring %q static load balancer IP of the controller Service: not a valid IP", ip),
+			service,
+		), string(providerName))
+	}
+
+	infrastructure, dropped := controllerServiceInfrastructure(service)
+	if len(dropped) > 0 {
+		notifications.NotificationAggr.DispatchNotification(notifications.NewNotification(
+			notifications.WarningNotification,
+			fmt.Sprintf("a Gateway infrastructure holds up to %d labels and %d annotations, these entries of the controller Service were left out: %s", MaxGatewayInfrastructureEntries, MaxGatewayInfrastructureEntries, strings.Join(dropped, ", ")),
+			service,
+		), string(providerName))
+	}
+
+	keys := make([]types.NamespacedName, 0, len(gatewayResources.Gateways))
+	for key := range gatewayResources.Gateways {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+
+	if len(addresses) > 0 && len(keys) > 1 {
+		var gatewayNames []string
+		for _, key := range keys {
+			gatewayNames = append(gatewayNames, key.String())
+		}
+		notifications.NotificationAggr.DispatchNotification(notifications.NewNotification(
+			notifications.WarningNotification,
+			fmt.Sprintf("the static load balancer IPs of the controller Service (%s) are set on several Gateways (%s), only one of them can claim them", addressesString(addresses), strings.Join(gatewayNames, ", ")),
+			service,
+		), string(providerName))
+	}
+
+	for _, key := range keys {
+		gateway := gatewayResources.Gateways[key]
+		if dropped := appendGatewayAddresses(&gateway, addresses); len(dropped) > 0 {
+			notifyDroppedAddresses(providerName, key, dropped, service)
+		}
+		if infrastructure != nil {
+			gateway.Spec.Infrastructure = infrastructure.DeepCopy()
+		}
+		gatewayResources.Gateways[key] = gateway
+	}
+	return nil
+}
+
+// controllerServiceAddresses returns the static load balancer IPs of the
+// Service as Gateway addresses, along with the values that are not valid IPs.
+func controllerServiceAddresses(service *corev1.Service) ([]gatewayv1.GatewayAddress, []string) {
+	values := []string{service.Spec.LoadBalancerIP}
+	for _, annotation := range staticIPAnnotations {
+		values = append(values, strings.Split(service.Annotations[annotation], ",")...)
+	}
+
+	var addresses []gatewayv1.GatewayAddress
+	var invalidIPs []string
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+		if net.ParseIP(value) == nil {
+			invalidIPs = append(invalidIPs, value)
+			continue
+		}
+		address := gatewayv1.GatewayAddress{Type: PtrTo(gatewayv1.IPAddressType), Value: value}
+		if !containsAddress(addresses, address) {
+			addresses = append(addresses, address)
+		}
+	}
+	return addresses, invalidIPs
+}
+
+// controllerServiceInfrastructure returns the Gateway infrastructure holding the
+// labels and the internal load balancer annotations of the Service, along with
+// the entries left out because of the size limits of the infrastructure.
+func controllerServiceInfrastructure(service *corev1.Service) (*gatewayv1.GatewayInfrastructure, []string) {
+	var dropped []string
+
+	labels := map[gatewayv1.AnnotationKey]gatewayv1.AnnotationValue{}
+	labelKeys := make([]string, 0, len(service.Labels))
+	for key := range service.Labels {
+		labelKeys = append(labelKeys, key)
+	}
+	sort.Strings(labelKeys)
+	for _, key := range labelKeys {
+		if len(labels) == MaxGatewayInfrastructureEntries {
+			dropped = append(dropped, fmt.Sprintf("label %s", key))
+			continue
+		}
+		labels[gatewayv1.AnnotationKey(key)] = gatewayv1.AnnotationValue(service.Labels[key])
+	}
+
+	annotations := map[gatewayv1.AnnotationKey]gatewayv1.AnnotationValue{}
+	for _, key := range loadBalancerAnnotations {
+		value, ok := service.Annotations[key]
+		if !ok {
+			continue
+		}
+		if len(annotations) == MaxGatewayInfrastructureEntries {
+			dropped = append(dropped, fmt.Sprintf("annotation %s", key))
+			continue
+		}
+		annotations[gatewayv1.AnnotationKey(key)] = gatewayv1.AnnotationValue(value)
+	}
+
+	if len(labels) == 0 && len(annotations) == 0 {
+		return nil, dropped
+	}
+	infrastructure := &gatewayv1.GatewayInfrastructure{}
+	if len(labels) > 0 {
+		infrastructure.Labels = labels
+	}
+	if len(annotations) > 0 {
+		infrastructure.Annotations = annotations
+	}
+	return infrastructure, dropped
+}
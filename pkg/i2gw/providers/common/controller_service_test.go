@@ -0,0 +1,232 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+func Test_ControllerServiceFeature(t *testing.T) {
+	ipAddress := func(value string) gatewayv1.GatewayAddress {
+		return gatewayv1.GatewayAddress{Type: PtrTo(gatewayv1.IPAddressType), Value: value}
+	}
+
+	var manyAddresses []gatewayv1.GatewayAddress
+	for i := 0; i < MaxGatewayAddresses; i++ {
+		manyAddresses = append(manyAddresses, ipAddress(fmt.Sprintf("10.0.0.%d", i)))
+	}
+
+	manyLabels := map[string]string{}
+	for i := 0; i <= MaxGatewayInfrastructureEntries; i++ {
+		manyLabels[fmt.Sprintf("label-%d", i)] = "value"
+	}
+
+	testCases := []struct {
+		name                   string
+		service                corev1.Service
+		gateways               []types.NamespacedName
+		existingAddresses      []gatewayv1.GatewayAddress
+		expectedAddresses      []gatewayv1.GatewayAddress
+		expectedInfrastructure *gatewayv1.GatewayInfrastructure
+		expectedNotifications  []string
+	}{{
+		name: "static IP, internal load balancer and labels",
+		service: corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels: map[string]string{"app.kubernetes.io/name": "ingress-nginx"},
+				Annotations: map[string]string{
+					"service.beta.kubernetes.io/azure-load-balancer-internal": "true",
+					"kubectl.kubernetes.io/last-applied-configuration":        "{}",
+				},
+			},
+			Spec: corev1.ServiceSpec{LoadBalancerIP: "10.0.0.1"},
+		},
+		gateways:          []types.NamespacedName{{Namespace: "default", Name: "nginx"}},
+		expectedAddresses: []gatewayv1.GatewayAddress{ipAddress("10.0.0.1")},
+		expectedInfrastructure: &gatewayv1.GatewayInfrastructure{
+			Labels:      map[gatewayv1.AnnotationKey]gatewayv1.AnnotationValue{"app.kubernetes.io/name": "ingress-nginx"},
+			Annotations: map[gatewayv1.AnnotationKey]gatewayv1.AnnotationValue{"service.beta.kubernetes.io/azure-load-balancer-internal": "true"},
+		},
+	}, {
+		name: "static IPs from annotations are merged with existing addresses",
+		service: corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{"metallb.universe.tf/loadBalancerIPs": "10.0.0.1, 10.0.0.2,not-an-ip"},
+			},
+		},
+		gateways:              []types.NamespacedName{{Namespace: "default", Name: "nginx"}},
+		existingAddresses:     []gatewayv1.GatewayAddress{ipAddress("10.0.0.1")},
+		expectedAddresses:     []gatewayv1.GatewayAddress{ipAddress("10.0.0.1"), ipAddress("10.0.0.2")},
+		expectedNotifications: []string{`ignoring "not-an-ip" static load balancer IP`},
+	}, {
+		name: "static IP set on several gateways",
+		service: corev1.Service{
+			Spec: corev1.ServiceSpec{LoadBalancerIP: "10.0.0.1"},
+		},
+		gateways:              []types.NamespacedName{{Namespace: "a", Name: "nginx"}, {Namespace: "b", Name: "nginx"}},
+		expectedAddresses:     []gatewayv1.GatewayAddress{ipAddress("10.0.0.1")},
+		expectedNotifications: []string{"are set on several Gateways (a/nginx, b/nginx)"},
+	}, {
+		name: "too many labels",
+		service: corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Labels: manyLabels},
+		},
+		gateways: []types.NamespacedName{{Namespace: "default", Name: "nginx"}},
+		expectedInfrastructure: &gatewayv1.GatewayInfrastructure{
+			Labels: map[gatewayv1.AnnotationKey]gatewayv1.AnnotationValue{
+				"label-0": "value", "label-1": "value", "label-2": "value", "label-3": "value",
+				"label-4": "value", "label-5": "value", "label-6": "value", "label-7": "value",
+			},
+		},
+		expectedNotifications: []string{"label label-8"},
+	}, {
+		name: "too many addresses",
+		service: corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{"metallb.universe.tf/loadBalancerIPs": "10.0.0.16,10.0.0.17"},
+			},
+		},
+		gateways:              []types.NamespacedName{{Namespace: "default", Name: "nginx"}},
+		existingAddresses:     manyAddresses,
+		expectedAddresses:     manyAddresses,
+		expectedNotifications: []string{"these addresses were left out of Gateway default/nginx: 10.0.0.16,10.0.0.17"},
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			gatewayResources := i2gw.GatewayResources{Gateways: map[types.NamespacedName]gatewayv1.Gateway{}}
+			for _, key := range tc.gateways {
+				gatewayResources.Gateways[key] = gatewayv1.Gateway{
+					ObjectMeta: metav1.ObjectMeta{Namespace: key.Namespace, Name: key.Name},
+					Spec:       gatewayv1.GatewaySpec{Addresses: tc.existingAddresses},
+				}
+			}
+
+			errs := ControllerServiceFeature("test", &tc.service)(nil, &gatewayResources)
+			if len(errs) != 0 {
+				t.Errorf("Expected no errors, got: %+v", errs)
+			}
+
+			for key, gateway := range gatewayResources.Gateways {
+				if diff := cmp.Diff(tc.expectedAddresses, gateway.Spec.Addresses); diff != "" {
+					t.Errorf("Unexpected addresses for Gateway %s, diff (-want +got):\n%s", key, diff)
+				}
+				if diff := cmp.Diff(tc.expectedInfrastructure, gateway.Spec.Infrastructure); diff != "" {
+					t.Errorf("Unexpected infrastructure for Gateway %s, diff (-want +got):\n%s", key, diff)
+				}
+			}
+
+			tables := notifications.NotificationAggr.CreateNotificationTables()
+			for _, expected := range tc.expectedNotifications {
+				if !strings.Contains(tables["test"], expected) {
+					t.Errorf("Expected notification %q, got:\n%s", expected, tables["test"])
+				}
+			}
+			if len(tc.expectedNotifications) == 0 && len(tables) != 0 {
+				t.Errorf("Expected no notifications, got: %+v", tables)
+			}
+		})
+	}
+}
+
+func Test_ReadControllerServiceFromFile(t *testing.T) {
+	manifest := `apiVersion: v1
+kind: Service
+metadata:
+  name: ingress-nginx-controller
+  namespace: ingress-nginx
+spec:
+  type: LoadBalancer
+  loadBalancerIP: 10.0.0.1
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: ingress-nginx-controller
+  namespace: default
+spec:
+  type: ClusterIP
+`
+	filename := filepath.Join(t.TempDir(), "manifest.yaml")
+	if err := os.WriteFile(filename, []byte(manifest), 0o600); err != nil {
+		t.Fatalf("Failed to write manifest: %v", err)
+	}
+
+	testCases := []struct {
+		name          string
+		flag          string
+		expectedIP    string
+		expectedError bool
+	}{{
+		name: "flag not set",
+	}, {
+		name:       "service found",
+		flag:       "ingress-nginx/ingress-nginx-controller",
+		expectedIP: "10.0.0.1",
+	}, {
+		name:          "service not found",
+		flag:          "ingress-nginx/missing",
+		expectedError: true,
+	}, {
+		name:          "invalid flag",
+		flag:          "ingress-nginx-controller",
+		expectedError: true,
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			conf := &i2gw.ProviderConf{
+				// The Service is read regardless of the namespace the run is scoped to.
+				Namespace: "default",
+				ProviderSpecificFlags: map[string]map[string]string{
+					"test": {ControllerServiceFlag: tc.flag},
+				},
+			}
+			service, err := ReadControllerServiceFromFile(filename, conf, "test")
+			if tc.expectedError {
+				if err == nil {
+					t.Errorf("Expected an error, got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if tc.flag == "" {
+				if service != nil {
+					t.Errorf("Expected no service, got %+v", service)
+				}
+				return
+			}
+			if service == nil || service.Spec.LoadBalancerIP != tc.expectedIP {
+				t.Errorf("Expected service with load balancer IP %s, got %+v", tc.expectedIP, service)
+			}
+		})
+	}
+}
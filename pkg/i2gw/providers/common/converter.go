@@ -78,6 +78,12 @@ var (
 		Kind:    "HTTPRoute",
 	}
 
+	GRPCRouteGVK = schema.GroupVersionKind{
+		Group:   "gateway.networking.k8s.io",
+		Version: "v1alpha2",
+		Kind:    "GRPCRoute",
+	}
+
 	TLSRouteGVK = schema.GroupVersionKind{
 		Group:   "gateway.networking.k8s.io",
 		Version: "v1alpha2",
@@ -90,6 +96,18 @@ var (
 		Kind:    "TCPRoute",
 	}
 
+	UDPRouteGVK = schema.GroupVersionKind{
+		Group:   "gateway.networking.k8s.io",
+		Version: "v1alpha2",
+		Kind:    "UDPRoute",
+	}
+
+	BackendTLSPolicyGVK = schema.GroupVersionKind{
+		Group:   "gateway.networking.k8s.io",
+		Version: "v1alpha2",
+		Kind:    "BackendTLSPolicy",
+	}
+
 	ReferenceGrantGVK = schema.GroupVersionKind{
 		Group:   "gateway.networking.k8s.io",
 		Version: "v1beta1",
@@ -173,10 +191,8 @@ func (a *ingressAggregator) toHTTPRoutesAndGateways(options i2gw.ProviderImpleme
 
 	for _, rg := range a.ruleGroups {
 		listener := gatewayv1.Listener{}
-		if rg.host != "" {
-			listener.Hostname = (*gatewayv1.Hostname)(&rg.host)
-		} else if len(rg.tls) == 1 && len(rg.tls[0].Hosts) == 1 {
-			listener.Hostname = (*gatewayv1.Hostname)(&rg.tls[0].Hosts[0])
+		if hostname := ListenerHostname(rg.host, rg.tls); hostname != "" {
+			listener.Hostname = (*gatewayv1.Hostname)(&hostname)
 		}
 		if len(rg.tls) > 0 {
 			listener.TLS = &gatewayv1.GatewayTLSConfig{}
@@ -247,20 +263,20 @@ func (a *ingressAggregator) toHTTPRoutesAndGateways(options i2gw.ProviderImpleme
 			gatewaysByKey[gwKey] = gateway
 		}
 		for _, listener := range listeners {
-			var listenerNamePrefix string
-			if listener.Hostname != nil && *listener.Hostname != "" {
-				listenerNamePrefix = fmt.Sprintf("%s-", NameFromHost(string(*listener.Hostname)))
+			var hostname string
+			if listener.Hostname != nil {
+				hostname = string(*listener.Hostname)
 			}
 
 			gateway.Spec.Listeners = append(gateway.Spec.Listeners, gatewayv1.Listener{
-				Name:     gatewayv1.SectionName(fmt.Sprintf("%shttp", listenerNamePrefix)),
+				Name:     ListenerName(hostname, "http"),
 				Hostname: listener.Hostname,
 				Port:     80,
 				Protocol: gatewayv1.HTTPProtocolType,
 			})
 			if listener.TLS != nil {
 				gateway.Spec.Listeners = append(gateway.Spec.Listeners, gatewayv1.Listener{
-					Name:     gatewayv1.SectionName(fmt.Sprintf("%shttps", listenerNamePrefix)),
+					Name:     ListenerName(hostname, "https"),
 					Hostname: listener.Hostname,
 					Port:     443,
 					Protocol: gatewayv1.HTTPSProtocolType,
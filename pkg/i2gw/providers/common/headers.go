@@ -0,0 +1,164 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"fmt"
+	"strings"
+
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+// HeaderFilterBuilder builds the header modifier filters, and URLRewrite
+// hostname, of a rule from the header settings of the Ingresses feeding it.
+// The first setting of a header wins, and the headers later set to another
+// value are reported as conflicts.
+type HeaderFilterBuilder struct {
+	request          []gatewayv1.HTTPHeader
+	response         []gatewayv1.HTTPHeader
+	hostname         string
+	conflictingNames []string
+}
+
+// AddRequestHeaders adds headers to set on the requests.
+func (b *HeaderFilterBuilder) AddRequestHeaders(headers []gatewayv1.HTTPHeader) {
+	for _, header := range headers {
+		b.request = b.merge(b.request, header)
+	}
+}
+
+// AddResponseHeaders adds headers to set on the responses.
+func (b *HeaderFilterBuilder) AddResponseHeaders(headers []gatewayv1.HTTPHeader) {
+	for _, header := range headers {
+		b.response = b.merge(b.response, header)
+	}
+}
+
+// SetHostname sets the Host header sent to the backends, as set by setting,
+// which names the conflict if another hostname is already set.
+func (b *HeaderFilterBuilder) SetHostname(hostname, setting string) {
+	if hostname == "" {
+		return
+	}
+	if b.hostname == "" {
+		b.hostname = hostname
+	} else if b.hostname != hostname {
+		b.addConflict(setting)
+	}
+}
+
+func (b *HeaderFilterBuilder) merge(headers []gatewayv1.HTTPHeader, header gatewayv1.HTTPHeader) []gatewayv1.HTTPHeader {
+	for _, existing := range headers {
+		if strings.EqualFold(string(existing.Name), string(header.Name)) {
+			if existing.Value != header.Value {
+				b.addConflict(fmt.Sprintf("header %s", existing.Name))
+			}
+			return headers
+		}
+	}
+	return append(headers, header)
+}
+
+func (b *HeaderFilterBuilder) addConflict(name string) {
+	for _, conflict := range b.conflictingNames {
+		if conflict == name {
+			return
+		}
+	}
+	b.conflictingNames = append(b.conflictingNames, name)
+}
+
+// Conflicts returns the headers, and hostname settings, set to different
+// values.
+func (b *HeaderFilterBuilder) Conflicts() []string {
+	return b.conflictingNames
+}
+
+// Apply sets the headers and hostname to the filters of the rule.
+func (b *HeaderFilterBuilder) Apply(rule *gatewayv1.HTTPRouteRule) {
+	SetRuleHeaders(rule, gatewayv1.HTTPRouteFilterRequestHeaderModifier, b.request)
+	SetRuleHeaders(rule, gatewayv1.HTTPRouteFilterResponseHeaderModifier, b.response)
+	if b.hostname != "" {
+		hostname := gatewayv1.PreciseHostname(b.hostname)
+		RuleURLRewrite(rule).Hostname = &hostname
+	}
+}
+
+// SetRuleHeaders sets the headers with the header modifier filter of the given
+// type of the rule, which is added if the rule has none. The headers replace
+// the ones of the same name already set, header names being case-insensitive,
+// so that the most specific setting, applied last, wins.
+func SetRuleHeaders(rule *gatewayv1.HTTPRouteRule, filterType gatewayv1.HTTPRouteFilterType, headers []gatewayv1.HTTPHeader) {
+	if len(headers) == 0 {
+		return
+	}
+	headerFilter := RuleHeaderFilter(rule, filterType)
+	for _, header := range headers {
+		headerFilter.Set = removeHeader(headerFilter.Set, string(header.Name))
+		headerFilter.Set = append(headerFilter.Set, header)
+	}
+}
+
+// RuleHeaderFilter returns the header modifier filter of the given type of the
+// rule, adding it if the rule has none, as a rule can only have one.
+func RuleHeaderFilter(rule *gatewayv1.HTTPRouteRule, filterType gatewayv1.HTTPRouteFilterType) *gatewayv1.HTTPHeaderFilter {
+	for i := range rule.Filters {
+		if rule.Filters[i].Type != filterType {
+			continue
+		}
+		if filterType == gatewayv1.HTTPRouteFilterRequestHeaderModifier {
+			return rule.Filters[i].RequestHeaderModifier
+		}
+		return rule.Filters[i].ResponseHeaderModifier
+	}
+
+	headerFilter := &gatewayv1.HTTPHeaderFilter{}
+	filter := gatewayv1.HTTPRouteFilter{Type: filterType}
+	if filterType == gatewayv1.HTTPRouteFilterRequestHeaderModifier {
+		filter.RequestHeaderModifier = headerFilter
+	} else {
+		filter.ResponseHeaderModifier = headerFilter
+	}
+	rule.Filters = append(rule.Filters, filter)
+	return headerFilter
+}
+
+// RuleURLRewrite returns the URLRewrite filter of the rule, adding it if the
+// rule has none, as a rule can only have one.
+func RuleURLRewrite(rule *gatewayv1.HTTPRouteRule) *gatewayv1.HTTPURLRewriteFilter {
+	for i := range rule.Filters {
+		if rule.Filters[i].Type == gatewayv1.HTTPRouteFilterURLRewrite {
+			return rule.Filters[i].URLRewrite
+		}
+	}
+	urlRewrite := &gatewayv1.HTTPURLRewriteFilter{}
+	rule.Filters = append(rule.Filters, gatewayv1.HTTPRouteFilter{
+		Type:       gatewayv1.HTTPRouteFilterURLRewrite,
+		URLRewrite: urlRewrite,
+	})
+	return urlRewrite
+}
+
+func removeHeader(headers []gatewayv1.HTTPHeader, name string) []gatewayv1.HTTPHeader {
+	var kept []gatewayv1.HTTPHeader
+	for _, header := range headers {
+		if !strings.EqualFold(string(header.Name), name) {
+			kept = append(kept, header)
+		}
+	}
+	return kept
+}
@@ -0,0 +1,68 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+func Test_HeaderFilterBuilder(t *testing.T) {
+	rule := gatewayv1.HTTPRouteRule{
+		Filters: []gatewayv1.HTTPRouteFilter{{
+			Type: gatewayv1.HTTPRouteFilterRequestHeaderModifier,
+			RequestHeaderModifier: &gatewayv1.HTTPHeaderFilter{
+				Set: []gatewayv1.HTTPHeader{{Name: "X-Team", Value: "default"}, {Name: "X-Env", Value: "production"}},
+			},
+		}},
+	}
+
+	var builder HeaderFilterBuilder
+	builder.AddRequestHeaders([]gatewayv1.HTTPHeader{{Name: "x-team", Value: "payments"}})
+	builder.AddRequestHeaders([]gatewayv1.HTTPHeader{{Name: "X-Team", Value: "billing"}})
+	builder.AddResponseHeaders([]gatewayv1.HTTPHeader{{Name: "X-Frame-Options", Value: "DENY"}})
+	builder.SetHostname("backend.example.com", "upstream-vhost")
+	builder.SetHostname("other.example.com", "upstream-vhost")
+	builder.Apply(&rule)
+
+	expected := gatewayv1.HTTPRouteRule{
+		Filters: []gatewayv1.HTTPRouteFilter{{
+			Type: gatewayv1.HTTPRouteFilterRequestHeaderModifier,
+			RequestHeaderModifier: &gatewayv1.HTTPHeaderFilter{
+				Set: []gatewayv1.HTTPHeader{{Name: "X-Env", Value: "production"}, {Name: "x-team", Value: "payments"}},
+			},
+		}, {
+			Type: gatewayv1.HTTPRouteFilterResponseHeaderModifier,
+			ResponseHeaderModifier: &gatewayv1.HTTPHeaderFilter{
+				Set: []gatewayv1.HTTPHeader{{Name: "X-Frame-Options", Value: "DENY"}},
+			},
+		}, {
+			Type: gatewayv1.HTTPRouteFilterURLRewrite,
+			URLRewrite: &gatewayv1.HTTPURLRewriteFilter{
+				Hostname: PtrTo(gatewayv1.PreciseHostname("backend.example.com")),
+			},
+		}},
+	}
+	if diff := cmp.Diff(expected, rule); diff != "" {
+		t.Errorf("Unexpected rule, diff (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]string{"header x-team", "upstream-vhost"}, builder.Conflicts()); diff != "" {
+		t.Errorf("Unexpected conflicts, diff (-want +got):\n%s", diff)
+	}
+}
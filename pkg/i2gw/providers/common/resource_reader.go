@@ -22,9 +22,11 @@ import (
 	"errors"
 	"fmt"
 	"io"
-	"os"
 
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw"
+	corev1 "k8s.io/api/core/v1"
 	networkingv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
@@ -33,31 +35,38 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
-func ReadIngressesFromCluster(ctx context.Context, client client.Client, ingressClasses sets.Set[string]) (map[types.NamespacedName]*networkingv1.Ingress, error) {
-	var ingressList networkingv1.IngressList
-	err := client.List(ctx, &ingressList)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get ingresses from the cluster: %w", err)
-	}
+// IngressGVK is the GroupVersionKind of the Ingresses read from the cluster.
+var IngressGVK = networkingv1.SchemeGroupVersion.WithKind("Ingress")
 
+// ReadIngressesFromCluster reads the Ingresses of the given classes through the
+// shared cluster cache. Only the matching Ingresses are kept in the returned map.
+func ReadIngressesFromCluster(ctx context.Context, cache *i2gw.ClusterCache, ingressClasses sets.Set[string]) (map[types.NamespacedName]*networkingv1.Ingress, error) {
 	ingresses := map[types.NamespacedName]*networkingv1.Ingress{}
-	for i, ingress := range ingressList.Items {
+	err := cache.Stream(ctx, IngressGVK, func(obj *unstructured.Unstructured) error {
+		var ingress networkingv1.Ingress
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.UnstructuredContent(), &ingress); err != nil {
+			return fmt.Errorf("failed to parse ingress %s/%s: %w", obj.GetNamespace(), obj.GetName(), err)
+		}
 		if !ingressClasses.Has(GetIngressClass(ingress)) {
-			continue
+			return nil
 		}
-		ingresses[types.NamespacedName{Namespace: ingress.Namespace, Name: ingress.Name}] = &ingressList.Items[i]
+		ingresses[types.NamespacedName{Namespace: ingress.Namespace, Name: ingress.Name}] = &ingress
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ingresses from the cluster: %w", err)
 	}
 
 	return ingresses, nil
 }
 
-func ReadIngressesFromFile(filename, namespace string, ingressClasses sets.Set[string]) (map[types.NamespacedName]*networkingv1.Ingress, error) {
-	stream, err := os.ReadFile(filename)
+func ReadIngressesFromFile(filename string, conf *i2gw.ProviderConf, ingressClasses sets.Set[string]) (map[types.NamespacedName]*networkingv1.Ingress, error) {
+	stream, err := conf.ReadInputFile(filename)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read file %v: %w", filename, err)
 	}
 
-	unstructuredObjects, err := ExtractObjectsFromReader(bytes.NewReader(stream), namespace)
+	unstructuredObjects, err := ExtractObjectsFromReader(bytes.NewReader(stream), conf.Namespace)
 	if err != nil {
 		return nil, fmt.Errorf("failed to extract objects: %w", err)
 	}
@@ -81,6 +90,110 @@ func ReadIngressesFromFile(filename, namespace string, ingressClasses sets.Set[s
 	return ingresses, nil
 }
 
+// ReadServicesFromCluster gets the Services referenced by the backends of the
+// Ingresses from the cluster, ignoring the missing ones, instead of listing
+// every Service of the cluster.
+func ReadServicesFromCluster(ctx context.Context, reader client.Reader, ingresses map[types.NamespacedName]*networkingv1.Ingress) (map[types.NamespacedName]*corev1.Service, error) {
+	keys := sets.New[types.NamespacedName]()
+	addBackend := func(namespace string, backend *networkingv1.IngressBackend) {
+		if backend != nil && backend.Service != nil {
+			keys.Insert(types.NamespacedName{Namespace: namespace, Name: backend.Service.Name})
+		}
+	}
+	for _, ingress := range ingresses {
+		addBackend(ingress.Namespace, ingress.Spec.DefaultBackend)
+		for _, rule := range ingress.Spec.Rules {
+			if rule.HTTP == nil {
+				continue
+			}
+			for i := range rule.HTTP.Paths {
+				addBackend(ingress.Namespace, &rule.HTTP.Paths[i].Backend)
+			}
+		}
+	}
+
+	services := map[types.NamespacedName]*corev1.Service{}
+	for key := range keys {
+		service, err := ReadServiceFromCluster(ctx, reader, key)
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return nil, err
+		}
+		services[key] = service
+	}
+	return services, nil
+}
+
+// ReadServicesFromFile reads the Services of the file, only the ones of the
+// namespace of the run if it is scoped to one.
+func ReadServicesFromFile(filename string, conf *i2gw.ProviderConf) (map[types.NamespacedName]*corev1.Service, error) {
+	stream, err := conf.ReadInputFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file %v: %w", filename, err)
+	}
+
+	unstructuredObjects, err := ExtractObjectsFromReader(bytes.NewReader(stream), conf.Namespace)
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract objects: %w", err)
+	}
+
+	services := map[types.NamespacedName]*corev1.Service{}
+	for _, f := range unstructuredObjects {
+		if f.GroupVersionKind().Kind != "Service" {
+			continue
+		}
+		var service corev1.Service
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(f.UnstructuredContent(), &service); err != nil {
+			return nil, fmt.Errorf("failed to parse service %s/%s: %w", f.GetNamespace(), f.GetName(), err)
+		}
+		services[types.NamespacedName{Namespace: service.Namespace, Name: service.Name}] = &service
+	}
+	return services, nil
+}
+
+// ReadConfigMapFromCluster reads the ConfigMap with the given name from the
+// cluster.
+func ReadConfigMapFromCluster(ctx context.Context, reader client.Reader, key types.NamespacedName) (*corev1.ConfigMap, error) {
+	if reader == nil {
+		return nil, fmt.Errorf("failed to get configmap %s: no cluster reader", key)
+	}
+	configMap := &corev1.ConfigMap{}
+	if err := reader.Get(ctx, key, configMap); err != nil {
+		return nil, fmt.Errorf("failed to get configmap %s from the cluster: %w", key, err)
+	}
+	return configMap, nil
+}
+
+// ReadConfigMapFromFile reads the ConfigMap with the given name from the file.
+// The ConfigMap is looked up regardless of the namespace the run is scoped to.
+// Like ReadConfigMapFromCluster, the error wraps a NotFound API error when the
+// ConfigMap is missing.
+func ReadConfigMapFromFile(filename string, conf *i2gw.ProviderConf, key types.NamespacedName) (*corev1.ConfigMap, error) {
+	stream, err := conf.ReadInputFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file %v: %w", filename, err)
+	}
+
+	unstructuredObjects, err := ExtractObjectsFromReader(bytes.NewReader(stream), key.Namespace)
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract objects: %w", err)
+	}
+
+	for _, f := range unstructuredObjects {
+		if f.GroupVersionKind().Kind != "ConfigMap" || f.GetName() != key.Name {
+			continue
+		}
+		var configMap corev1.ConfigMap
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(f.UnstructuredContent(), &configMap); err != nil {
+			return nil, fmt.Errorf("failed to parse configmap %s: %w", key, err)
+		}
+		return &configMap, nil
+	}
+	return nil, fmt.Errorf("failed to read configmap from file %v: %w", filename, apierrors.NewNotFound(corev1.Resource("configmaps"), key.String()))
+}
+
 // ExtractObjectsFromReader extracts all objects from a reader,
 // which is created from YAML or JSON input files.
 // It retrieves all objects, including nested ones if they are contained within a list.
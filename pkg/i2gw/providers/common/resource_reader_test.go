@@ -18,16 +18,22 @@ package common
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"os"
+	"sort"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
 	networkingv1 "k8s.io/api/networking/v1"
 	apiequality "k8s.io/apimachinery/pkg/api/equality"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
 
 func Test_ExtractObjectsFromReader(t *testing.T) {
@@ -142,3 +148,54 @@ func compareIngressLists(t *testing.T, gotIngressList *networkingv1.IngressList,
 		}
 	}
 }
+
+// getOnlyReader fails the List calls, to check that the Services are not
+// listed.
+type getOnlyReader struct {
+	client.Reader
+}
+
+func (r getOnlyReader) List(context.Context, client.ObjectList, ...client.ListOption) error {
+	return fmt.Errorf("unexpected list")
+}
+
+func Test_ReadServicesFromCluster(t *testing.T) {
+	service := func(namespace, name string) *corev1.Service {
+		return &corev1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name}}
+	}
+	reader := getOnlyReader{fake.NewClientBuilder().WithObjects(
+		service("namespace1", "default-backend"),
+		service("namespace1", "path-backend"),
+		service("namespace1", "unreferenced"),
+		service("namespace2", "path-backend"),
+	).Build()}
+
+	ing := ingress(80, "ingress1", "namespace1")
+	ing.Spec.DefaultBackend = &networkingv1.IngressBackend{
+		Service: &networkingv1.IngressServiceBackend{Name: "default-backend"},
+	}
+	ing.Spec.Rules[0].HTTP.Paths = append(ing.Spec.Rules[0].HTTP.Paths, networkingv1.HTTPIngressPath{
+		Path: "/missing",
+		Backend: networkingv1.IngressBackend{
+			Service: &networkingv1.IngressServiceBackend{Name: "missing"},
+		},
+	})
+	ing.Spec.Rules[0].HTTP.Paths[0].Backend.Service.Name = "path-backend"
+	ingresses := map[types.NamespacedName]*networkingv1.Ingress{
+		{Namespace: ing.Namespace, Name: ing.Name}: &ing,
+	}
+
+	services, err := ReadServicesFromCluster(context.Background(), reader, ingresses)
+	if err != nil {
+		t.Fatalf("Expected no error but got %v", err)
+	}
+	var keys []string
+	for key := range services {
+		keys = append(keys, key.String())
+	}
+	sort.Strings(keys)
+	expectedKeys := []string{"namespace1/default-backend", "namespace1/path-backend"}
+	if diff := cmp.Diff(expectedKeys, keys); diff != "" {
+		t.Errorf("Unexpected Services read (-want +got): %s", diff)
+	}
+}
@@ -0,0 +1,143 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/utils/ptr"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+// GroupCaptureUsed returns an error if the path uses regular expression
+// capture groups.
+func GroupCaptureUsed(path string) *field.Error {
+	if strings.Contains(path, "(") && strings.Contains(path, ")") {
+		return field.Invalid(field.NewPath("metadata", "annotations"), path, "group capture not supported")
+	}
+	return nil
+}
+
+// RewritePath is the Gateway API equivalent of a path rewrite.
+type RewritePath struct {
+	// MatchPrefix, if not empty, is the path prefix the rewritten requests must
+	// be matched with, replacing the regular expression of the original path.
+	MatchPrefix string
+	// Modifier is the path modifier of the URLRewrite filter.
+	Modifier gatewayv1.HTTPPathModifier
+	// Difference, if not empty, describes how the equivalent behaves
+	// differently from the original rewrite.
+	Difference string
+}
+
+// captureSuffix is a regular expression suffix capturing the rest of the path
+// after a literal prefix.
+type captureSuffix struct {
+	suffix string
+	// restGroup is the reference to the group capturing the rest of the path.
+	restGroup string
+	// restHasSlash tells whether the captured rest of the path starts with the
+	// slash following the prefix.
+	restHasSlash bool
+	difference   string
+}
+
+var captureSuffixes = []captureSuffix{
+	{suffix: "(/|$)(.*)", restGroup: "$2"},
+	{suffix: "/(.*)", restGroup: "$1", difference: "requests to %s without a trailing slash are matched as well"},
+	{suffix: "(/.*)", restGroup: "$1", restHasSlash: true, difference: "requests to %s without a trailing slash are matched as well"},
+	{suffix: "(.*)", restGroup: "$1", restHasSlash: true, difference: "requests to paths starting with %s in the middle of a path segment are no longer matched"},
+}
+
+// ToRewritePath returns the Gateway API equivalent of the regular expression
+// based rewrite of nginx, replacing the whole path matched by the path
+// expression with the target, where $N references the capture groups of the
+// path expression.
+//
+// A target without capture group references replaces the full path, whatever
+// the path expression, which keeps matching the same requests. Targets
+// appending the rest of the path captured after a literal prefix replace that
+// prefix. Other rewrites have no equivalent, and an error describing why is
+// returned.
+func ToRewritePath(path, target string) (RewritePath, error) {
+	if strings.Contains(target, "?") {
+		return RewritePath{}, fmt.Errorf("target %q sets a query string, which cannot be rewritten by Gateway API", target)
+	}
+
+	if !strings.Contains(target, "$") {
+		return RewritePath{
+			Modifier: gatewayv1.HTTPPathModifier{
+				Type:            gatewayv1.FullPathHTTPPathModifier,
+				ReplaceFullPath: ptr.To(target),
+			},
+		}, nil
+	}
+	if !strings.Contains(path, "(") {
+		return RewritePath{}, fmt.Errorf("target %q references capture groups, but path %q has none", target, path)
+	}
+
+	for _, cs := range captureSuffixes {
+		if !strings.HasSuffix(path, cs.suffix) {
+			continue
+		}
+		prefix := strings.TrimSuffix(path, cs.suffix)
+		if regexp.QuoteMeta(prefix) != prefix {
+			return RewritePath{}, fmt.Errorf("path %q is not a literal prefix followed by %s", path, cs.suffix)
+		}
+		if !strings.HasSuffix(target, cs.restGroup) || strings.Contains(strings.TrimSuffix(target, cs.restGroup), "$") {
+			return RewritePath{}, fmt.Errorf("target %q must be a literal prefix followed by %s to be expressed as a prefix replacement", target, cs.restGroup)
+		}
+		targetPrefix := strings.TrimSuffix(target, cs.restGroup)
+		if cs.restHasSlash {
+			if strings.HasSuffix(targetPrefix, "/") {
+				return RewritePath{}, fmt.Errorf("target %q adds a slash before %s, which already starts with one", target, cs.restGroup)
+			}
+		} else {
+			if !strings.HasSuffix(targetPrefix, "/") {
+				return RewritePath{}, fmt.Errorf("target %q appends %s to %q without a separating slash", target, cs.restGroup, targetPrefix)
+			}
+			targetPrefix = strings.TrimSuffix(targetPrefix, "/")
+		}
+
+		if prefix == "" {
+			prefix = "/"
+		}
+		if targetPrefix == "" {
+			targetPrefix = "/"
+		}
+		rewritePath := RewritePath{
+			MatchPrefix: prefix,
+			Modifier: gatewayv1.HTTPPathModifier{
+				Type:               gatewayv1.PrefixMatchHTTPPathModifier,
+				ReplacePrefixMatch: ptr.To(targetPrefix),
+			},
+		}
+		if cs.difference != "" {
+			rewritePath.Difference = fmt.Sprintf(cs.difference, prefix)
+		}
+		return rewritePath, nil
+	}
+
+	supported := make([]string, 0, len(captureSuffixes))
+	for _, cs := range captureSuffixes {
+		supported = append(supported, "<prefix>"+cs.suffix)
+	}
+	return RewritePath{}, fmt.Errorf("path %q uses capture groups in a form that has no Gateway API equivalent, only %s are supported", path, strings.Join(supported, ", "))
+}
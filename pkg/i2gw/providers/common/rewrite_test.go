@@ -0,0 +1,132 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+func Test_ToRewritePath(t *testing.T) {
+	fullPath := func(path string) gatewayv1.HTTPPathModifier {
+		return gatewayv1.HTTPPathModifier{Type: gatewayv1.FullPathHTTPPathModifier, ReplaceFullPath: PtrTo(path)}
+	}
+	prefix := func(path string) gatewayv1.HTTPPathModifier {
+		return gatewayv1.HTTPPathModifier{Type: gatewayv1.PrefixMatchHTTPPathModifier, ReplacePrefixMatch: PtrTo(path)}
+	}
+
+	testCases := []struct {
+		name               string
+		path               string
+		target             string
+		expected           RewritePath
+		expectedDifference bool
+		expectedError      bool
+	}{{
+		name:     "static target replaces the full path",
+		path:     "/foo",
+		target:   "/",
+		expected: RewritePath{Modifier: fullPath("/")},
+	}, {
+		name:     "static target on a capture group path replaces the full path",
+		path:     "/api(/|$)(.*)",
+		target:   "/",
+		expected: RewritePath{Modifier: fullPath("/")},
+	}, {
+		name:     "strip prefix",
+		path:     "/foo(/|$)(.*)",
+		target:   "/$2",
+		expected: RewritePath{MatchPrefix: "/foo", Modifier: prefix("/")},
+	}, {
+		name:     "replace prefix",
+		path:     "/foo(/|$)(.*)",
+		target:   "/bar/$2",
+		expected: RewritePath{MatchPrefix: "/foo", Modifier: prefix("/bar")},
+	}, {
+		name:               "rest captured after the slash",
+		path:               "/foo/(.*)",
+		target:             "/bar/$1",
+		expected:           RewritePath{MatchPrefix: "/foo", Modifier: prefix("/bar")},
+		expectedDifference: true,
+	}, {
+		name:               "rest captured with the slash",
+		path:               "/foo(/.*)",
+		target:             "$1",
+		expected:           RewritePath{MatchPrefix: "/foo", Modifier: prefix("/")},
+		expectedDifference: true,
+	}, {
+		name:               "root capture",
+		path:               "/(.*)",
+		target:             "/api/$1",
+		expected:           RewritePath{MatchPrefix: "/", Modifier: prefix("/api")},
+		expectedDifference: true,
+	}, {
+		name:          "target without separating slash",
+		path:          "/foo(/|$)(.*)",
+		target:        "/bar$2",
+		expectedError: true,
+	}, {
+		name:          "target with double slash",
+		path:          "/foo(/.*)",
+		target:        "/bar/$1",
+		expectedError: true,
+	}, {
+		name:          "target reordering capture groups",
+		path:          "/foo/(.*)/(.*)",
+		target:        "/$2/$1",
+		expectedError: true,
+	}, {
+		name:          "regular expression prefix",
+		path:          "/v[0-9]+(/|$)(.*)",
+		target:        "/$2",
+		expectedError: true,
+	}, {
+		name:          "capture groups without groups in path",
+		path:          "/foo",
+		target:        "/$1",
+		expectedError: true,
+	}, {
+		name:          "query string",
+		path:          "/foo",
+		target:        "/bar?baz=1",
+		expectedError: true,
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := ToRewritePath(tc.path, tc.target)
+			if tc.expectedError {
+				if err == nil {
+					t.Errorf("Expected an error, got %+v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if (got.Difference != "") != tc.expectedDifference {
+				t.Errorf("Expected difference %v, got %q", tc.expectedDifference, got.Difference)
+			}
+			got.Difference = ""
+			if diff := cmp.Diff(tc.expected, got); diff != "" {
+				t.Errorf("Unexpected rewrite path, diff (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -18,6 +18,7 @@ package common
 
 import (
 	"fmt"
+	"net/url"
 	"regexp"
 
 	networkingv1 "k8s.io/api/networking/v1"
@@ -101,6 +102,40 @@ func NameFromHost(host string) string {
 	return step2
 }
 
+// ParseHTTPURL parses a URL, which must be an http or https URL with a host.
+func ParseHTTPURL(rawURL string) (*url.URL, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("only http and https are valid protocols (%v)", u.Scheme)
+	}
+	if u.Hostname() == "" {
+		return nil, fmt.Errorf("the URL has no host")
+	}
+	return u, nil
+}
+
+// ListenerHostname returns the hostname of the listeners generated for the
+// rules of host, which falls back to the host of the TLS entry when there is
+// exactly one.
+func ListenerHostname(host string, tls []networkingv1.IngressTLS) string {
+	if host == "" && len(tls) == 1 && len(tls[0].Hosts) == 1 {
+		return tls[0].Hosts[0]
+	}
+	return host
+}
+
+// ListenerName returns the name of the listener generated for the hostname
+// and protocol, such as http or https.
+func ListenerName(hostname, protocol string) gatewayv1.SectionName {
+	if hostname == "" {
+		return gatewayv1.SectionName(protocol)
+	}
+	return gatewayv1.SectionName(fmt.Sprintf("%s-%s", NameFromHost(hostname), protocol))
+}
+
 func RouteName(ingressName, host string) string {
 	return fmt.Sprintf("%s-%s", ingressName, NameFromHost(host))
 }
@@ -530,3 +530,41 @@ func TestGroupIngressPathsByMatchKey(t *testing.T) {
 		})
 	}
 }
+
+func TestParseHTTPURL(t *testing.T) {
+	testCases := []struct {
+		name          string
+		rawURL        string
+		expectedError string
+	}{{
+		name:   "https URL",
+		rawURL: "https://auth.example.com:8443/verify",
+	}, {
+		name:          "other protocol",
+		rawURL:        "ftp://www.example.com",
+		expectedError: "only http and https are valid protocols (ftp)",
+	}, {
+		name:          "protocol starting with http",
+		rawURL:        "httpx://www.example.com",
+		expectedError: "only http and https are valid protocols (httpx)",
+	}, {
+		name:          "relative URL",
+		rawURL:        "/relative",
+		expectedError: "only http and https are valid protocols ()",
+	}, {
+		name:          "no host",
+		rawURL:        "http:///path",
+		expectedError: "the URL has no host",
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := ParseHTTPURL(tc.rawURL)
+			if tc.expectedError == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.EqualError(t, err, tc.expectedError)
+		})
+	}
+}
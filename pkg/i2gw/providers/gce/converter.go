@@ -33,13 +33,17 @@ type converter struct {
 
 // newConverter returns an ingress-gce converter instance.
 func newConverter(conf *i2gw.ProviderConf) converter {
-	return converter{
+	c := converter{
 		conf:           conf,
 		featureParsers: []i2gw.FeatureParser{},
 		implementationSpecificOptions: i2gw.ProviderImplementationSpecificOptions{
 			ToImplementationSpecificHTTPPathTypeMatch: implementationSpecificHTTPPathTypeMatch,
 		},
 	}
+	if conf.PreserveIngressAddresses {
+		c.featureParsers = append(c.featureParsers, common.IngressStatusAddressesFeature(ProviderName))
+	}
+	return c
 }
 
 func (c *converter) convert(storage *storage) (i2gw.GatewayResources, field.ErrorList) {
@@ -67,5 +71,9 @@ func (c *converter) convert(storage *storage) (i2gw.GatewayResources, field.Erro
 		errs = append(errs, parseErrs...)
 	}
 
+	if storage.ControllerService != nil {
+		errs = append(errs, common.ControllerServiceFeature(ProviderName, storage.ControllerService)(ingressList, &gatewayResources)...)
+	}
+
 	return gatewayResources, errs
 }
@@ -21,6 +21,7 @@ import (
 	"fmt"
 
 	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/providers/common"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 )
 
@@ -28,6 +29,11 @@ const ProviderName = "gce"
 
 func init() {
 	i2gw.ProviderConstructorByName[ProviderName] = NewProvider
+
+	i2gw.RegisterProviderSpecificFlag(ProviderName, i2gw.ProviderSpecificFlag{
+		Name:        common.ControllerServiceFlag,
+		Description: common.ControllerServiceFlagDescription,
+	})
 }
 
 // Provider implements the i2gw.Provider interface.
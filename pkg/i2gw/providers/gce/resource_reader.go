@@ -45,21 +45,31 @@ func newResourceReader(conf *i2gw.ProviderConf) reader {
 func (r *reader) readResourcesFromCluster(ctx context.Context) (*storage, error) {
 	storage := newResourcesStorage()
 
-	ingresses, err := common.ReadIngressesFromCluster(ctx, r.conf.Client, supportedGCEIngressClass)
+	ingresses, err := common.ReadIngressesFromCluster(ctx, r.conf.Cache, supportedGCEIngressClass)
 	if err != nil {
 		return nil, err
 	}
 	storage.Ingresses = (ingresses)
+
+	storage.ControllerService, err = common.ReadControllerServiceFromCluster(ctx, r.conf, ProviderName)
+	if err != nil {
+		return nil, err
+	}
 	return storage, nil
 }
 
 func (r *reader) readResourcesFromFile(filename string) (*storage, error) {
 	storage := newResourcesStorage()
 
-	ingresses, err := common.ReadIngressesFromFile(filename, r.conf.Namespace, supportedGCEIngressClass)
+	ingresses, err := common.ReadIngressesFromFile(filename, r.conf, supportedGCEIngressClass)
 	if err != nil {
 		return nil, err
 	}
 	storage.Ingresses = ingresses
+
+	storage.ControllerService, err = common.ReadControllerServiceFromFile(filename, r.conf, ProviderName)
+	if err != nil {
+		return nil, err
+	}
 	return storage, nil
 }
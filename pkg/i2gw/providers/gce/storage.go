@@ -17,12 +17,16 @@ limitations under the License.
 package gce
 
 import (
+	corev1 "k8s.io/api/core/v1"
 	networkingv1 "k8s.io/api/networking/v1"
 	"k8s.io/apimachinery/pkg/types"
 )
 
 type storage struct {
 	Ingresses map[types.NamespacedName]*networkingv1.Ingress
+	// ControllerService is the Service of the GCE ingress controller, nil
+	// unless given with the controller-service flag.
+	ControllerService *corev1.Service
 }
 
 func newResourcesStorage() *storage {
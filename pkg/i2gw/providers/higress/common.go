@@ -2,9 +2,8 @@ package higress
 
 import (
 	"fmt"
-	"net/url"
-	"strings"
 
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/providers/common"
 	networkingv1 "k8s.io/api/networking/v1"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	"k8s.io/utils/ptr"
@@ -258,29 +257,14 @@ func createHTTPRouteMatch(path *ingressPath) gatewayv1.HTTPRouteMatch {
 	}
 }
 
-func isValidURL(s string) error {
-	u, err := url.Parse(s)
-	if err != nil {
-		return err
-	}
-
-	if !strings.HasPrefix(u.Scheme, "http") {
-		return fmt.Errorf("only http and https are valid protocols (%v)", u.Scheme)
-	}
-
-	return nil
+func isHTTPURL(s string) bool {
+	_, err := common.ParseHTTPURL(s)
+	return err == nil
 }
 
 func isPathValid(path string) *field.Error {
-	if err := groupCaptureUsed(path); err != nil {
+	if err := common.GroupCaptureUsed(path); err != nil {
 		return err
 	}
 	return nil
 }
-
-func groupCaptureUsed(path string) *field.Error {
-	if strings.Contains(path, "(") && strings.Contains(path, ")") {
-		return field.Invalid(field.NewPath("metadata", "annotations"), path, "group capture not supported")
-	}
-	return nil
-}
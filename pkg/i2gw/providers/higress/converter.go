@@ -31,14 +31,17 @@ const (
 
 // converter implements the ToGatewayAPI function of i2gw.ResourceConverter interface.
 type converter struct {
+	conf *i2gw.ProviderConf
+
 	featureParsers []i2gw.FeatureParser
 	FeatureHandler []FeatureHandler
 }
 
 // newConverter returns an higress converter instance.
 // Note: The order in which the Paser/Handler is executed may result in different outputs, change it with caution.
-func newConverter() *converter {
+func newConverter(conf *i2gw.ProviderConf) *converter {
 	return &converter{
+		conf: conf,
 		featureParsers: []i2gw.FeatureParser{
 			canaryFeature,
 			headerModFeature,
@@ -113,5 +116,12 @@ func (c *converter) convert(storage *storage) (i2gw.GatewayResources, field.Erro
 		}
 	}
 
+	if c.conf.PreserveIngressAddresses {
+		errs = append(errs, common.IngressStatusAddressesFeature(Name)(ingressList, &gatewayResources)...)
+	}
+	if storage.ControllerService != nil {
+		errs = append(errs, common.ControllerServiceFeature(Name, storage.ControllerService)(ingressList, &gatewayResources)...)
+	}
+
 	return gatewayResources, errs
 }
@@ -3,6 +3,7 @@ package higress
 import (
 	"fmt"
 	"regexp"
+	"sort"
 	"strings"
 
 	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw"
@@ -67,81 +68,60 @@ func applyHTTPRouteWithHeaderMod(httpRoute *gatewayv1.HTTPRoute, paths []ingress
 	}
 
 	for i, path := range headerPath {
-		var gwHTTPRouteFilters []gatewayv1.HTTPRouteFilter
-		var headerFilter gatewayv1.HTTPHeaderFilter
 		headerMod := path.extra.headerMod
-
-		if headerMod.add != nil {
-			var addHeaders []gatewayv1.HTTPHeader
-			for key, value := range headerMod.add {
-				addHeaders = append(addHeaders, gatewayv1.HTTPHeader{
-					Name:  gatewayv1.HTTPHeaderName(key),
-					Value: value,
-				})
-			}
-			headerFilter.Add = addHeaders
-		}
-
-		if headerMod.update != nil {
-			var updateHeaders []gatewayv1.HTTPHeader
-			for key, value := range headerMod.update {
-				updateHeaders = append(updateHeaders, gatewayv1.HTTPHeader{
-					Name:  gatewayv1.HTTPHeaderName(key),
-					Value: value,
-				})
-			}
-
-			headerFilter.Set = updateHeaders
-		}
-
-		if headerMod.remove != nil {
-			var removeHeaders []string
-
-			removeHeaders = append(removeHeaders, headerMod.remove...)
-
-			headerFilter.Remove = removeHeaders
-		}
-
-		gwHTTPRouteFilters = append(gwHTTPRouteFilters, gatewayv1.HTTPRouteFilter{
-			Type:                  gatewayv1.HTTPRouteFilterRequestHeaderModifier,
-			RequestHeaderModifier: &headerFilter,
-		})
 		backendRef, err := common.ToBackendRef(path.path.Backend, field.NewPath("paths", "backends").Index(i))
 		if err != nil {
 			errors = append(errors, err)
 			continue
 		}
-		errs := applyByHeaderMod(httpRoute, &path, backendRef, gwHTTPRouteFilters)
-		if errs != nil {
-			errors = append(errors, errs)
+
+		// A rule can only have one RequestHeaderModifier filter, which the
+		// headers are merged into.
+		rule := ruleByHeaderMod(httpRoute, &path, backendRef)
+		common.SetRuleHeaders(rule, gatewayv1.HTTPRouteFilterRequestHeaderModifier, toHTTPHeaders(headerMod.update))
+		if headerMod.add != nil || headerMod.remove != nil {
+			headerFilter := common.RuleHeaderFilter(rule, gatewayv1.HTTPRouteFilterRequestHeaderModifier)
+			headerFilter.Add = append(headerFilter.Add, toHTTPHeaders(headerMod.add)...)
+			headerFilter.Remove = append(headerFilter.Remove, headerMod.remove...)
 		}
 	}
 
 	return errors
 }
 
-func applyByHeaderMod(httpRoute *gatewayv1.HTTPRoute, path *ingressPath, backendRef *gatewayv1.BackendRef, gwHTTPRouteFilters []gatewayv1.HTTPRouteFilter) *field.Error {
-	// fmt.Println("bakendRef Name is: ", backendRef.Name)
+// ruleByHeaderMod returns the rule of the path, which is split into a rule of
+// its own if it shares the rule of other backends.
+func ruleByHeaderMod(httpRoute *gatewayv1.HTTPRoute, path *ingressPath, backendRef *gatewayv1.BackendRef) *gatewayv1.HTTPRouteRule {
 	if rule := singleBackendRuleExists(httpRoute, path); rule != nil {
-		rule.Filters = append(rule.Filters, gwHTTPRouteFilters...)
-		return nil
-	} else {
-		// fmt.Println("New Rule")
-		match := gatewayv1.HTTPRouteMatch{
-			Path: &gatewayv1.HTTPPathMatch{
-				Type:  ConvertPathType(path.path.PathType),
-				Value: ptr.To(path.path.Path),
-			},
-		}
-		deleteBackendNew(httpRoute, path)
-		httpRoute.Spec.Rules = append(httpRoute.Spec.Rules, *createHTTPRouteRule(createHTTPRouteRuleParam{
-			filters:     gwHTTPRouteFilters,
-			backendRefs: []gatewayv1.HTTPBackendRef{{BackendRef: *backendRef}},
-			matchs:      []gatewayv1.HTTPRouteMatch{match},
-		}))
+		return rule
+	}
+	match := gatewayv1.HTTPRouteMatch{
+		Path: &gatewayv1.HTTPPathMatch{
+			Type:  ConvertPathType(path.path.PathType),
+			Value: ptr.To(path.path.Path),
+		},
 	}
+	deleteBackendNew(httpRoute, path)
+	httpRoute.Spec.Rules = append(httpRoute.Spec.Rules, *createHTTPRouteRule(createHTTPRouteRuleParam{
+		backendRefs: []gatewayv1.HTTPBackendRef{{BackendRef: *backendRef}},
+		matchs:      []gatewayv1.HTTPRouteMatch{match},
+	}))
+	return &httpRoute.Spec.Rules[len(httpRoute.Spec.Rules)-1]
+}
 
-	return nil
+// toHTTPHeaders returns the headers, sorted by name.
+func toHTTPHeaders(headers map[string]string) []gatewayv1.HTTPHeader {
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var httpHeaders []gatewayv1.HTTPHeader
+	for _, name := range names {
+		httpHeaders = append(httpHeaders, gatewayv1.HTTPHeader{Name: gatewayv1.HTTPHeaderName(name), Value: headers[name]})
+	}
+	return httpHeaders
 }
 
 func (h *headerModConfig) Parse(ingress *networkingv1.Ingress) field.ErrorList {
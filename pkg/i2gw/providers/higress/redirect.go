@@ -284,7 +284,7 @@ func (r *redirectConfig) Parse(ingress *networkingv1.Ingress) field.ErrorList {
 		r.sslRedirect = true
 	}
 
-	if permanentRedirect := findAnnotationValue(ingress.Annotations, PermanentRedirect); permanentRedirect != "" && isValidURL(permanentRedirect) == nil {
+	if permanentRedirect := findAnnotationValue(ingress.Annotations, PermanentRedirect); permanentRedirect != "" && isHTTPURL(permanentRedirect) {
 		r.redirectURL = permanentRedirect
 		r.redirectCode = DefaultPermanentCode
 	}
@@ -296,7 +296,7 @@ func (r *redirectConfig) Parse(ingress *networkingv1.Ingress) field.ErrorList {
 		}
 	}
 
-	if temporalRedirect := findAnnotationValue(ingress.Annotations, TemporalRedirect); temporalRedirect != "" && isValidURL(temporalRedirect) == nil {
+	if temporalRedirect := findAnnotationValue(ingress.Annotations, TemporalRedirect); temporalRedirect != "" && isHTTPURL(temporalRedirect) {
 		r.redirectURL = temporalRedirect
 		r.redirectCode = DefaultTemporalCode
 	}
@@ -27,11 +27,16 @@ func newResourceReader(conf *i2gw.ProviderConf) *resourceReader {
 func (r *resourceReader) readResourcesFromCluster(ctx context.Context) (*storage, error) {
 	storage := newResourcesStorage()
 
-	ingresses, err := common.ReadIngressesFromCluster(ctx, r.conf.Client, sets.New(HigressClass))
+	ingresses, err := common.ReadIngressesFromCluster(ctx, r.conf.Cache, sets.New(HigressClass))
 	if err != nil {
 		return nil, err
 	}
 	storage.Ingresses.FromMap(ingresses)
+
+	storage.ControllerService, err = common.ReadControllerServiceFromCluster(ctx, r.conf, Name)
+	if err != nil {
+		return nil, err
+	}
 	return storage, nil
 }
 
@@ -40,10 +45,15 @@ func (r *resourceReader) readResourcesFromCluster(ctx context.Context) (*storage
 func (r *resourceReader) readResourcesFromFile(filename string) (*storage, error) {
 	storage := newResourcesStorage()
 
-	ingresses, err := common.ReadIngressesFromFile(filename, r.conf.Namespace, sets.New(HigressClass))
+	ingresses, err := common.ReadIngressesFromFile(filename, r.conf, sets.New(HigressClass))
 	if err != nil {
 		return nil, err
 	}
 	storage.Ingresses.FromMap(ingresses)
+
+	storage.ControllerService, err = common.ReadControllerServiceFromFile(filename, r.conf, Name)
+	if err != nil {
+		return nil, err
+	}
 	return storage, nil
 }
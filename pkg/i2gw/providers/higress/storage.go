@@ -3,6 +3,7 @@ package higress
 import (
 	"sort"
 
+	corev1 "k8s.io/api/core/v1"
 	networkingv1 "k8s.io/api/networking/v1"
 	"k8s.io/apimachinery/pkg/types"
 )
@@ -13,6 +14,9 @@ type OrderedIngressMap struct {
 }
 type storage struct {
 	Ingresses OrderedIngressMap
+	// ControllerService is the Service of the Higress gateway, nil unless
+	// given with the controller-service flag.
+	ControllerService *corev1.Service
 }
 
 func newResourcesStorage() *storage {
@@ -0,0 +1,401 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingressnginx
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/providers/common"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+const (
+	authURLAnnotation             = "nginx.ingress.kubernetes.io/auth-url"
+	authSigninAnnotation          = "nginx.ingress.kubernetes.io/auth-signin"
+	authResponseHeadersAnnotation = "nginx.ingress.kubernetes.io/auth-response-headers"
+	authMethodAnnotation          = "nginx.ingress.kubernetes.io/auth-method"
+	authTypeAnnotation            = "nginx.ingress.kubernetes.io/auth-type"
+	authSecretAnnotation          = "nginx.ingress.kubernetes.io/auth-secret"
+	authSecretTypeAnnotation      = "nginx.ingress.kubernetes.io/auth-secret-type"
+	authRealmAnnotation           = "nginx.ingress.kubernetes.io/auth-realm"
+
+	basicAuthType  = "basic"
+	digestAuthType = "digest"
+	// authFileSecretType is the default auth-secret-type, an htpasswd file in
+	// the auth key of the Secret.
+	authFileSecretType = "auth-file"
+	authMapSecretType  = "auth-map"
+
+	// AllowUnprotectedRoutesFlag is the provider-specific flag acknowledging
+	// that the paths protected by authentication or IP access annotations
+	// which cannot be converted are converted without access control.
+	AllowUnprotectedRoutesFlag = "allow-unprotected-routes"
+)
+
+// auth converts the authentication and IP access annotations to the
+// SecurityPolicy of the HTTPRoutes with the envoy-gateway target
+// implementation.
+//
+// The paths of an Ingress whose access control cannot be converted, including
+// the high risk snippet directives of parseSnippetAccess and
+// serverSnippetAccess, would be open to anyone once converted, so their rules
+// are left out of the HTTPRoutes, unless AllowUnprotectedRoutesFlag
+// acknowledges it. The paths with a converted authentication are reported as
+// auth-sensitive.
+//
+// Like ingress-nginx, the access control only applies to the paths of each
+// Ingress, so it is recorded per rule in routePolicies. As ingress-nginx
+// answers the redirects before authenticating the requests, this must run
+// after redirectFeature, whose rules are left as is, and before the rules are
+// changed by rewriteFeature and regexFeature.
+type auth struct {
+	target           string
+	allowUnprotected string
+	policies         *routePolicies
+}
+
+// ingressAuth is an authentication of the requests to the paths of an
+// Ingress.
+type ingressAuth struct {
+	// annotation is the annotation setting the authentication.
+	annotation string
+	// policyField is the field of the SecurityPolicy set to policy, which is
+	// nil when the authentication cannot be converted, for the reason given
+	// by unconverted.
+	policyField string
+	policy      map[string]interface{}
+	unconverted string
+	// restriction is true for the IP access restrictions, which authorize
+	// the clients rather than authenticate the requests.
+	restriction bool
+	// reference is the object of kind referenceKind referenced by the policy.
+	referenceKind gatewayv1.Kind
+	reference     types.NamespacedName
+	// converted reports the conversion of the authentication, once its policy
+	// is recorded for a rule left in the HTTPRoutes.
+	converted notifications.Notification
+}
+
+func newAuth(conf *i2gw.ProviderConf, target string, policies *routePolicies) *auth {
+	return &auth{
+		target:           target,
+		allowUnprotected: conf.ProviderSpecificFlags[Name][AllowUnprotectedRoutesFlag],
+		policies:         policies,
+	}
+}
+
+func (a *auth) feature(ingresses []networkingv1.Ingress, gatewayResources *i2gw.GatewayResources) field.ErrorList {
+	allowUnprotected := false
+	if a.allowUnprotected != "" {
+		var err error
+		allowUnprotected, err = strconv.ParseBool(a.allowUnprotected)
+		if err != nil {
+			return field.ErrorList{field.Invalid(field.NewPath("ProviderSpecificFlags", Name, AllowUnprotectedRoutesFlag), a.allowUnprotected, "must be true or false")}
+		}
+	}
+
+	var errs field.ErrorList
+	authByIngress := map[types.NamespacedName][]*ingressAuth{}
+	for i := range ingresses {
+		ingressKey := types.NamespacedName{Namespace: ingresses[i].Namespace, Name: ingresses[i].Name}
+		for _, parse := range []func(*networkingv1.Ingress) (*ingressAuth, field.ErrorList){a.parseExtAuth, a.parseBasicAuth, a.parseIPAccess, parseSnippetAccess} {
+			ingressAuth, parseErrs := parse(&ingresses[i])
+			errs = append(errs, parseErrs...)
+			if ingressAuth != nil {
+				authByIngress[ingressKey] = append(authByIngress[ingressKey], ingressAuth)
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+
+	serverAccess := serverSnippetAccess(ingresses)
+	unprotectedRules := map[types.NamespacedName]map[int]bool{}
+	unprotectedSources := map[types.NamespacedName][]unprotectedSource{}
+	var unprotectedKeys []types.NamespacedName
+	converted := map[types.NamespacedName]bool{}
+	var recordedAuths []*ingressAuth
+	recorded := map[*ingressAuth]bool{}
+	forEachRuleSource(ingresses, gatewayResources, true, func(key types.NamespacedName, ruleIdx int, source ruleSource) {
+		ingressKey := types.NamespacedName{Namespace: source.ingress.Namespace, Name: source.ingress.Name}
+		auths := authByIngress[ingressKey]
+		if access, ok := serverAccess[key]; ok {
+			auths = append(auths[:len(auths):len(auths)], access)
+		}
+		var annotations []string
+		var ruleAuths []*ingressAuth
+		for _, ingressAuth := range auths {
+			if ingressAuth.policy == nil {
+				if unprotectedRules[key] == nil {
+					unprotectedRules[key] = map[int]bool{}
+					unprotectedKeys = append(unprotectedKeys, key)
+				}
+				if !unprotectedRules[key][ruleIdx] {
+					unprotectedRules[key][ruleIdx] = true
+					unprotectedSources[key] = append(unprotectedSources[key], unprotectedSource{source: source, access: ingressAuth})
+				}
+				continue
+			}
+			a.policies.set(key, ruleIdx, source, envoyGatewaySecurityPolicyGVK, ingressAuth.policyField, ingressAuth.policy)
+			ruleAuths = append(ruleAuths, ingressAuth)
+			if ingressAuth.reference.Namespace != "" && ingressAuth.reference.Namespace != key.Namespace {
+				addReferenceGrant(gatewayResources, envoyGatewaySecurityPolicyGVK, key.Namespace, ingressAuth.referenceKind, ingressAuth.reference)
+			}
+			if !ingressAuth.restriction {
+				annotations = append(annotations, ingressAuth.annotation)
+			}
+		}
+		if allowUnprotected || !unprotectedRules[key][ruleIdx] {
+			for _, ingressAuth := range ruleAuths {
+				if !recorded[ingressAuth] {
+					recorded[ingressAuth] = true
+					recordedAuths = append(recordedAuths, ingressAuth)
+				}
+			}
+		}
+		if len(annotations) > 0 && !unprotectedRules[key][ruleIdx] && !converted[ingressKey] {
+			converted[ingressKey] = true
+			notifications.NotificationAggr.DispatchNotification(notifications.NewNotification(
+				notifications.WarningNotification,
+				fmt.Sprintf("the paths of %s/%s are auth-sensitive: their requests are authenticated by the SecurityPolicy of the HTTPRoutes of their rules, converted from the %s, which must be enforced by the Gateway implementation for them not to be open to anyone",
+					ingressKey.Namespace, ingressKey.Name, strings.Join(annotations, " and ")),
+				&source.ingress,
+			), Name)
+		}
+	})
+	for _, ingressAuth := range recordedAuths {
+		notifications.NotificationAggr.DispatchNotification(ingressAuth.converted, Name)
+	}
+
+	for _, key := range unprotectedKeys {
+		notifyUnprotectedRules(key, unprotectedSources[key], allowUnprotected)
+		if allowUnprotected {
+			continue
+		}
+		a.policies.removeRules(key, unprotectedRules[key])
+		httpRoute := gatewayResources.HTTPRoutes[key]
+		var rules []gatewayv1.HTTPRouteRule
+		for i, rule := range httpRoute.Spec.Rules {
+			if !unprotectedRules[key][i] {
+				rules = append(rules, rule)
+			}
+		}
+		if len(rules) == 0 {
+			delete(gatewayResources.HTTPRoutes, key)
+			continue
+		}
+		httpRoute.Spec.Rules = rules
+		gatewayResources.HTTPRoutes[key] = httpRoute
+	}
+
+	return nil
+}
+
+// unprotectedSource is the source of a rule whose access control cannot be
+// converted.
+type unprotectedSource struct {
+	source ruleSource
+	access *ingressAuth
+}
+
+// parseExtAuth returns the external authentication of the auth-url
+// annotation of the Ingress, nil when it sets none. Like mirror-target, the
+// host of the URL must be the in-cluster DNS name of a Service, which the
+// SecurityPolicy references.
+func (a *auth) parseExtAuth(ingress *networkingv1.Ingress) (*ingressAuth, field.ErrorList) {
+	authURL := ingress.Annotations[authURLAnnotation]
+	if authURL == "" {
+		return nil, nil
+	}
+	extAuth := &ingressAuth{annotation: authURLAnnotation, policyField: "extAuth"}
+	if a.target == "" {
+		extAuth.unconverted = fmt.Sprintf("no target implementation is selected with --%s-%s", Name, TargetImplementationFlag)
+		return extAuth, nil
+	}
+	if strings.Contains(authURL, "$") {
+		extAuth.unconverted = "its URL uses nginx variables"
+		return extAuth, nil
+	}
+
+	parsedURL, err := common.ParseHTTPURL(authURL)
+	if err != nil {
+		fieldPath := field.NewPath(ingress.Name).Child("metadata").Child("annotations")
+		return nil, field.ErrorList{field.Invalid(fieldPath.Key(authURLAnnotation), authURL, err.Error())}
+	}
+	service, ok := serviceFromHost(parsedURL.Hostname(), ingress.Namespace)
+	if !ok {
+		extAuth.unconverted = fmt.Sprintf("%q is an external host, while the SecurityPolicy can only reference Services", parsedURL.Host)
+		return extAuth, nil
+	}
+	port := int64(80)
+	if parsedURL.Scheme == "https" {
+		port = 443
+	}
+	if parsedURL.Port() != "" {
+		port, _ = strconv.ParseInt(parsedURL.Port(), 10, 32)
+	}
+
+	backendRef := map[string]interface{}{
+		"name": service.Name,
+		"port": port,
+	}
+	if service.Namespace != ingress.Namespace {
+		backendRef["namespace"] = service.Namespace
+	}
+	http := map[string]interface{}{"backendRef": backendRef}
+	if parsedURL.Path != "" {
+		http["path"] = parsedURL.Path
+	}
+	if responseHeaders := splitList(ingress.Annotations[authResponseHeadersAnnotation]); len(responseHeaders) > 0 {
+		http["headersToBackend"] = toInterfaceSlice(responseHeaders)
+	}
+	extAuth.policy = map[string]interface{}{"http": http}
+	extAuth.referenceKind, extAuth.reference = "Service", service
+
+	var lost []string
+	if parsedURL.RawQuery != "" {
+		lost = append(lost, fmt.Sprintf("the query %q of the URL is dropped", parsedURL.RawQuery))
+	}
+	if parsedURL.Scheme == "https" {
+		lost = append(lost, "the requests to the authentication Service are sent over TLS only if a BackendTLSPolicy is added for it")
+	}
+	if signin := ingress.Annotations[authSigninAnnotation]; signin != "" {
+		lost = append(lost, fmt.Sprintf("the unauthenticated requests are denied instead of being redirected to %s %q", authSigninAnnotation, signin))
+	}
+	if method := ingress.Annotations[authMethodAnnotation]; method != "" {
+		lost = append(lost, fmt.Sprintf("the authentication requests keep the method of the request instead of %s %q", authMethodAnnotation, method))
+	}
+	message := fmt.Sprintf("%s %q is converted to the extAuth of the SecurityPolicy of the HTTPRoutes of the Ingress, with Service %s port %d", authURLAnnotation, authURL, service, port)
+	notificationType := notifications.InfoNotification
+	if len(lost) > 0 {
+		message += ", but " + strings.Join(lost, ", and ")
+		notificationType = notifications.WarningNotification
+	}
+	extAuth.converted = notifications.NewNotification(notificationType, message, ingress)
+
+	return extAuth, nil
+}
+
+// parseBasicAuth returns the basic authentication of the auth-type annotation
+// of the Ingress, nil when it sets none. The SecurityPolicy references the
+// htpasswd Secret of auth-secret, which is not read.
+func (a *auth) parseBasicAuth(ingress *networkingv1.Ingress) (*ingressAuth, field.ErrorList) {
+	authType, ok := ingress.Annotations[authTypeAnnotation]
+	if !ok {
+		return nil, nil
+	}
+	fieldPath := field.NewPath(ingress.Name).Child("metadata").Child("annotations")
+	if authType != basicAuthType && authType != digestAuthType {
+		return nil, field.ErrorList{field.NotSupported(fieldPath.Key(authTypeAnnotation), authType, []string{basicAuthType, digestAuthType})}
+	}
+	value := ingress.Annotations[authSecretAnnotation]
+	if value == "" {
+		return nil, field.ErrorList{field.Required(fieldPath.Key(authSecretAnnotation), fmt.Sprintf("required by %s %s", authTypeAnnotation, authType))}
+	}
+	secret, err := objectReference(ingress.Namespace, value)
+	if err != nil {
+		return nil, field.ErrorList{field.Invalid(fieldPath.Key(authSecretAnnotation), value, err.Error())}
+	}
+	secretType := ingress.Annotations[authSecretTypeAnnotation]
+	if secretType == "" {
+		secretType = authFileSecretType
+	}
+	if secretType != authFileSecretType && secretType != authMapSecretType {
+		return nil, field.ErrorList{field.NotSupported(fieldPath.Key(authSecretTypeAnnotation), secretType, []string{authFileSecretType, authMapSecretType})}
+	}
+
+	basicAuth := &ingressAuth{annotation: authTypeAnnotation, policyField: "basicAuth"}
+	switch {
+	case a.target == "":
+		basicAuth.unconverted = fmt.Sprintf("no target implementation is selected with --%s-%s", Name, TargetImplementationFlag)
+		return basicAuth, nil
+	case authType == digestAuthType:
+		basicAuth.unconverted = "digest authentication has no equivalent"
+		return basicAuth, nil
+	}
+
+	users := map[string]interface{}{"name": secret.Name}
+	if secret.Namespace != ingress.Namespace {
+		users["namespace"] = secret.Namespace
+	}
+	basicAuth.policy = map[string]interface{}{"users": users}
+	basicAuth.referenceKind, basicAuth.reference = "Secret", secret
+
+	secretFormat := "its htpasswd file, in the auth key, must be moved to a .htpasswd key"
+	if secretType == authMapSecretType {
+		secretFormat = "its keys mapping users to password hashes must be merged into an htpasswd file in a .htpasswd key"
+	}
+	message := fmt.Sprintf("%s %s is converted to the basicAuth of the SecurityPolicy of the HTTPRoutes of the Ingress, with the users of Secret %s: %s, and only SHA password hashes are supported",
+		authTypeAnnotation, authType, secret, secretFormat)
+	if realm := ingress.Annotations[authRealmAnnotation]; realm != "" {
+		message += fmt.Sprintf(", while %s %q cannot be set", authRealmAnnotation, realm)
+	}
+	basicAuth.converted = notifications.NewNotification(notifications.WarningNotification, message, ingress)
+
+	return basicAuth, nil
+}
+
+// notifyUnprotectedRules reports the paths whose access control cannot be
+// converted, left out of the HTTPRoute unless allowed.
+func notifyUnprotectedRules(routeKey types.NamespacedName, unprotected []unprotectedSource, allowUnprotected bool) {
+	sourcesByIngress := map[types.NamespacedName][]unprotectedSource{}
+	for _, rule := range unprotected {
+		ingressKey := types.NamespacedName{Namespace: rule.source.ingress.Namespace, Name: rule.source.ingress.Name}
+		sourcesByIngress[ingressKey] = append(sourcesByIngress[ingressKey], rule)
+	}
+	ingressKeys := make([]types.NamespacedName, 0, len(sourcesByIngress))
+	for ingressKey := range sourcesByIngress {
+		ingressKeys = append(ingressKeys, ingressKey)
+	}
+	sort.Slice(ingressKeys, func(i, j int) bool { return ingressKeys[i].String() < ingressKeys[j].String() })
+
+	for _, ingressKey := range ingressKeys {
+		ingressAuth := sourcesByIngress[ingressKey][0].access
+		var paths []string
+		for _, rule := range sourcesByIngress[ingressKey] {
+			paths = append(paths, fmt.Sprintf("%q", rule.source.path.Path))
+		}
+		ingress := sourcesByIngress[ingressKey][0].source.ingress
+		var callingObject client.Object = &ingress
+
+		if allowUnprotected {
+			notifications.NotificationAggr.DispatchNotification(notifications.NewNotification(
+				notifications.WarningNotification,
+				fmt.Sprintf("the paths %s of HTTPRoute %s are converted without the access control of %s, as %s, and --%s-%s acknowledges it: they are open to anyone until it is configured with the Gateway implementation",
+					strings.Join(paths, ", "), routeKey, ingressAuth.annotation, ingressAuth.unconverted, Name, AllowUnprotectedRoutesFlag),
+				callingObject,
+			), Name)
+			continue
+		}
+		notifications.NotificationAggr.DispatchNotification(notifications.NewNotification(
+			notifications.ErrorNotification,
+			fmt.Sprintf("the paths %s are left out of HTTPRoute %s, as the access control of %s cannot be converted, as %s: configure it with the Gateway implementation, or set --%s-%s to convert them without access control",
+				strings.Join(paths, ", "), routeKey, ingressAuth.annotation, ingressAuth.unconverted, Name, AllowUnprotectedRoutesFlag),
+			callingObject,
+		), Name)
+	}
+}
@@ -0,0 +1,241 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingressnginx
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func Test_authFeature(t *testing.T) {
+	routeKey := types.NamespacedName{Namespace: "default", Name: "a-example-com"}
+
+	testCases := []struct {
+		name                    string
+		flags                   map[string]string
+		ingresses               []networkingv1.Ingress
+		expectedPolicySpec      map[string]interface{}
+		expectedRulePaths       []string
+		expectedReferenceGrants []types.NamespacedName
+		expectedNotifications   []string
+	}{{
+		name:  "Service of the Ingress namespace",
+		flags: map[string]string{TargetImplementationFlag: envoyGatewayImplementation},
+		ingresses: []networkingv1.Ingress{
+			testIngress("a", "example.com", "/", networkingv1.PathTypePrefix, map[string]string{
+				authURLAnnotation:             "http://oauth2-proxy:4180/oauth2/auth",
+				authResponseHeadersAnnotation: "X-Auth-Request-User, X-Auth-Request-Email",
+			}),
+		},
+		expectedPolicySpec: map[string]interface{}{
+			"extAuth": map[string]interface{}{
+				"http": map[string]interface{}{
+					"backendRef":       map[string]interface{}{"name": "oauth2-proxy", "port": int64(4180)},
+					"path":             "/oauth2/auth",
+					"headersToBackend": []interface{}{"X-Auth-Request-User", "X-Auth-Request-Email"},
+				},
+			},
+		},
+		expectedRulePaths:     []string{"/"},
+		expectedNotifications: []string{"is converted to the extAuth of the SecurityPolicy of the HTTPRoutes of the Ingress, with Service default/oauth2-proxy port 4180"},
+	}, {
+		name:  "Service of another namespace, with a sign-in URL",
+		flags: map[string]string{TargetImplementationFlag: envoyGatewayImplementation},
+		ingresses: []networkingv1.Ingress{
+			testIngress("a", "example.com", "/", networkingv1.PathTypePrefix, map[string]string{
+				authURLAnnotation:    "http://oauth2-proxy.auth.svc.cluster.local/oauth2/auth",
+				authSigninAnnotation: "https://auth.example.com/oauth2/start?rd=$escaped_request_uri",
+			}),
+		},
+		expectedPolicySpec: map[string]interface{}{
+			"extAuth": map[string]interface{}{
+				"http": map[string]interface{}{
+					"backendRef": map[string]interface{}{"name": "oauth2-proxy", "namespace": "auth", "port": int64(80)},
+					"path":       "/oauth2/auth",
+				},
+			},
+		},
+		expectedRulePaths:       []string{"/"},
+		expectedReferenceGrants: []types.NamespacedName{{Namespace: "auth", Name: "from-default-to-service-oauth2-proxy"}},
+		expectedNotifications:   []string{"the unauthenticated requests are denied instead of being redirected"},
+	}, {
+		name: "no target implementation",
+		ingresses: []networkingv1.Ingress{
+			testIngress("a", "example.com", "/", networkingv1.PathTypePrefix, map[string]string{
+				authURLAnnotation: "http://oauth2-proxy/oauth2/auth",
+			}),
+			testIngress("b", "example.com", "/public", networkingv1.PathTypePrefix, nil),
+		},
+		expectedRulePaths:     []string{"/public"},
+		expectedNotifications: []string{`the paths "/" are left out of HTTPRoute default/a-example-com`},
+	}, {
+		name:  "external host, acknowledged",
+		flags: map[string]string{TargetImplementationFlag: envoyGatewayImplementation, AllowUnprotectedRoutesFlag: "true"},
+		ingresses: []networkingv1.Ingress{
+			testIngress("a", "example.com", "/", networkingv1.PathTypePrefix, map[string]string{
+				authURLAnnotation: "https://auth.example.com/verify",
+			}),
+		},
+		expectedRulePaths:     []string{"/"},
+		expectedNotifications: []string{`"auth.example.com" is an external host`, "are converted without the access control"},
+	}, {
+		name:  "basic authentication",
+		flags: map[string]string{TargetImplementationFlag: envoyGatewayImplementation},
+		ingresses: []networkingv1.Ingress{
+			testIngress("a", "example.com", "/", networkingv1.PathTypePrefix, map[string]string{
+				authTypeAnnotation:   "basic",
+				authSecretAnnotation: "dashboards/basic-auth",
+				authRealmAnnotation:  "Authentication Required",
+			}),
+		},
+		expectedPolicySpec: map[string]interface{}{
+			"basicAuth": map[string]interface{}{
+				"users": map[string]interface{}{"name": "basic-auth", "namespace": "dashboards"},
+			},
+		},
+		expectedRulePaths:       []string{"/"},
+		expectedReferenceGrants: []types.NamespacedName{{Namespace: "dashboards", Name: "from-default-to-secret-basic-auth"}},
+		expectedNotifications: []string{
+			"with the users of Secret dashboards/basic-auth: its htpasswd file, in the auth key, must be moved to a .htpasswd key",
+			`nginx.ingress.kubernetes.io/auth-realm "Authentication Required" cannot be set`,
+			"the paths of default/a are auth-sensitive",
+		},
+	}, {
+		name:  "basic and external authentication, shared with another Ingress",
+		flags: map[string]string{TargetImplementationFlag: envoyGatewayImplementation},
+		ingresses: []networkingv1.Ingress{
+			testIngress("a", "example.com", "/", networkingv1.PathTypePrefix, map[string]string{
+				authTypeAnnotation:   "basic",
+				authSecretAnnotation: "basic-auth",
+				authURLAnnotation:    "http://oauth2-proxy/oauth2/auth",
+			}),
+			testIngress("b", "example.com", "/public", networkingv1.PathTypePrefix, nil),
+		},
+		expectedPolicySpec: map[string]interface{}{
+			"extAuth": map[string]interface{}{
+				"http": map[string]interface{}{
+					"backendRef": map[string]interface{}{"name": "oauth2-proxy", "port": int64(80)},
+					"path":       "/oauth2/auth",
+				},
+			},
+			"basicAuth": map[string]interface{}{
+				"users": map[string]interface{}{"name": "basic-auth"},
+			},
+		},
+		expectedRulePaths: []string{"/public"},
+		expectedNotifications: []string{
+			"converted from the nginx.ingress.kubernetes.io/auth-url and nginx.ingress.kubernetes.io/auth-type",
+			"the rules of HTTPRoute default/a-example-com with the policies of default/a are moved to HTTPRoute default/a-example-com-a",
+		},
+	}, {
+		name:  "digest authentication",
+		flags: map[string]string{TargetImplementationFlag: envoyGatewayImplementation},
+		ingresses: []networkingv1.Ingress{
+			testIngress("a", "example.com", "/", networkingv1.PathTypePrefix, map[string]string{
+				authTypeAnnotation:   "digest",
+				authSecretAnnotation: "digest-auth",
+			}),
+			testIngress("b", "example.com", "/public", networkingv1.PathTypePrefix, nil),
+		},
+		expectedRulePaths:     []string{"/public"},
+		expectedNotifications: []string{"as digest authentication has no equivalent"},
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			conf := &i2gw.ProviderConf{ProviderSpecificFlags: map[string]map[string]string{Name: tc.flags}}
+			gatewayResources, notificationTable := convertTestIngresses(t, conf, tc.ingresses...)
+
+			var policySpec map[string]interface{}
+			for _, policy := range gatewayResources.ExtensionResources {
+				policySpec, _, _ = unstructured.NestedMap(policy.Object, "spec")
+				delete(policySpec, "targetRef")
+			}
+			if diff := cmp.Diff(tc.expectedPolicySpec, policySpec); diff != "" {
+				t.Errorf("Unexpected SecurityPolicy spec, diff (-want +got):\n%s", diff)
+			}
+			var rulePaths []string
+			for _, rule := range gatewayResources.HTTPRoutes[routeKey].Spec.Rules {
+				rulePaths = append(rulePaths, *rule.Matches[0].Path.Value)
+			}
+			if diff := cmp.Diff(tc.expectedRulePaths, rulePaths); diff != "" {
+				t.Errorf("Unexpected rules, diff (-want +got):\n%s", diff)
+			}
+			var referenceGrants []types.NamespacedName
+			for key := range gatewayResources.ReferenceGrants {
+				referenceGrants = append(referenceGrants, key)
+			}
+			if diff := cmp.Diff(tc.expectedReferenceGrants, referenceGrants); diff != "" {
+				t.Errorf("Unexpected ReferenceGrants, diff (-want +got):\n%s", diff)
+			}
+			for _, expected := range tc.expectedNotifications {
+				if !strings.Contains(notificationTable, expected) {
+					t.Errorf("Expected notification %q, got:\n%s", expected, notificationTable)
+				}
+			}
+		})
+	}
+
+	t.Run("basic authentication without a Secret", func(t *testing.T) {
+		provider := NewProvider(&i2gw.ProviderConf{}).(*Provider)
+		ingress := testIngress("a", "example.com", "/", networkingv1.PathTypePrefix, map[string]string{authTypeAnnotation: "basic"})
+		provider.storage.Ingresses.FromMap(map[types.NamespacedName]*networkingv1.Ingress{{Namespace: "default", Name: "a"}: &ingress})
+		if _, errs := provider.ToGatewayAPI(); len(errs) != 1 {
+			t.Errorf("Expected 1 error, got %+v", errs)
+		}
+	})
+
+	t.Run("route left without rules is removed", func(t *testing.T) {
+		gatewayResources, _ := convertTestIngresses(t, &i2gw.ProviderConf{},
+			testIngress("a", "example.com", "/", networkingv1.PathTypePrefix, map[string]string{authURLAnnotation: "http://oauth2-proxy/oauth2/auth"}))
+		if _, ok := gatewayResources.HTTPRoutes[routeKey]; ok {
+			t.Errorf("Expected HTTPRoute %s to be removed, got %+v", routeKey, gatewayResources.HTTPRoutes[routeKey])
+		}
+	})
+
+	t.Run("gRPC paths get no SecurityPolicy", func(t *testing.T) {
+		ingress := testIngress("a", "example.com", "/helloworld.Greeter", networkingv1.PathTypePrefix, map[string]string{
+			backendProtocolAnnotation:      "GRPC",
+			authURLAnnotation:              "http://oauth2-proxy:4180/oauth2/auth",
+			whitelistSourceRangeAnnotation: "10.0.0.0/8",
+		})
+		for _, allowUnprotected := range []string{"false", "true"} {
+			conf := &i2gw.ProviderConf{ProviderSpecificFlags: map[string]map[string]string{Name: {
+				TargetImplementationFlag:   envoyGatewayImplementation,
+				AllowUnprotectedRoutesFlag: allowUnprotected,
+			}}}
+			gatewayResources, notificationTable := convertTestIngresses(t, conf, ingress)
+			if len(gatewayResources.ExtensionResources) != 0 {
+				t.Errorf("Expected no SecurityPolicy, got %+v", gatewayResources.ExtensionResources)
+			}
+			if _, ok := gatewayResources.GRPCRoutes[routeKey]; ok != (allowUnprotected == "true") {
+				t.Errorf("Expected GRPCRoute %s only when unprotected routes are allowed, got %+v", routeKey, gatewayResources.GRPCRoutes)
+			}
+			for _, unexpected := range []string{"is converted to the extAuth", "are converted to the authorization", "auth-sensitive"} {
+				if strings.Contains(notificationTable, unexpected) {
+					t.Errorf("Unexpected notification %q, got:\n%s", unexpected, notificationTable)
+				}
+			}
+		}
+	})
+}
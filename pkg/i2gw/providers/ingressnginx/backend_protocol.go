@@ -0,0 +1,525 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingressnginx
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/providers/common"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/utils/ptr"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+	gatewayv1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
+)
+
+const (
+	backendProtocolAnnotation = "nginx.ingress.kubernetes.io/backend-protocol"
+	proxySSLVerifyAnnotation  = "nginx.ingress.kubernetes.io/proxy-ssl-verify"
+	proxySSLSecretAnnotation  = "nginx.ingress.kubernetes.io/proxy-ssl-secret"
+	proxySSLNameAnnotation    = "nginx.ingress.kubernetes.io/proxy-ssl-name"
+
+	protocolHTTP     = "HTTP"
+	protocolHTTPS    = "HTTPS"
+	protocolAutoHTTP = "AUTO_HTTP"
+	protocolGRPC     = "GRPC"
+	protocolGRPCS    = "GRPCS"
+	protocolFCGI     = "FCGI"
+
+	// h2cAppProtocol is the appProtocol telling Gateway implementations to
+	// reach a Service port with cleartext HTTP/2, which gRPC requires.
+	h2cAppProtocol = "kubernetes.io/h2c"
+)
+
+// appProtocolsByBackendProtocol are the appProtocols of Service ports
+// consistent with each backend protocol.
+var appProtocolsByBackendProtocol = map[string][]string{
+	protocolHTTP:     {"http"},
+	protocolAutoHTTP: {"http", "https"},
+	protocolHTTPS:    {"https"},
+	protocolGRPC:     {"grpc", "h2c", h2cAppProtocol},
+	protocolGRPCS:    {"grpcs", "https"},
+}
+
+var (
+	grpcServiceRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z_0-9]*(\.[A-Za-z_][A-Za-z_0-9]*)*$`)
+	grpcMethodRegex  = regexp.MustCompile(`^[A-Za-z_][A-Za-z_0-9]*$`)
+)
+
+// backendProtocol converts the backend-protocol annotation. The rules of
+// GRPC and GRPCS Ingresses are moved from the HTTPRoutes to GRPCRoutes, and
+// the Services of HTTPS and GRPCS Ingresses get a BackendTLSPolicy when
+// ingress-nginx verifies their certificate, or are reported otherwise. The
+// appProtocol of the backend Services is checked against the protocol.
+//
+// The rules are moved before the other features are applied, which are not
+// converted for GRPCRoutes. As the access control of the paths cannot be
+// applied to GRPCRoutes either, the rules of the gRPC paths with access
+// control are left out, unless allowUnprotected acknowledges it, like auth
+// does for the access control it cannot convert.
+type backendProtocol struct {
+	services map[types.NamespacedName]*corev1.Service
+	// allowUnprotected is the value of AllowUnprotectedRoutesFlag, which
+	// auth.feature validates.
+	allowUnprotected string
+}
+
+// ingressBackendProtocol returns the backend protocol of the Ingress, which
+// ingress-nginx matches case-insensitively and defaults to HTTP.
+func ingressBackendProtocol(ingress networkingv1.Ingress) string {
+	protocol := strings.ToUpper(strings.TrimSpace(ingress.Annotations[backendProtocolAnnotation]))
+	if protocol == "" {
+		return protocolHTTP
+	}
+	return protocol
+}
+
+func isGRPCProtocol(protocol string) bool {
+	return protocol == protocolGRPC || protocol == protocolGRPCS
+}
+
+func (b *backendProtocol) feature(ingresses []networkingv1.Ingress, gatewayResources *i2gw.GatewayResources) field.ErrorList {
+	for i := range ingresses {
+		ingress := &ingresses[i]
+		protocol := ingressBackendProtocol(*ingress)
+		switch protocol {
+		case protocolHTTP, protocolAutoHTTP, protocolHTTPS, protocolGRPC, protocolGRPCS:
+		case protocolFCGI:
+			notifications.NotificationAggr.DispatchNotification(notifications.NewNotification(
+				notifications.ErrorNotification,
+				fmt.Sprintf("%s %s cannot be converted: Gateway API has no FastCGI backends", backendProtocolAnnotation, protocol),
+				ingress,
+			), Name)
+			continue
+		default:
+			notifications.NotificationAggr.DispatchNotification(notifications.NewNotification(
+				notifications.WarningNotification,
+				fmt.Sprintf("%s %q is not a valid backend protocol, which ingress-nginx ignores: the backends are reached with HTTP", backendProtocolAnnotation, ingress.Annotations[backendProtocolAnnotation]),
+				ingress,
+			), Name)
+			protocol = protocolHTTP
+		}
+
+		b.checkAppProtocols(ingress, protocol)
+		if protocol == protocolHTTPS || protocol == protocolGRPCS {
+			addBackendTLSPolicies(ingress, gatewayResources)
+		}
+		if isGRPCProtocol(protocol) {
+			notifyUnconvertedGRPCAnnotations(ingress)
+		}
+	}
+
+	allowUnprotected, _ := strconv.ParseBool(b.allowUnprotected)
+	moveGRPCRules(ingresses, gatewayResources, allowUnprotected)
+	return nil
+}
+
+// checkAppProtocols reports the backend Service ports whose appProtocol is not
+// consistent with the backend protocol, and the gRPC ones without one.
+func (b *backendProtocol) checkAppProtocols(ingress *networkingv1.Ingress, protocol string) {
+	for _, backend := range ingressServiceBackends(*ingress) {
+		service, ok := b.services[types.NamespacedName{Namespace: ingress.Namespace, Name: backend.Name}]
+		if !ok {
+			continue
+		}
+		servicePort, ok := findServicePort(service, backend.Port)
+		if !ok {
+			continue
+		}
+
+		serviceKey := fmt.Sprintf("%s/%s", service.Namespace, service.Name)
+		if servicePort.AppProtocol == nil || *servicePort.AppProtocol == "" {
+			if protocol == protocolGRPC {
+				notifications.NotificationAggr.DispatchNotification(notifications.NewNotification(
+					notifications.WarningNotification,
+					fmt.Sprintf("port %s of Service %s has no appProtocol: Gateway implementations may not reach it with HTTP/2, which gRPC requires, unless it is set to %s",
+						servicePortName(servicePort), serviceKey, h2cAppProtocol),
+					ingress, service,
+				), Name)
+			}
+			continue
+		}
+
+		appProtocol := strings.ToLower(*servicePort.AppProtocol)
+		consistent := false
+		for _, candidate := range appProtocolsByBackendProtocol[protocol] {
+			consistent = consistent || appProtocol == candidate
+		}
+		if !consistent {
+			notifications.NotificationAggr.DispatchNotification(notifications.NewNotification(
+				notifications.WarningNotification,
+				fmt.Sprintf("appProtocol %s of port %s of Service %s is not consistent with backend protocol %s of the Ingress: Gateway implementations may use it to reach the backend instead",
+					*servicePort.AppProtocol, servicePortName(servicePort), serviceKey, protocol),
+				ingress, service,
+			), Name)
+		}
+	}
+}
+
+// addBackendTLSPolicies adds a BackendTLSPolicy for the backend Services of
+// the Ingress when ingress-nginx verifies their certificate with a CA of the
+// Ingress namespace. ingress-nginx does not verify the certificate by default,
+// which BackendTLSPolicy cannot express, so the Services are reported instead.
+func addBackendTLSPolicies(ingress *networkingv1.Ingress, gatewayResources *i2gw.GatewayResources) {
+	backends := ingressServiceBackends(*ingress)
+	serviceNames := make([]string, 0, len(backends))
+	for _, backend := range backends {
+		if len(serviceNames) == 0 || serviceNames[len(serviceNames)-1] != backend.Name {
+			serviceNames = append(serviceNames, backend.Name)
+		}
+	}
+
+	verify := strings.EqualFold(ingress.Annotations[proxySSLVerifyAnnotation], "on")
+	caSecret, secretErr := common.ParseNamespacedName(ingress.Annotations[proxySSLSecretAnnotation])
+	if !verify || secretErr != nil || caSecret.Namespace != ingress.Namespace {
+		reason := "ingress-nginx does not verify their certificate, while a BackendTLSPolicy requires a CA certificate to verify it with"
+		if verify {
+			reason = fmt.Sprintf("%s %q is not a Secret of namespace %s, which a BackendTLSPolicy can reference", proxySSLSecretAnnotation, ingress.Annotations[proxySSLSecretAnnotation], ingress.Namespace)
+		}
+		notifications.NotificationAggr.DispatchNotification(notifications.NewNotification(
+			notifications.WarningNotification,
+			fmt.Sprintf("Services %s are reached over TLS, which must be configured manually with a BackendTLSPolicy or the Gateway implementation: %s",
+				strings.Join(serviceNames, ", "), reason),
+			ingress,
+		), Name)
+		return
+	}
+
+	if gatewayResources.BackendTLSPolicies == nil {
+		gatewayResources.BackendTLSPolicies = map[types.NamespacedName]gatewayv1alpha2.BackendTLSPolicy{}
+	}
+	for _, serviceName := range serviceNames {
+		key := types.NamespacedName{Namespace: ingress.Namespace, Name: fmt.Sprintf("%s-backend-tls", serviceName)}
+		if _, ok := gatewayResources.BackendTLSPolicies[key]; ok {
+			continue
+		}
+		hostname := ingress.Annotations[proxySSLNameAnnotation]
+		if hostname == "" {
+			hostname = fmt.Sprintf("%s.%s.svc", serviceName, ingress.Namespace)
+		}
+		policy := gatewayv1alpha2.BackendTLSPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace},
+			Spec: gatewayv1alpha2.BackendTLSPolicySpec{
+				TargetRef: gatewayv1alpha2.PolicyTargetReferenceWithSectionName{
+					PolicyTargetReference: gatewayv1alpha2.PolicyTargetReference{
+						Group: "",
+						Kind:  "Service",
+						Name:  gatewayv1.ObjectName(serviceName),
+					},
+				},
+				TLS: gatewayv1alpha2.BackendTLSPolicyConfig{
+					CACertRefs: []gatewayv1.LocalObjectReference{{
+						Group: "",
+						Kind:  "Secret",
+						Name:  gatewayv1.ObjectName(caSecret.Name),
+					}},
+					Hostname: gatewayv1.PreciseHostname(hostname),
+				},
+			},
+		}
+		policy.SetGroupVersionKind(common.BackendTLSPolicyGVK)
+		gatewayResources.BackendTLSPolicies[key] = policy
+
+		notifications.NotificationAggr.DispatchNotification(notifications.NewNotification(
+			notifications.InfoNotification,
+			fmt.Sprintf("BackendTLSPolicy %s verifies Service %s with the CA certificate of Secret %s: implementations only supporting ConfigMap references require it to be copied to a ConfigMap with a ca.crt key",
+				key, serviceName, caSecret.Name),
+			ingress,
+		), Name)
+	}
+}
+
+// grpcRouteAnnotations are the annotations converted to filters which
+// GRPCRoute rules support.
+var grpcRouteAnnotations = map[string]bool{
+	backendProtocolAnnotation:  true,
+	xForwardedPrefixAnnotation: true,
+	proxySetHeadersAnnotation:  true,
+	customHeadersAnnotation:    true,
+}
+
+// notifyUnconvertedGRPCAnnotations reports the annotations of a gRPC Ingress
+// which are not converted, as its rules are moved to a GRPCRoute.
+func notifyUnconvertedGRPCAnnotations(ingress *networkingv1.Ingress) {
+	var unconverted []string
+	for annotation := range ingress.Annotations {
+		if !strings.HasPrefix(annotation, "nginx.ingress.kubernetes.io/") || grpcRouteAnnotations[annotation] ||
+			strings.HasPrefix(annotation, canaryAnnotation) || strings.HasPrefix(annotation, "nginx.ingress.kubernetes.io/proxy-ssl-") {
+			continue
+		}
+		unconverted = append(unconverted, annotation)
+	}
+	if len(unconverted) == 0 {
+		return
+	}
+	sort.Strings(unconverted)
+	notifications.NotificationAggr.DispatchNotification(notifications.NewNotification(
+		notifications.WarningNotification,
+		fmt.Sprintf("The rules of the gRPC Ingress are converted to a GRPCRoute, for which %s are not converted", strings.Join(unconverted, ", ")),
+		ingress,
+	), Name)
+}
+
+// grpcAccessControl returns the annotation setting the access control of the
+// paths of the gRPC Ingress, empty when it sets none.
+func grpcAccessControl(ingress *networkingv1.Ingress) string {
+	for _, annotation := range []string{authURLAnnotation, authTypeAnnotation, whitelistSourceRangeAnnotation, denylistSourceRangeAnnotation} {
+		if strings.TrimSpace(ingress.Annotations[annotation]) != "" {
+			return annotation
+		}
+	}
+	if access, _ := parseSnippetAccess(ingress); access != nil {
+		return access.annotation
+	}
+	return ""
+}
+
+// moveGRPCRules moves the HTTPRoute rules of gRPC Ingresses to a GRPCRoute of
+// the same name. The rules with access control are left out instead, unless
+// allowUnprotected is set. HTTPRoutes left without rules are removed.
+func moveGRPCRules(ingresses []networkingv1.Ingress, gatewayResources *i2gw.GatewayResources, allowUnprotected bool) {
+	serverAccess := serverSnippetAccess(ingresses)
+	for _, rg := range sortedRuleGroups(ingresses) {
+		key := types.NamespacedName{Namespace: rg.Namespace, Name: common.RouteName(rg.Name, rg.Host)}
+		httpRoute, ok := gatewayResources.HTTPRoutes[key]
+		if !ok {
+			continue
+		}
+
+		var grpcRules []gatewayv1alpha2.GRPCRouteRule
+		removed := map[int]bool{}
+		sourcesByRule := ruleSourcesByIndex(rg, httpRoute)
+		for _, ruleIdx := range sortedRuleIndexes(sourcesByRule) {
+			sources := annotationSources(sourcesByRule[ruleIdx])
+			grpcCount := 0
+			for _, source := range sources {
+				if isGRPCProtocol(ingressBackendProtocol(source.ingress)) {
+					grpcCount++
+				}
+			}
+			if grpcCount == 0 {
+				continue
+			}
+
+			var err error
+			if grpcCount < len(sources) {
+				err = fmt.Errorf("it is shared with Ingresses not setting a gRPC %s", backendProtocolAnnotation)
+			}
+			var grpcRule gatewayv1alpha2.GRPCRouteRule
+			if err == nil {
+				grpcRule, err = toGRPCRouteRule(httpRoute.Spec.Rules[ruleIdx])
+			}
+			if err != nil {
+				notifications.NotificationAggr.DispatchNotification(notifications.NewNotification(
+					notifications.WarningNotification,
+					fmt.Sprintf("Path %q of a gRPC Ingress is kept in HTTPRoute %s, as it cannot be converted to a GRPCRoute rule: %v", sources[0].path.Path, key, err),
+					&sources[0].ingress,
+				), Name)
+				continue
+			}
+			removed[ruleIdx] = true
+
+			accessAnnotation := grpcAccessControl(&sources[0].ingress)
+			if access, ok := serverAccess[key]; ok && accessAnnotation == "" {
+				accessAnnotation = access.annotation
+			}
+			switch {
+			case accessAnnotation != "" && !allowUnprotected:
+				notifications.NotificationAggr.DispatchNotification(notifications.NewNotification(
+					notifications.ErrorNotification,
+					fmt.Sprintf("Path %q of a gRPC Ingress is left out of HTTPRoute %s, as the access control of %s cannot be applied to GRPCRoutes: configure it with the Gateway implementation, or set --%s-%s to convert it without access control",
+						sources[0].path.Path, key, accessAnnotation, Name, AllowUnprotectedRoutesFlag),
+					&sources[0].ingress,
+				), Name)
+				continue
+			case accessAnnotation != "":
+				notifications.NotificationAggr.DispatchNotification(notifications.NewNotification(
+					notifications.WarningNotification,
+					fmt.Sprintf("Path %q of a gRPC Ingress is moved to GRPCRoute %s without the access control of %s, which cannot be applied to GRPCRoutes, and --%s-%s acknowledges it: it is open to anyone until it is configured with the Gateway implementation",
+						sources[0].path.Path, key, accessAnnotation, Name, AllowUnprotectedRoutesFlag),
+					&sources[0].ingress,
+				), Name)
+			}
+			grpcRules = append(grpcRules, grpcRule)
+		}
+		if len(removed) == 0 {
+			continue
+		}
+
+		if len(grpcRules) > 0 {
+			grpcRoute := gatewayv1alpha2.GRPCRoute{
+				ObjectMeta: metav1.ObjectMeta{Name: httpRoute.Name, Namespace: httpRoute.Namespace},
+				Spec: gatewayv1alpha2.GRPCRouteSpec{
+					CommonRouteSpec: *httpRoute.Spec.CommonRouteSpec.DeepCopy(),
+					Hostnames:       httpRoute.Spec.Hostnames,
+					Rules:           grpcRules,
+				},
+			}
+			grpcRoute.SetGroupVersionKind(common.GRPCRouteGVK)
+			if gatewayResources.GRPCRoutes == nil {
+				gatewayResources.GRPCRoutes = map[types.NamespacedName]gatewayv1alpha2.GRPCRoute{}
+			}
+			gatewayResources.GRPCRoutes[key] = grpcRoute
+		}
+
+		var httpRules []gatewayv1.HTTPRouteRule
+		for i, rule := range httpRoute.Spec.Rules {
+			if !removed[i] {
+				httpRules = append(httpRules, rule)
+			}
+		}
+		if len(httpRules) == 0 {
+			delete(gatewayResources.HTTPRoutes, key)
+			continue
+		}
+		httpRoute.Spec.Rules = httpRules
+		gatewayResources.HTTPRoutes[key] = httpRoute
+	}
+}
+
+func toGRPCRouteRule(rule gatewayv1.HTTPRouteRule) (gatewayv1alpha2.GRPCRouteRule, error) {
+	var grpcRule gatewayv1alpha2.GRPCRouteRule
+	for _, match := range rule.Matches {
+		var grpcMatch gatewayv1alpha2.GRPCRouteMatch
+		if match.Path != nil {
+			method, err := toGRPCMethodMatch(*match.Path)
+			if err != nil {
+				return gatewayv1alpha2.GRPCRouteRule{}, err
+			}
+			grpcMatch.Method = method
+		}
+		for _, header := range match.Headers {
+			grpcMatch.Headers = append(grpcMatch.Headers, gatewayv1alpha2.GRPCHeaderMatch{
+				Type:  header.Type,
+				Name:  gatewayv1alpha2.GRPCHeaderName(header.Name),
+				Value: header.Value,
+			})
+		}
+		if grpcMatch.Method != nil || len(grpcMatch.Headers) > 0 {
+			grpcRule.Matches = append(grpcRule.Matches, grpcMatch)
+		}
+	}
+	for _, filter := range rule.Filters {
+		switch filter.Type {
+		case gatewayv1.HTTPRouteFilterRequestHeaderModifier, gatewayv1.HTTPRouteFilterResponseHeaderModifier, gatewayv1.HTTPRouteFilterRequestMirror:
+			grpcRule.Filters = append(grpcRule.Filters, gatewayv1alpha2.GRPCRouteFilter{
+				Type:                   gatewayv1alpha2.GRPCRouteFilterType(filter.Type),
+				RequestHeaderModifier:  filter.RequestHeaderModifier,
+				ResponseHeaderModifier: filter.ResponseHeaderModifier,
+				RequestMirror:          filter.RequestMirror,
+			})
+		default:
+			return gatewayv1alpha2.GRPCRouteRule{}, fmt.Errorf("GRPCRoute has no %s filter", filter.Type)
+		}
+	}
+	for _, backendRef := range rule.BackendRefs {
+		grpcRule.BackendRefs = append(grpcRule.BackendRefs, gatewayv1alpha2.GRPCBackendRef{BackendRef: backendRef.BackendRef})
+	}
+	return grpcRule, nil
+}
+
+// toGRPCMethodMatch returns the match of the gRPC methods whose paths, made of
+// /<service>/<method>, are matched by the path match. It returns nil for the
+// root prefix, which matches every method.
+func toGRPCMethodMatch(path gatewayv1.HTTPPathMatch) (*gatewayv1alpha2.GRPCMethodMatch, error) {
+	value := ptr.Deref(path.Value, "/")
+	isPrefix := path.Type == nil || *path.Type == gatewayv1.PathMatchPathPrefix
+	if path.Type != nil && *path.Type != gatewayv1.PathMatchPathPrefix && *path.Type != gatewayv1.PathMatchExact {
+		return nil, fmt.Errorf("path %q is not a prefix or exact path", value)
+	}
+
+	parts := strings.Split(strings.TrimPrefix(value, "/"), "/")
+	if isPrefix && len(parts) > 1 && parts[len(parts)-1] == "" {
+		parts = parts[:len(parts)-1]
+	}
+	switch {
+	case isPrefix && len(parts) == 1 && parts[0] == "":
+		return nil, nil
+	case isPrefix && len(parts) == 1 && grpcServiceRegex.MatchString(parts[0]):
+		return &gatewayv1alpha2.GRPCMethodMatch{
+			Type:    ptr.To(gatewayv1alpha2.GRPCMethodMatchExact),
+			Service: ptr.To(parts[0]),
+		}, nil
+	case len(parts) == 2 && grpcServiceRegex.MatchString(parts[0]) && grpcMethodRegex.MatchString(parts[1]):
+		return &gatewayv1alpha2.GRPCMethodMatch{
+			Type:    ptr.To(gatewayv1alpha2.GRPCMethodMatchExact),
+			Service: ptr.To(parts[0]),
+			Method:  ptr.To(parts[1]),
+		}, nil
+	}
+	return nil, fmt.Errorf("path %q does not match a gRPC service or method", value)
+}
+
+// ingressServiceBackends returns the Service backends of the Ingress, sorted
+// by name and port, without duplicates.
+func ingressServiceBackends(ingress networkingv1.Ingress) []networkingv1.IngressServiceBackend {
+	var backends []networkingv1.IngressServiceBackend
+	seen := map[networkingv1.IngressServiceBackend]bool{}
+	add := func(backend networkingv1.IngressBackend) {
+		if backend.Service == nil || seen[*backend.Service] {
+			return
+		}
+		seen[*backend.Service] = true
+		backends = append(backends, *backend.Service)
+	}
+	if ingress.Spec.DefaultBackend != nil {
+		add(*ingress.Spec.DefaultBackend)
+	}
+	for _, rule := range ingress.Spec.Rules {
+		if rule.HTTP == nil {
+			continue
+		}
+		for _, path := range rule.HTTP.Paths {
+			add(path.Backend)
+		}
+	}
+	sort.Slice(backends, func(i, j int) bool {
+		if backends[i].Name != backends[j].Name {
+			return backends[i].Name < backends[j].Name
+		}
+		if backends[i].Port.Name != backends[j].Port.Name {
+			return backends[i].Port.Name < backends[j].Port.Name
+		}
+		return backends[i].Port.Number < backends[j].Port.Number
+	})
+	return backends
+}
+
+func findServicePort(service *corev1.Service, port networkingv1.ServiceBackendPort) (corev1.ServicePort, bool) {
+	for _, servicePort := range service.Spec.Ports {
+		if (port.Name != "" && servicePort.Name == port.Name) || (port.Name == "" && servicePort.Port == port.Number) {
+			return servicePort, true
+		}
+	}
+	return corev1.ServicePort{}, false
+}
+
+func servicePortName(servicePort corev1.ServicePort) string {
+	if servicePort.Name != "" {
+		return servicePort.Name
+	}
+	return fmt.Sprintf("%d", servicePort.Port)
+}
@@ -0,0 +1,240 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingressnginx
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+	gatewayv1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
+)
+
+func Test_backendProtocolFeature(t *testing.T) {
+	testBackendRef := gatewayv1alpha2.GRPCBackendRef{
+		BackendRef: gatewayv1.BackendRef{
+			BackendObjectReference: gatewayv1.BackendObjectReference{Name: "test", Port: ptrTo(gatewayv1.PortNumber(80))},
+		},
+	}
+
+	testCases := []struct {
+		name                   string
+		ingresses              []networkingv1.Ingress
+		flags                  map[string]string
+		appProtocol            *string
+		expectedGRPCRules      []gatewayv1alpha2.GRPCRouteRule
+		expectedHTTPRouteRules int
+		expectedPolicy         *gatewayv1alpha2.BackendTLSPolicySpec
+		expectedNotifications  []string
+	}{{
+		name: "grpc service",
+		ingresses: []networkingv1.Ingress{
+			testIngress("a", "example.com", "/helloworld.Greeter", networkingv1.PathTypePrefix, map[string]string{
+				backendProtocolAnnotation: "grpc",
+			}),
+		},
+		appProtocol: ptrTo(h2cAppProtocol),
+		expectedGRPCRules: []gatewayv1alpha2.GRPCRouteRule{{
+			Matches: []gatewayv1alpha2.GRPCRouteMatch{{
+				Method: &gatewayv1alpha2.GRPCMethodMatch{
+					Type:    ptrTo(gatewayv1alpha2.GRPCMethodMatchExact),
+					Service: ptrTo("helloworld.Greeter"),
+				},
+			}},
+			BackendRefs: []gatewayv1alpha2.GRPCBackendRef{testBackendRef},
+		}},
+	}, {
+		name: "grpc method of a mixed host",
+		ingresses: []networkingv1.Ingress{
+			testIngress("a", "example.com", "/helloworld.Greeter/SayHello", networkingv1.PathTypeExact, map[string]string{
+				backendProtocolAnnotation: "GRPC",
+			}),
+			testIngress("b", "example.com", "/web", networkingv1.PathTypePrefix, nil),
+		},
+		expectedGRPCRules: []gatewayv1alpha2.GRPCRouteRule{{
+			Matches: []gatewayv1alpha2.GRPCRouteMatch{{
+				Method: &gatewayv1alpha2.GRPCMethodMatch{
+					Type:    ptrTo(gatewayv1alpha2.GRPCMethodMatchExact),
+					Service: ptrTo("helloworld.Greeter"),
+					Method:  ptrTo("SayHello"),
+				},
+			}},
+			BackendRefs: []gatewayv1alpha2.GRPCBackendRef{testBackendRef},
+		}},
+		expectedHTTPRouteRules: 1,
+		expectedNotifications:  []string{"has no appProtocol"},
+	}, {
+		name: "grpc root path matches every method",
+		ingresses: []networkingv1.Ingress{
+			testIngress("a", "example.com", "/", networkingv1.PathTypePrefix, map[string]string{
+				backendProtocolAnnotation: "GRPC",
+			}),
+		},
+		appProtocol:       ptrTo("grpc"),
+		expectedGRPCRules: []gatewayv1alpha2.GRPCRouteRule{{BackendRefs: []gatewayv1alpha2.GRPCBackendRef{testBackendRef}}},
+	}, {
+		name: "grpc path which is not a method is kept",
+		ingresses: []networkingv1.Ingress{
+			testIngress("a", "example.com", "/api/v1/greeter", networkingv1.PathTypePrefix, map[string]string{
+				backendProtocolAnnotation: "GRPC",
+			}),
+		},
+		appProtocol:            ptrTo("grpc"),
+		expectedHTTPRouteRules: 1,
+		expectedNotifications:  []string{"cannot be converted to a GRPCRoute rule"},
+	}, {
+		name: "grpc path with authentication is left out",
+		ingresses: []networkingv1.Ingress{
+			testIngress("a", "example.com", "/helloworld.Greeter", networkingv1.PathTypePrefix, map[string]string{
+				backendProtocolAnnotation: "GRPC",
+				authURLAnnotation:         "http://auth.default.svc.cluster.local/verify",
+			}),
+		},
+		appProtocol:           ptrTo("grpc"),
+		expectedNotifications: []string{"is left out of HTTPRoute default/a-example-com, as the access control of nginx.ingress.kubernetes.io/auth-url cannot be applied to GRPCRoutes"},
+	}, {
+		name: "grpc path with IP access acknowledged",
+		ingresses: []networkingv1.Ingress{
+			testIngress("a", "example.com", "/helloworld.Greeter", networkingv1.PathTypePrefix, map[string]string{
+				backendProtocolAnnotation:      "GRPC",
+				whitelistSourceRangeAnnotation: "10.0.0.0/8",
+			}),
+		},
+		flags:       map[string]string{AllowUnprotectedRoutesFlag: "true"},
+		appProtocol: ptrTo("grpc"),
+		expectedGRPCRules: []gatewayv1alpha2.GRPCRouteRule{{
+			Matches: []gatewayv1alpha2.GRPCRouteMatch{{
+				Method: &gatewayv1alpha2.GRPCMethodMatch{
+					Type:    ptrTo(gatewayv1alpha2.GRPCMethodMatchExact),
+					Service: ptrTo("helloworld.Greeter"),
+				},
+			}},
+			BackendRefs: []gatewayv1alpha2.GRPCBackendRef{testBackendRef},
+		}},
+		expectedNotifications: []string{"is moved to GRPCRoute default/a-example-com without the access control of nginx.ingress.kubernetes.io/whitelist-source-range"},
+	}, {
+		name: "https without verification",
+		ingresses: []networkingv1.Ingress{
+			testIngress("a", "example.com", "/", networkingv1.PathTypePrefix, map[string]string{
+				backendProtocolAnnotation: "HTTPS",
+			}),
+		},
+		appProtocol:            ptrTo("http"),
+		expectedHTTPRouteRules: 1,
+		expectedNotifications: []string{
+			"ingress-nginx does not verify their certificate",
+			"appProtocol http of port 80 of Service default/test is not consistent with backend protocol HTTPS",
+		},
+	}, {
+		name: "https with verification",
+		ingresses: []networkingv1.Ingress{
+			testIngress("a", "example.com", "/", networkingv1.PathTypePrefix, map[string]string{
+				backendProtocolAnnotation: "HTTPS",
+				proxySSLVerifyAnnotation:  "on",
+				proxySSLSecretAnnotation:  "default/test-ca",
+			}),
+		},
+		appProtocol:            ptrTo("https"),
+		expectedHTTPRouteRules: 1,
+		expectedPolicy: &gatewayv1alpha2.BackendTLSPolicySpec{
+			TargetRef: gatewayv1alpha2.PolicyTargetReferenceWithSectionName{
+				PolicyTargetReference: gatewayv1alpha2.PolicyTargetReference{Kind: "Service", Name: "test"},
+			},
+			TLS: gatewayv1alpha2.BackendTLSPolicyConfig{
+				CACertRefs: []gatewayv1.LocalObjectReference{{Kind: "Secret", Name: "test-ca"}},
+				Hostname:   "test.default.svc",
+			},
+		},
+	}, {
+		name: "fastcgi",
+		ingresses: []networkingv1.Ingress{
+			testIngress("a", "example.com", "/", networkingv1.PathTypePrefix, map[string]string{
+				backendProtocolAnnotation: "FCGI",
+			}),
+		},
+		expectedHTTPRouteRules: 1,
+		expectedNotifications:  []string{"Gateway API has no FastCGI backends"},
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			provider := NewProvider(&i2gw.ProviderConf{
+				ProviderSpecificFlags: map[string]map[string]string{Name: tc.flags},
+			}).(*Provider)
+			ingressMap := map[types.NamespacedName]*networkingv1.Ingress{}
+			for i := range tc.ingresses {
+				ingressMap[types.NamespacedName{Namespace: tc.ingresses[i].Namespace, Name: tc.ingresses[i].Name}] = &tc.ingresses[i]
+			}
+			provider.storage.Ingresses.FromMap(ingressMap)
+			provider.storage.Services[types.NamespacedName{Namespace: "default", Name: "test"}] = &corev1.Service{
+				ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
+				Spec: corev1.ServiceSpec{
+					Ports: []corev1.ServicePort{{Port: 80, AppProtocol: tc.appProtocol}},
+				},
+			}
+
+			gatewayResources, errs := provider.ToGatewayAPI()
+			if len(errs) != 0 {
+				t.Fatalf("Unexpected errors: %+v", errs)
+			}
+			notificationTable := notifications.NotificationAggr.CreateNotificationTables()[Name]
+
+			key := types.NamespacedName{Namespace: "default", Name: "a-example-com"}
+			grpcRoute, ok := gatewayResources.GRPCRoutes[key]
+			if tc.expectedGRPCRules == nil && ok {
+				t.Errorf("Expected no GRPCRoute %s, got %+v", key, grpcRoute)
+			} else if diff := cmp.Diff(tc.expectedGRPCRules, grpcRoute.Spec.Rules); diff != "" {
+				t.Errorf("Unexpected GRPCRoute rules, diff (-want +got):\n%s", diff)
+			}
+			if ok {
+				if diff := cmp.Diff([]gatewayv1.Hostname{"example.com"}, grpcRoute.Spec.Hostnames); diff != "" {
+					t.Errorf("Unexpected GRPCRoute hostnames, diff (-want +got):\n%s", diff)
+				}
+			}
+
+			httpRoute, ok := gatewayResources.HTTPRoutes[key]
+			if tc.expectedHTTPRouteRules == 0 && ok {
+				t.Errorf("Expected no HTTPRoute %s, got %+v", key, httpRoute)
+			} else if len(httpRoute.Spec.Rules) != tc.expectedHTTPRouteRules {
+				t.Errorf("Expected %d HTTPRoute rules, got %+v", tc.expectedHTTPRouteRules, httpRoute.Spec.Rules)
+			}
+
+			policyKey := types.NamespacedName{Namespace: "default", Name: "test-backend-tls"}
+			policy, ok := gatewayResources.BackendTLSPolicies[policyKey]
+			if tc.expectedPolicy == nil {
+				if ok {
+					t.Errorf("Expected no BackendTLSPolicy %s, got %+v", policyKey, policy)
+				}
+			} else if diff := cmp.Diff(*tc.expectedPolicy, policy.Spec); diff != "" {
+				t.Errorf("Unexpected BackendTLSPolicy, diff (-want +got):\n%s", diff)
+			}
+
+			for _, expected := range tc.expectedNotifications {
+				if !strings.Contains(notificationTable, expected) {
+					t.Errorf("Expected notification %q, got:\n%s", expected, notificationTable)
+				}
+			}
+		})
+	}
+}
@@ -18,9 +18,12 @@ package ingressnginx
 
 import (
 	"fmt"
+	"regexp"
+	"slices"
 	"strconv"
 
 	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
 	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/providers/common"
 	networkingv1 "k8s.io/api/networking/v1"
 	"k8s.io/apimachinery/pkg/types"
@@ -29,89 +32,191 @@ import (
 	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
 )
 
-func canaryFeature(ingresses []networkingv1.Ingress, gatewayResources *i2gw.GatewayResources) field.ErrorList {
-	ruleGroups := common.GetRuleGroups(ingresses)
+const (
+	canaryAnnotation              = "nginx.ingress.kubernetes.io/canary"
+	canaryByHeaderAnnotation      = "nginx.ingress.kubernetes.io/canary-by-header"
+	canaryByHeaderValueAnnotation = "nginx.ingress.kubernetes.io/canary-by-header-value"
+	canaryByHeaderPattern         = "nginx.ingress.kubernetes.io/canary-by-header-pattern"
+	canaryByCookieAnnotation      = "nginx.ingress.kubernetes.io/canary-by-cookie"
+	canaryWeightAnnotation        = "nginx.ingress.kubernetes.io/canary-weight"
+	canaryWeightTotalAnnotation   = "nginx.ingress.kubernetes.io/canary-weight-total"
+
+	// canaryAlways and canaryNever are the values of the canary header and
+	// cookie routing requests to the canary, and away from it.
+	canaryAlways = "always"
+	canaryNever  = "never"
+)
 
-	for _, rg := range ruleGroups {
+// canaryFeature converts canary Ingresses. The requests matching the header or
+// cookie of the canary are routed by rules added before the rule of the path,
+// which splits the other requests between the backends by weight.
+//
+// ingress-nginx evaluates the header first, then the cookie, then the weight.
+// The added rules have a single header match each, so that HTTPRoute rule
+// precedence falls back to their order, and are ordered the same way.
+func canaryFeature(ingresses []networkingv1.Ingress, gatewayResources *i2gw.GatewayResources) field.ErrorList {
+	for _, rg := range sortedRuleGroups(ingresses) {
 		ingressPathsByMatchKey, errs := getPathsByMatchGroups(rg)
 		if len(errs) > 0 {
 			return errs
 		}
 
-		for _, paths := range ingressPathsByMatchKey {
-			path := paths[0]
-
-			backendRefs, calculationErrs := calculateBackendRefWeight(paths)
-			errs = append(errs, calculationErrs...)
+		key := types.NamespacedName{Namespace: rg.Namespace, Name: common.RouteName(rg.Name, rg.Host)}
+		httpRoute, ok := gatewayResources.HTTPRoutes[key]
+		if !ok {
+			// If there wasn't an HTTPRoute for this Ingress, we can skip it as something is wrong.
+			// All the available errors will be returned at the end.
+			continue
+		}
 
-			key := types.NamespacedName{Namespace: path.ingress.Namespace, Name: common.RouteName(rg.Name, rg.Host)}
-			httpRoute, ok := gatewayResources.HTTPRoutes[key]
+		for _, pmKey := range ingressPathsByMatchKey.keys {
+			paths := ingressPathsByMatchKey.data[pmKey]
+			canaryPath, ok := findCanaryPath(paths)
 			if !ok {
-				// If there wasn't an HTTPRoute for this Ingress, we can skip it as something is wrong.
-				// All the available errors will be returned at the end.
 				continue
 			}
+			ruleIdxs := findHTTPRouteRules(httpRoute, paths[0].path)
+			if len(ruleIdxs) == 0 {
+				continue
+			}
+			ruleIdx := ruleIdxs[0]
 
-			patchHTTPRouteWithBackendRefs(&httpRoute, backendRefs)
+			backendRefs, calculationErrs := calculateBackendRefWeight(paths)
+			errs = append(errs, calculationErrs...)
+			if len(calculationErrs) > 0 {
+				continue
+			}
+			httpRoute.Spec.Rules[ruleIdx].BackendRefs = backendRefs
+
+			canaryRules, ruleErrs := canaryMatchRules(httpRoute.Spec.Rules[ruleIdx], paths, canaryPath)
+			errs = append(errs, ruleErrs...)
+			httpRoute.Spec.Rules = slices.Insert(httpRoute.Spec.Rules, ruleIdx, canaryRules...)
+			if cookie := canaryPath.extra.canary.cookie; cookie != "" {
+				notifications.NotificationAggr.DispatchNotification(notifications.NewNotification(
+					notifications.InfoNotification,
+					fmt.Sprintf("%s %q of path %q is converted to regular expression matches of the Cookie header, which require the Gateway implementation to match the Cookie headers of a request joined in a single value", canaryByCookieAnnotation, cookie, canaryPath.path.Path),
+					&canaryPath.ingress,
+				), Name)
+			}
 		}
 		if len(errs) > 0 {
 			return errs
 		}
+		gatewayResources.HTTPRoutes[key] = httpRoute
 	}
 
 	return nil
 }
 
-func getPathsByMatchGroups(rg common.IngressRuleGroup) (map[pathMatchKey][]ingressPath, field.ErrorList) {
-	ingressPathsByMatchKey := map[pathMatchKey][]ingressPath{}
+func getPathsByMatchGroups(rg common.IngressRuleGroup) (orderedIngressPathsByMatchKey, field.ErrorList) {
+	ingressPathsByMatchKey := orderedIngressPathsByMatchKey{
+		data: map[pathMatchKey][]ingressPath{},
+	}
 
 	for _, ir := range rg.Rules {
 
 		ingress := ir.Ingress
 		annotations, errs := parseCanaryAnnotations(ingress)
 		if len(errs) > 0 {
-			return nil, errs
+			return ingressPathsByMatchKey, errs
 		}
 
 		extraFeatures := extra{canary: &annotations}
 
+		if ir.IngressRule.HTTP == nil {
+			continue
+		}
 		for _, path := range ir.IngressRule.HTTP.Paths {
 			ip := ingressPath{ingress: ingress, ruleType: "http", path: path, extra: &extraFeatures}
 			pmKey := getPathMatchKey(ip)
-			ingressPathsByMatchKey[pmKey] = append(ingressPathsByMatchKey[pmKey], ip)
+			if _, ok := ingressPathsByMatchKey.data[pmKey]; !ok {
+				ingressPathsByMatchKey.keys = append(ingressPathsByMatchKey.keys, pmKey)
+			}
+			ingressPathsByMatchKey.data[pmKey] = append(ingressPathsByMatchKey.data[pmKey], ip)
 		}
 	}
 
 	return ingressPathsByMatchKey, nil
 }
 
-func patchHTTPRouteWithBackendRefs(httpRoute *gatewayv1.HTTPRoute, backendRefs []gatewayv1.HTTPBackendRef) {
-	for _, backendRef := range backendRefs {
+// findCanaryPath returns the first path of a canary Ingress. ingress-nginx
+// supports a single canary per path.
+func findCanaryPath(paths []ingressPath) (ingressPath, bool) {
+	for _, path := range paths {
+		if path.extra != nil && path.extra.canary != nil && path.extra.canary.enable {
+			return path, true
+		}
+	}
+	return ingressPath{}, false
+}
 
-		ruleExists := false
+// canaryMatchRules returns the rules routing the requests matching the header
+// and the cookie of the canary, in the order ingress-nginx evaluates them.
+func canaryMatchRules(rule gatewayv1.HTTPRouteRule, paths []ingressPath, canaryPath ingressPath) ([]gatewayv1.HTTPRouteRule, field.ErrorList) {
+	canary := canaryPath.extra.canary
+	if canary.headerKey == "" && canary.cookie == "" {
+		return nil, nil
+	}
 
-		for j, rule := range httpRoute.Spec.Rules {
-			foundBackendRef := false
-			for i := range rule.BackendRefs {
-				if backendRef.Name == rule.BackendRefs[i].Name {
-					rule.BackendRefs[i].Weight = backendRef.Weight
-					foundBackendRef = true
-					ruleExists = true
-					break
-				}
+	var errs field.ErrorList
+	var canaryBackendRefs, mainBackendRefs []gatewayv1.HTTPBackendRef
+	for i, path := range paths {
+		backendRef, err := common.ToBackendRef(path.path.Backend, field.NewPath("paths", "backends").Index(i))
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if path.extra != nil && path.extra.canary != nil && path.extra.canary.enable {
+			if path.ingress.Name == canaryPath.ingress.Name {
+				canaryBackendRefs = append(canaryBackendRefs, gatewayv1.HTTPBackendRef{BackendRef: *backendRef})
 			}
+			continue
+		}
+		mainBackendRefs = append(mainBackendRefs, gatewayv1.HTTPBackendRef{BackendRef: *backendRef})
+	}
 
-			if !foundBackendRef {
-				httpRoute.Spec.Rules[j].BackendRefs = append(rule.BackendRefs, backendRef)
-			}
+	var rules []gatewayv1.HTTPRouteRule
+	addRule := func(header gatewayv1.HTTPHeaderMatch, backendRefs []gatewayv1.HTTPBackendRef) {
+		if len(backendRefs) == 0 {
+			return
+		}
+		matchRule := gatewayv1.HTTPRouteRule{BackendRefs: backendRefs}
+		for _, match := range rule.Matches {
+			match = *match.DeepCopy()
+			match.Headers = append(match.Headers, header)
+			matchRule.Matches = append(matchRule.Matches, match)
 		}
+		rules = append(rules, matchRule)
+	}
 
-		if !ruleExists {
-			httpRoute.Spec.Rules = append(httpRoute.Spec.Rules, gatewayv1.HTTPRouteRule{
-				BackendRefs: []gatewayv1.HTTPBackendRef{backendRef},
-			})
+	if canary.headerKey != "" {
+		headerName := gatewayv1.HTTPHeaderName(canary.headerKey)
+		switch {
+		case canary.headerValue != "" && canary.headerRegexMatch:
+			addRule(gatewayv1.HTTPHeaderMatch{Type: ptr.To(gatewayv1.HeaderMatchRegularExpression), Name: headerName, Value: canary.headerValue}, canaryBackendRefs)
+		case canary.headerValue != "":
+			addRule(gatewayv1.HTTPHeaderMatch{Type: ptr.To(gatewayv1.HeaderMatchExact), Name: headerName, Value: canary.headerValue}, canaryBackendRefs)
+		default:
+			addRule(gatewayv1.HTTPHeaderMatch{Type: ptr.To(gatewayv1.HeaderMatchExact), Name: headerName, Value: canaryAlways}, canaryBackendRefs)
+			addRule(gatewayv1.HTTPHeaderMatch{Type: ptr.To(gatewayv1.HeaderMatchExact), Name: headerName, Value: canaryNever}, mainBackendRefs)
 		}
 	}
+	if canary.cookie != "" {
+		addRule(cookieHeaderMatch(canary.cookie, canaryAlways), canaryBackendRefs)
+		addRule(cookieHeaderMatch(canary.cookie, canaryNever), mainBackendRefs)
+	}
+
+	return rules, errs
+}
+
+// cookieHeaderMatch returns a match of the requests with the cookie set to the
+// value, as a regular expression matching the whole Cookie header.
+func cookieHeaderMatch(name, value string) gatewayv1.HTTPHeaderMatch {
+	return gatewayv1.HTTPHeaderMatch{
+		Type:  ptr.To(gatewayv1.HeaderMatchRegularExpression),
+		Name:  "Cookie",
+		Value: fmt.Sprintf(`^(.*;\s*)?%s=%s(\s*;.*)?$`, regexp.QuoteMeta(name), regexp.QuoteMeta(value)),
+	}
 }
 
 func calculateBackendRefWeight(paths []ingressPath) ([]gatewayv1.HTTPBackendRef, field.ErrorList) {
@@ -163,6 +268,7 @@ type canaryAnnotations struct {
 	headerKey        string
 	headerValue      string
 	headerRegexMatch bool
+	cookie           string
 	weight           int
 	weightTotal      int
 }
@@ -174,30 +280,31 @@ func parseCanaryAnnotations(ingress networkingv1.Ingress) (canaryAnnotations, fi
 	fieldPath := field.NewPath(ingress.Name).Child("metadata").Child("annotations")
 
 	var annotations canaryAnnotations
-	if c := ingress.Annotations["nginx.ingress.kubernetes.io/canary"]; c == "true" {
+	if c := ingress.Annotations[canaryAnnotation]; c == "true" {
 		annotations.enable = true
-		if cHeader := ingress.Annotations["nginx.ingress.kubernetes.io/canary-by-header"]; cHeader != "" {
-			annotations.headerKey = cHeader
-			annotations.headerValue = "always"
-		}
-		if cHeaderVal := ingress.Annotations["nginx.ingress.kubernetes.io/canary-by-header-value"]; cHeaderVal != "" {
-			annotations.headerValue = cHeaderVal
-		}
-		if cHeaderRegex := ingress.Annotations["nginx.ingress.kubernetes.io/canary-by-header-pattern"]; cHeaderRegex != "" {
-			annotations.headerValue = cHeaderRegex
-			annotations.headerRegexMatch = true
+		annotations.headerKey = ingress.Annotations[canaryByHeaderAnnotation]
+		if annotations.headerKey != "" {
+			// The value takes precedence over the pattern, and when neither is
+			// set, the header routes with the always and never values.
+			if cHeaderVal := ingress.Annotations[canaryByHeaderValueAnnotation]; cHeaderVal != "" {
+				annotations.headerValue = cHeaderVal
+			} else if cHeaderRegex := ingress.Annotations[canaryByHeaderPattern]; cHeaderRegex != "" {
+				annotations.headerValue = cHeaderRegex
+				annotations.headerRegexMatch = true
+			}
 		}
-		if cHeaderWeight := ingress.Annotations["nginx.ingress.kubernetes.io/canary-weight"]; cHeaderWeight != "" {
+		annotations.cookie = ingress.Annotations[canaryByCookieAnnotation]
+		if cHeaderWeight := ingress.Annotations[canaryWeightAnnotation]; cHeaderWeight != "" {
 			annotations.weight, err = strconv.Atoi(cHeaderWeight)
 			if err != nil {
-				errs = append(errs, field.TypeInvalid(fieldPath, "nginx.ingress.kubernetes.io/canary-weight", err.Error()))
+				errs = append(errs, field.TypeInvalid(fieldPath, canaryWeightAnnotation, err.Error()))
 			}
 			annotations.weightTotal = 100
 		}
-		if cHeaderWeightTotal := ingress.Annotations["nginx.ingress.kubernetes.io/canary-weight-total"]; cHeaderWeightTotal != "" {
+		if cHeaderWeightTotal := ingress.Annotations[canaryWeightTotalAnnotation]; cHeaderWeightTotal != "" {
 			annotations.weightTotal, err = strconv.Atoi(cHeaderWeightTotal)
 			if err != nil {
-				errs = append(errs, field.TypeInvalid(fieldPath, "nginx.ingress.kubernetes.io/canary-weight-total", err.Error()))
+				errs = append(errs, field.TypeInvalid(fieldPath, canaryWeightTotalAnnotation, err.Error()))
 			}
 		}
 	}
@@ -209,15 +316,18 @@ func getPathMatchKey(ip ingressPath) pathMatchKey {
 	if ip.path.PathType != nil {
 		pathType = string(*ip.path.PathType)
 	}
-	var canaryHeaderKey string
-	if ip.extra != nil && ip.extra.canary != nil && ip.extra.canary.headerKey != "" {
-		canaryHeaderKey = ip.extra.canary.headerKey
-	}
-	return pathMatchKey(fmt.Sprintf("%s/%s/%s", pathType, ip.path.Path, canaryHeaderKey))
+	return pathMatchKey(fmt.Sprintf("%s/%s", pathType, ip.path.Path))
 }
 
 type pathMatchKey string
 
+// orderedIngressPathsByMatchKey are the Ingress paths grouped by the key of
+// their HTTPRoute rule, in the order of the rules.
+type orderedIngressPathsByMatchKey struct {
+	keys []pathMatchKey
+	data map[pathMatchKey][]ingressPath
+}
+
 type ingressPath struct {
 	ingress networkingv1.Ingress
 
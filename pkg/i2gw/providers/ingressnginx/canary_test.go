@@ -17,12 +17,15 @@ limitations under the License.
 package ingressnginx
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw"
 	corev1 "k8s.io/api/core/v1"
 	networkingv1 "k8s.io/api/networking/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
 )
@@ -324,3 +327,114 @@ func Test_parseCanaryAnnotations(t *testing.T) {
 		})
 	}
 }
+
+func Test_canaryFeature(t *testing.T) {
+	pathMatch := gatewayv1.HTTPPathMatch{Type: ptrTo(gatewayv1.PathMatchPathPrefix), Value: ptrTo("/")}
+	backendRef := func(name string, weight *int32) gatewayv1.HTTPBackendRef {
+		return gatewayv1.HTTPBackendRef{BackendRef: gatewayv1.BackendRef{
+			BackendObjectReference: gatewayv1.BackendObjectReference{Name: gatewayv1.ObjectName(name), Port: ptrTo(gatewayv1.PortNumber(80))},
+			Weight:                 weight,
+		}}
+	}
+	headerRule := func(header gatewayv1.HTTPHeaderMatch, backend string) gatewayv1.HTTPRouteRule {
+		return gatewayv1.HTTPRouteRule{
+			Matches:     []gatewayv1.HTTPRouteMatch{{Path: &pathMatch, Headers: []gatewayv1.HTTPHeaderMatch{header}}},
+			BackendRefs: []gatewayv1.HTTPBackendRef{backendRef(backend, nil)},
+		}
+	}
+	exactHeader := func(name, value string) gatewayv1.HTTPHeaderMatch {
+		return gatewayv1.HTTPHeaderMatch{Type: ptrTo(gatewayv1.HeaderMatchExact), Name: gatewayv1.HTTPHeaderName(name), Value: value}
+	}
+	cookieHeader := func(value string) gatewayv1.HTTPHeaderMatch {
+		return gatewayv1.HTTPHeaderMatch{Type: ptrTo(gatewayv1.HeaderMatchRegularExpression), Name: "Cookie", Value: `^(.*;\s*)?feature_x=` + value + `(\s*;.*)?$`}
+	}
+	weightedRule := func(mainWeight, canaryWeight int32) gatewayv1.HTTPRouteRule {
+		return gatewayv1.HTTPRouteRule{
+			Matches:     []gatewayv1.HTTPRouteMatch{{Path: &pathMatch}},
+			BackendRefs: []gatewayv1.HTTPBackendRef{backendRef("canary", ptrTo(canaryWeight)), backendRef("main", ptrTo(mainWeight))},
+		}
+	}
+	ingressWithBackend := func(name, backend string, annotations map[string]string) networkingv1.Ingress {
+		ingress := testIngress(name, "example.com", "/", networkingv1.PathTypePrefix, annotations)
+		ingress.Spec.Rules[0].HTTP.Paths[0].Backend.Service.Name = backend
+		return ingress
+	}
+
+	testCases := []struct {
+		name                 string
+		canaryAnnotations    map[string]string
+		expectedRules        []gatewayv1.HTTPRouteRule
+		expectedNotification string
+	}{{
+		name: "cookie",
+		canaryAnnotations: map[string]string{
+			canaryAnnotation:         "true",
+			canaryByCookieAnnotation: "feature_x",
+		},
+		expectedRules: []gatewayv1.HTTPRouteRule{
+			headerRule(cookieHeader("always"), "canary"),
+			headerRule(cookieHeader("never"), "main"),
+			weightedRule(100, 0),
+		},
+		expectedNotification: "converted to regular expression matches of the Cookie header",
+	}, {
+		name: "header takes precedence over cookie and weight",
+		canaryAnnotations: map[string]string{
+			canaryAnnotation:         "true",
+			canaryByHeaderAnnotation: "X-Canary",
+			canaryByCookieAnnotation: "feature_x",
+			canaryWeightAnnotation:   "10",
+		},
+		expectedRules: []gatewayv1.HTTPRouteRule{
+			headerRule(exactHeader("X-Canary", "always"), "canary"),
+			headerRule(exactHeader("X-Canary", "never"), "main"),
+			headerRule(cookieHeader("always"), "canary"),
+			headerRule(cookieHeader("never"), "main"),
+			weightedRule(90, 10),
+		},
+	}, {
+		name: "header value",
+		canaryAnnotations: map[string]string{
+			canaryAnnotation:              "true",
+			canaryByHeaderAnnotation:      "X-Canary",
+			canaryByHeaderValueAnnotation: "yes",
+			canaryByHeaderPattern:         "ignored",
+		},
+		expectedRules: []gatewayv1.HTTPRouteRule{
+			headerRule(exactHeader("X-Canary", "yes"), "canary"),
+			weightedRule(100, 0),
+		},
+	}, {
+		name: "header pattern",
+		canaryAnnotations: map[string]string{
+			canaryAnnotation:         "true",
+			canaryByHeaderAnnotation: "X-Canary",
+			canaryByHeaderPattern:    "^(yes|true)$",
+		},
+		expectedRules: []gatewayv1.HTTPRouteRule{
+			headerRule(gatewayv1.HTTPHeaderMatch{Type: ptrTo(gatewayv1.HeaderMatchRegularExpression), Name: "X-Canary", Value: "^(yes|true)$"}, "canary"),
+			weightedRule(100, 0),
+		},
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			gatewayResources, notificationTable := convertTestIngresses(t, &i2gw.ProviderConf{},
+				ingressWithBackend("main", "main", nil),
+				ingressWithBackend("canary", "canary", tc.canaryAnnotations),
+			)
+
+			key := types.NamespacedName{Namespace: "default", Name: "canary-example-com"}
+			httpRoute, ok := gatewayResources.HTTPRoutes[key]
+			if !ok {
+				t.Fatalf("Expected HTTPRoute %s, got %+v", key, gatewayResources.HTTPRoutes)
+			}
+			if diff := cmp.Diff(tc.expectedRules, httpRoute.Spec.Rules); diff != "" {
+				t.Errorf("Unexpected rules, diff (-want +got):\n%s", diff)
+			}
+			if tc.expectedNotification != "" && !strings.Contains(notificationTable, tc.expectedNotification) {
+				t.Errorf("Expected notification %q, got:\n%s", tc.expectedNotification, notificationTable)
+			}
+		})
+	}
+}
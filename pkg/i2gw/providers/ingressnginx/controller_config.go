@@ -0,0 +1,200 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingressnginx
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/providers/common"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/utils/ptr"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+const (
+	// ControllerConfigMapFlag is the provider-specific flag naming the
+	// ConfigMap of the controller, as namespace/name.
+	ControllerConfigMapFlag = "controller-configmap"
+
+	sslRedirectKey                = "ssl-redirect"
+	hstsKey                       = "hsts"
+	hstsMaxAgeKey                 = "hsts-max-age"
+	hstsIncludeSubdomainsKey      = "hsts-include-subdomains"
+	hstsPreloadKey                = "hsts-preload"
+	proxyReadTimeoutKey           = "proxy-read-timeout"
+	useForwardedHeadersKey        = "use-forwarded-headers"
+	proxySetHeadersKey            = "proxy-set-headers"
+	enableUnderscoresInHeadersKey = "enable-underscores-in-headers"
+
+	hstsHeader = "Strict-Transport-Security"
+)
+
+// controllerConfig are the settings of the controller ConfigMap which apply
+// to every Ingress, unless overridden by their annotations. It is empty when
+// the ConfigMap is not given, in which case the defaults of the features are
+// used.
+type controllerConfig struct {
+	configMap *corev1.ConfigMap
+	// sslRedirect is the ssl-redirect setting, nil when not set.
+	sslRedirect *bool
+	// proxyReadTimeout is the proxy-read-timeout setting, in seconds, nil
+	// when not set.
+	proxyReadTimeout *int
+	// hsts is the Strict-Transport-Security header sent by nginx over HTTPS,
+	// empty when disabled.
+	hsts string
+	// proxySetHeaders are the headers of the proxy-set-headers ConfigMap,
+	// set on the requests to every backend.
+	proxySetHeaders []gatewayv1.HTTPHeader
+	// unconvertedHeaders are the headers of the proxy-set-headers ConfigMap
+	// whose value uses nginx variables, which cannot be converted.
+	unconvertedHeaders         []string
+	useForwardedHeaders        bool
+	enableUnderscoresInHeaders bool
+}
+
+// parseControllerConfig parses the controller ConfigMap, either of which may
+// be nil, with the ConfigMap of its proxy-set-headers setting.
+func parseControllerConfig(configMap, proxySetHeaders *corev1.ConfigMap) (controllerConfig, field.ErrorList) {
+	if configMap == nil {
+		return controllerConfig{}, nil
+	}
+
+	var errs field.ErrorList
+	fieldPath := field.NewPath(fmt.Sprintf("%s/%s", configMap.Namespace, configMap.Name), "data")
+	parseBool := func(key string, defaultValue bool) bool {
+		value, ok := configMap.Data[key]
+		if !ok {
+			return defaultValue
+		}
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			errs = append(errs, field.Invalid(fieldPath.Key(key), value, "must be true or false"))
+			return defaultValue
+		}
+		return parsed
+	}
+
+	config := controllerConfig{
+		configMap:                  configMap,
+		useForwardedHeaders:        parseBool(useForwardedHeadersKey, false),
+		enableUnderscoresInHeaders: parseBool(enableUnderscoresInHeadersKey, false),
+	}
+	if _, ok := configMap.Data[sslRedirectKey]; ok {
+		config.sslRedirect = ptr.To(parseBool(sslRedirectKey, true))
+	}
+	if value, ok := configMap.Data[proxyReadTimeoutKey]; ok {
+		seconds, err := strconv.Atoi(value)
+		if err != nil || seconds <= 0 {
+			errs = append(errs, field.Invalid(fieldPath.Key(proxyReadTimeoutKey), value, "must be a positive number of seconds"))
+		} else {
+			config.proxyReadTimeout = &seconds
+		}
+	}
+
+	// Like ingress-nginx, HSTS is enabled by default, for a year, including
+	// the subdomains.
+	if parseBool(hstsKey, true) {
+		maxAge := "31536000"
+		if value, ok := configMap.Data[hstsMaxAgeKey]; ok {
+			if seconds, err := strconv.Atoi(value); err != nil || seconds < 0 {
+				errs = append(errs, field.Invalid(fieldPath.Key(hstsMaxAgeKey), value, "must be a number of seconds"))
+			} else {
+				maxAge = value
+			}
+		}
+		config.hsts = fmt.Sprintf("max-age=%s", maxAge)
+		if parseBool(hstsIncludeSubdomainsKey, true) {
+			config.hsts += "; includeSubDomains"
+		}
+		if parseBool(hstsPreloadKey, false) {
+			config.hsts += "; preload"
+		}
+	}
+
+	if proxySetHeaders != nil {
+		config.proxySetHeaders, config.unconvertedHeaders = headersFromConfigMap(proxySetHeaders)
+	}
+
+	return config, errs
+}
+
+// feature applies the proxy-set-headers and HSTS settings of the controller
+// ConfigMap to every HTTPRoute, and reports the settings which cannot be
+// converted. It must run before the features converting the header
+// annotations, which override the headers set by the controller.
+func (c *controllerConfig) feature(ingresses []networkingv1.Ingress, gatewayResources *i2gw.GatewayResources) field.ErrorList {
+	if c.configMap == nil {
+		return nil
+	}
+	c.notifyUnconvertedSettings()
+
+	for _, rg := range sortedRuleGroups(ingresses) {
+		key := types.NamespacedName{Namespace: rg.Namespace, Name: common.RouteName(rg.Name, rg.Host)}
+		httpRoute, ok := gatewayResources.HTTPRoutes[key]
+		if !ok {
+			continue
+		}
+
+		// nginx only sends the HSTS header over HTTPS, which it does not
+		// terminate for passthrough hosts.
+		var responseHeaders []gatewayv1.HTTPHeader
+		_, passthrough := sslPassthroughIngress(rg)
+		if c.hsts != "" && len(rg.TLS) > 0 && !passthrough {
+			responseHeaders = append(responseHeaders, gatewayv1.HTTPHeader{Name: hstsHeader, Value: c.hsts})
+		}
+		for i := range httpRoute.Spec.Rules {
+			common.SetRuleHeaders(&httpRoute.Spec.Rules[i], gatewayv1.HTTPRouteFilterRequestHeaderModifier, c.proxySetHeaders)
+			common.SetRuleHeaders(&httpRoute.Spec.Rules[i], gatewayv1.HTTPRouteFilterResponseHeaderModifier, responseHeaders)
+		}
+		gatewayResources.HTTPRoutes[key] = httpRoute
+	}
+
+	return nil
+}
+
+func (c *controllerConfig) notifyUnconvertedSettings() {
+	if len(c.unconvertedHeaders) > 0 {
+		notifications.NotificationAggr.DispatchNotification(notifications.NewNotification(
+			notifications.WarningNotification,
+			fmt.Sprintf("headers %s of the %s setting use nginx variables, which cannot be converted and must be configured with the Gateway implementation",
+				strings.Join(c.unconvertedHeaders, ", "), proxySetHeadersKey),
+			c.configMap,
+		), Name)
+	}
+	if c.useForwardedHeaders {
+		notifications.NotificationAggr.DispatchNotification(notifications.NewNotification(
+			notifications.WarningNotification,
+			fmt.Sprintf("%s is enabled: trusting the X-Forwarded-* headers of the clients has no Gateway API equivalent, and must be configured with the Gateway implementation", useForwardedHeadersKey),
+			c.configMap,
+		), Name)
+	}
+	if !c.enableUnderscoresInHeaders {
+		notifications.NotificationAggr.DispatchNotification(notifications.NewNotification(
+			notifications.InfoNotification,
+			fmt.Sprintf("%s is disabled: nginx drops the request headers with underscores, which Gateway implementations may forward to the backends unless configured otherwise", enableUnderscoresInHeadersKey),
+			c.configMap,
+		), Name)
+	}
+}
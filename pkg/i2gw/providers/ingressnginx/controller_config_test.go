@@ -0,0 +1,180 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingressnginx
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+func Test_controllerConfig(t *testing.T) {
+	withTLS := func(ingress networkingv1.Ingress) networkingv1.Ingress {
+		ingress.Spec.TLS = []networkingv1.IngressTLS{{Hosts: []string{"example.com"}, SecretName: "example-com-tls"}}
+		return ingress
+	}
+	proxySetHeaders := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ingress-nginx", Name: "custom-headers"},
+		Data: map[string]string{
+			"X-Environment":  "production",
+			"X-Request-Host": "$host",
+		},
+	}
+
+	testCases := []struct {
+		name                  string
+		data                  map[string]string
+		ingresses             []networkingv1.Ingress
+		expectedTimeouts      *gatewayv1.HTTPRouteTimeouts
+		expectedFilters       []gatewayv1.HTTPRouteFilter
+		expectRedirect        bool
+		expectedNotifications []string
+		expectedErrors        int
+	}{{
+		name: "defaults",
+		ingresses: []networkingv1.Ingress{
+			withTLS(testIngress("a", "example.com", "/", networkingv1.PathTypePrefix, nil)),
+		},
+		expectedFilters: []gatewayv1.HTTPRouteFilter{{
+			Type: gatewayv1.HTTPRouteFilterResponseHeaderModifier,
+			ResponseHeaderModifier: &gatewayv1.HTTPHeaderFilter{
+				Set: []gatewayv1.HTTPHeader{{Name: hstsHeader, Value: "max-age=31536000; includeSubDomains"}},
+			},
+		}},
+		expectRedirect:        true,
+		expectedNotifications: []string{"nginx drops the request headers with underscores"},
+	}, {
+		name: "settings",
+		data: map[string]string{
+			sslRedirectKey:                "false",
+			hstsMaxAgeKey:                 "600",
+			hstsIncludeSubdomainsKey:      "false",
+			hstsPreloadKey:                "true",
+			proxyReadTimeoutKey:           "120",
+			useForwardedHeadersKey:        "true",
+			proxySetHeadersKey:            "ingress-nginx/custom-headers",
+			enableUnderscoresInHeadersKey: "true",
+		},
+		ingresses: []networkingv1.Ingress{
+			withTLS(testIngress("a", "example.com", "/", networkingv1.PathTypePrefix, nil)),
+		},
+		expectedTimeouts: &gatewayv1.HTTPRouteTimeouts{
+			Request:        ptrTo(gatewayv1.Duration("120s")),
+			BackendRequest: ptrTo(gatewayv1.Duration("120s")),
+		},
+		expectedFilters: []gatewayv1.HTTPRouteFilter{{
+			Type: gatewayv1.HTTPRouteFilterRequestHeaderModifier,
+			RequestHeaderModifier: &gatewayv1.HTTPHeaderFilter{
+				Set: []gatewayv1.HTTPHeader{{Name: "X-Environment", Value: "production"}},
+			},
+		}, {
+			Type: gatewayv1.HTTPRouteFilterResponseHeaderModifier,
+			ResponseHeaderModifier: &gatewayv1.HTTPHeaderFilter{
+				Set: []gatewayv1.HTTPHeader{{Name: hstsHeader, Value: "max-age=600; preload"}},
+			},
+		}},
+		expectedNotifications: []string{
+			"headers X-Request-Host of the proxy-set-headers setting use nginx variables",
+			"use-forwarded-headers is enabled",
+			"the proxy-read-timeout 120s setting of the controller ConfigMap is converted to request and backendRequest timeouts",
+		},
+	}, {
+		name: "annotations override the settings",
+		data: map[string]string{
+			sslRedirectKey:      "false",
+			hstsKey:             "false",
+			proxyReadTimeoutKey: "120",
+		},
+		ingresses: []networkingv1.Ingress{
+			withTLS(testIngress("a", "example.com", "/", networkingv1.PathTypePrefix, map[string]string{
+				sslRedirectAnnotation:      "true",
+				proxyReadTimeoutAnnotation: "30",
+			})),
+		},
+		expectedTimeouts: &gatewayv1.HTTPRouteTimeouts{
+			Request:        ptrTo(gatewayv1.Duration("30s")),
+			BackendRequest: ptrTo(gatewayv1.Duration("30s")),
+		},
+		expectRedirect: true,
+	}, {
+		name: "invalid settings",
+		data: map[string]string{
+			hstsKey:             "yes",
+			proxyReadTimeoutKey: "60s",
+		},
+		ingresses: []networkingv1.Ingress{
+			testIngress("a", "example.com", "/", networkingv1.PathTypePrefix, nil),
+		},
+		expectedErrors: 2,
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			provider := NewProvider(&i2gw.ProviderConf{}).(*Provider)
+			ingressMap := map[types.NamespacedName]*networkingv1.Ingress{}
+			for i := range tc.ingresses {
+				ingressMap[types.NamespacedName{Namespace: tc.ingresses[i].Namespace, Name: tc.ingresses[i].Name}] = &tc.ingresses[i]
+			}
+			provider.storage.Ingresses.FromMap(ingressMap)
+			provider.storage.ControllerConfigMap = &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "ingress-nginx", Name: "ingress-nginx-controller"},
+				Data:       tc.data,
+			}
+			if tc.data[proxySetHeadersKey] != "" {
+				provider.storage.ProxySetHeaders = proxySetHeaders
+			}
+
+			gatewayResources, errs := provider.ToGatewayAPI()
+			if len(errs) != tc.expectedErrors {
+				t.Fatalf("Expected %d errors, got %+v", tc.expectedErrors, errs)
+			}
+			if tc.expectedErrors > 0 {
+				return
+			}
+			notificationTable := notifications.NotificationAggr.CreateNotificationTables()[Name]
+
+			key := types.NamespacedName{Namespace: "default", Name: "a-example-com"}
+			httpRoute, ok := gatewayResources.HTTPRoutes[key]
+			if !ok || len(httpRoute.Spec.Rules) != 1 {
+				t.Fatalf("Expected HTTPRoute %s with a single rule, got %+v", key, gatewayResources.HTTPRoutes)
+			}
+			if diff := cmp.Diff(tc.expectedTimeouts, httpRoute.Spec.Rules[0].Timeouts); diff != "" {
+				t.Errorf("Unexpected timeouts, diff (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.expectedFilters, httpRoute.Spec.Rules[0].Filters); diff != "" {
+				t.Errorf("Unexpected filters, diff (-want +got):\n%s", diff)
+			}
+			_, redirected := gatewayResources.HTTPRoutes[types.NamespacedName{Namespace: "default", Name: "a-example-com-http"}]
+			if redirected != tc.expectRedirect {
+				t.Errorf("Expected redirect %t, got %t", tc.expectRedirect, redirected)
+			}
+			for _, expected := range tc.expectedNotifications {
+				if !strings.Contains(notificationTable, expected) {
+					t.Errorf("Expected notification %q, got:\n%s", expected, notificationTable)
+				}
+			}
+		})
+	}
+}
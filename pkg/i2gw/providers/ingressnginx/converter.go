@@ -17,33 +17,89 @@ limitations under the License.
 package ingressnginx
 
 import (
+	"sort"
+
 	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw"
 	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/providers/common"
+	networkingv1 "k8s.io/api/networking/v1"
 	"k8s.io/apimachinery/pkg/util/validation/field"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
 )
 
 // converter implements the ToGatewayAPI function of i2gw.ResourceConverter interface.
 type converter struct {
-	featureParsers []i2gw.FeatureParser
+	featureParsers                []i2gw.FeatureParser
+	implementationSpecificOptions i2gw.ProviderImplementationSpecificOptions
+	backendProtocol               *backendProtocol
+	controllerConfig              *controllerConfig
+	headers                       *headers
+	// targetImplementation is the value of TargetImplementationFlag.
+	targetImplementation string
 }
 
 // newConverter returns an ingress-nginx converter instance.
-func newConverter() *converter {
-	return &converter{
+func newConverter(conf *i2gw.ProviderConf) *converter {
+	config := &controllerConfig{}
+	targetImplementation := conf.ProviderSpecificFlags[Name][TargetImplementationFlag]
+	sslRedirect := newSSLRedirect(conf, config)
+	policies := newRoutePolicies(sslRedirect)
+	auth := newAuth(conf, targetImplementation, policies)
+	backendProtocol := &backendProtocol{allowUnprotected: conf.ProviderSpecificFlags[Name][AllowUnprotectedRoutesFlag]}
+	headers := &headers{}
+	c := &converter{
 		featureParsers: []i2gw.FeatureParser{
 			canaryFeature,
+			config.feature,
+			headers.feature,
+			sslPassthroughFeature,
+			backendProtocol.feature,
+			timeoutsFeature(config),
+			mirrorFeature,
+			corsFeature(targetImplementation, policies),
+			snippetFeature,
+			redirectFeature,
+			auth.feature,
+			rateLimitFeature(targetImplementation, policies),
+			sessionAffinityFeature(targetImplementation, policies),
+			loadBalanceFeature(targetImplementation, policies),
+			sslRedirect.planFeature,
+			rewriteFeature,
+			regexFeature,
+			sslRedirect.redirectFeature,
+			policies.feature,
+		},
+		implementationSpecificOptions: i2gw.ProviderImplementationSpecificOptions{
+			ToImplementationSpecificHTTPPathTypeMatch: implementationSpecificHTTPPathTypeMatch,
 		},
+		backendProtocol:      backendProtocol,
+		controllerConfig:     config,
+		headers:              headers,
+		targetImplementation: targetImplementation,
 	}
+	if conf.PreserveIngressAddresses {
+		c.featureParsers = append(c.featureParsers, common.IngressStatusAddressesFeature(Name))
+	}
+	return c
 }
 
 func (c *converter) convert(storage *storage) (i2gw.GatewayResources, field.ErrorList) {
 
 	// TODO(liorliberman) temporary until we decide to change ToGateway and featureParsers to get a map of [types.NamespacedName]*networkingv1.Ingress instead of a list
 	ingressList := storage.Ingresses.List()
+	if errs := validateTargetImplementation(c.targetImplementation); len(errs) > 0 {
+		return i2gw.GatewayResources{}, errs
+	}
+	c.backendProtocol.services = storage.Services
+	c.headers.configMaps = storage.ConfigMaps
+	config, errs := parseControllerConfig(storage.ControllerConfigMap, storage.ProxySetHeaders)
+	if len(errs) > 0 {
+		return i2gw.GatewayResources{}, errs
+	}
+	*c.controllerConfig = config
 
 	// Convert plain ingress resources to gateway resources, ignoring all
 	// provider-specific features.
-	gatewayResources, errs := common.ToGateway(ingressList, i2gw.ProviderImplementationSpecificOptions{})
+	gatewayResources, errs := common.ToGateway(ingressList, c.implementationSpecificOptions)
 	if len(errs) > 0 {
 		return i2gw.GatewayResources{}, errs
 	}
@@ -55,5 +111,80 @@ func (c *converter) convert(storage *storage) (i2gw.GatewayResources, field.Erro
 		errs = append(errs, parseErrs...)
 	}
 
+	errs = append(errs, streamServicesFeature(storage.TCPServices, storage.UDPServices, storage.Services)(ingressList, &gatewayResources)...)
+
+	if storage.ControllerService != nil {
+		errs = append(errs, common.ControllerServiceFeature(Name, storage.ControllerService)(ingressList, &gatewayResources)...)
+	}
+
 	return gatewayResources, errs
 }
+
+// ruleSource is an Ingress path converted to an HTTPRoute rule.
+type ruleSource struct {
+	ingress networkingv1.Ingress
+	path    networkingv1.HTTPIngressPath
+}
+
+// sortedRuleGroups returns the rule groups of the Ingresses, sorted by key so
+// that features are applied, and notifications dispatched, in a stable order.
+func sortedRuleGroups(ingresses []networkingv1.Ingress) []common.IngressRuleGroup {
+	ruleGroups := common.GetRuleGroups(ingresses)
+	rgKeys := make([]string, 0, len(ruleGroups))
+	for rgKey := range ruleGroups {
+		rgKeys = append(rgKeys, rgKey)
+	}
+	sort.Strings(rgKeys)
+
+	sorted := make([]common.IngressRuleGroup, 0, len(rgKeys))
+	for _, rgKey := range rgKeys {
+		sorted = append(sorted, ruleGroups[rgKey])
+	}
+	return sorted
+}
+
+// ruleSourcesByIndex returns the Ingress paths of the rule group by the index
+// of the HTTPRoute rule they were converted to. The rules are found by their
+// path match, so this must be used before the matches are changed by
+// rewriteFeature and regexFeature.
+func ruleSourcesByIndex(rg common.IngressRuleGroup, httpRoute gatewayv1.HTTPRoute) map[int][]ruleSource {
+	sourcesByRule := map[int][]ruleSource{}
+	for _, rule := range rg.Rules {
+		if rule.IngressRule.HTTP == nil {
+			continue
+		}
+		for _, path := range rule.IngressRule.HTTP.Paths {
+			for _, ruleIdx := range findHTTPRouteRules(httpRoute, path) {
+				sourcesByRule[ruleIdx] = append(sourcesByRule[ruleIdx], ruleSource{ingress: rule.Ingress, path: path})
+			}
+		}
+	}
+	return sourcesByRule
+}
+
+// sortedRuleIndexes returns the indexes of the rules of sourcesByRule in
+// increasing order.
+func sortedRuleIndexes(sourcesByRule map[int][]ruleSource) []int {
+	ruleIdxs := make([]int, 0, len(sourcesByRule))
+	for ruleIdx := range sourcesByRule {
+		ruleIdxs = append(ruleIdxs, ruleIdx)
+	}
+	sort.Ints(ruleIdxs)
+	return ruleIdxs
+}
+
+// annotationSources returns the sources whose annotations configure the rule.
+// ingress-nginx ignores the annotations of canary Ingresses sharing the path
+// of a main Ingress, except for the canary ones.
+func annotationSources(sources []ruleSource) []ruleSource {
+	var mainSources []ruleSource
+	for _, source := range sources {
+		if source.ingress.Annotations[canaryAnnotation] != "true" {
+			mainSources = append(mainSources, source)
+		}
+	}
+	if len(mainSources) == 0 {
+		return sources
+	}
+	return mainSources
+}
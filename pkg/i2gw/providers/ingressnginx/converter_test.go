@@ -29,16 +29,16 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/validation/field"
-	"k8s.io/utils/ptr"
 	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
 )
 
 func Test_ToGateway(t *testing.T) {
 	iPrefix := networkingv1.PathTypePrefix
-	//iExact := networkingv1.PathTypeExact
+	iExact := networkingv1.PathTypeExact
 	isPathType := networkingv1.PathTypeImplementationSpecific
 	gPathPrefix := gatewayv1.PathMatchPathPrefix
-	//gExact := gatewayv1.PathMatchExact
+	gExact := gatewayv1.PathMatchExact
+	gRegex := gatewayv1.PathMatchRegularExpression
 
 	testCases := []struct {
 		name                     string
@@ -204,13 +204,316 @@ func Test_ToGateway(t *testing.T) {
 					},
 				},
 			},
-			expectedGatewayResources: i2gw.GatewayResources{},
+			expectedGatewayResources: i2gw.GatewayResources{
+				Gateways: map[types.NamespacedName]gatewayv1.Gateway{
+					{Namespace: "default", Name: "ingress-nginx"}: {
+						ObjectMeta: metav1.ObjectMeta{Name: "ingress-nginx", Namespace: "default"},
+						Spec: gatewayv1.GatewaySpec{
+							GatewayClassName: "ingress-nginx",
+							Listeners: []gatewayv1.Listener{{
+								Name:     "test-mydomain-com-http",
+								Port:     80,
+								Protocol: gatewayv1.HTTPProtocolType,
+								Hostname: ptrTo(gatewayv1.Hostname("test.mydomain.com")),
+							}},
+						},
+					},
+				},
+				HTTPRoutes: map[types.NamespacedName]gatewayv1.HTTPRoute{
+					{Namespace: "default", Name: "implementation-specific-regex-test-mydomain-com"}: {
+						ObjectMeta: metav1.ObjectMeta{Name: "implementation-specific-regex-test-mydomain-com", Namespace: "default"},
+						Spec: gatewayv1.HTTPRouteSpec{
+							CommonRouteSpec: gatewayv1.CommonRouteSpec{
+								ParentRefs: []gatewayv1.ParentReference{{
+									Name: "ingress-nginx",
+								}},
+							},
+							Hostnames: []gatewayv1.Hostname{"test.mydomain.com"},
+							Rules: []gatewayv1.HTTPRouteRule{{
+								Matches: []gatewayv1.HTTPRouteMatch{{
+									Path: &gatewayv1.HTTPPathMatch{
+										Type:  &gPathPrefix,
+										Value: ptrTo("/~/echo/**/test"),
+									},
+								}},
+								BackendRefs: []gatewayv1.HTTPBackendRef{{
+									BackendRef: gatewayv1.BackendRef{
+										BackendObjectReference: gatewayv1.BackendObjectReference{
+											Name: "test",
+											Port: ptrTo(gatewayv1.PortNumber(80)),
+										},
+									},
+								}},
+							}},
+						},
+					},
+				},
+			},
+			expectedErrors: field.ErrorList{},
+		},
+		{
+			name: "use-regex applies to every ingress of the host",
+			ingresses: OrderedIngressMap{
+				ingressNames: []types.NamespacedName{{Namespace: "default", Name: "regex"}, {Namespace: "default", Name: "plain"}, {Namespace: "default", Name: "exact"}},
+				ingressObjects: map[types.NamespacedName]*networkingv1.Ingress{
+					{Namespace: "default", Name: "regex"}: {
+						ObjectMeta: metav1.ObjectMeta{
+							Name:      "regex",
+							Namespace: "default",
+							Annotations: map[string]string{
+								"nginx.ingress.kubernetes.io/use-regex": "true",
+							},
+						},
+						Spec: networkingv1.IngressSpec{
+							IngressClassName: ptrTo("ingress-nginx"),
+							Rules: []networkingv1.IngressRule{{
+								Host: "test.mydomain.com",
+								IngressRuleValue: networkingv1.IngressRuleValue{
+									HTTP: &networkingv1.HTTPIngressRuleValue{
+										Paths: []networkingv1.HTTPIngressPath{{
+											Path:     "/api/v[0-9]+",
+											PathType: &isPathType,
+											Backend: networkingv1.IngressBackend{
+												Service: &networkingv1.IngressServiceBackend{
+													Name: "test",
+													Port: networkingv1.ServiceBackendPort{
+														Number: 80,
+													},
+												},
+											},
+										}},
+									},
+								},
+							}},
+						},
+					},
+					{Namespace: "default", Name: "plain"}: {
+						ObjectMeta: metav1.ObjectMeta{
+							Name:      "plain",
+							Namespace: "default",
+						},
+						Spec: networkingv1.IngressSpec{
+							IngressClassName: ptrTo("ingress-nginx"),
+							Rules: []networkingv1.IngressRule{{
+								Host: "test.mydomain.com",
+								IngressRuleValue: networkingv1.IngressRuleValue{
+									HTTP: &networkingv1.HTTPIngressRuleValue{
+										Paths: []networkingv1.HTTPIngressPath{{
+											Path:     "/static",
+											PathType: &iPrefix,
+											Backend: networkingv1.IngressBackend{
+												Service: &networkingv1.IngressServiceBackend{
+													Name: "test",
+													Port: networkingv1.ServiceBackendPort{
+														Number: 80,
+													},
+												},
+											},
+										}},
+									},
+								},
+							}},
+						},
+					},
+					{Namespace: "default", Name: "exact"}: {
+						ObjectMeta: metav1.ObjectMeta{
+							Name:      "exact",
+							Namespace: "default",
+						},
+						Spec: networkingv1.IngressSpec{
+							IngressClassName: ptrTo("ingress-nginx"),
+							Rules: []networkingv1.IngressRule{{
+								Host: "test.mydomain.com",
+								IngressRuleValue: networkingv1.IngressRuleValue{
+									HTTP: &networkingv1.HTTPIngressRuleValue{
+										Paths: []networkingv1.HTTPIngressPath{{
+											Path:     "/health$",
+											PathType: &iExact,
+											Backend: networkingv1.IngressBackend{
+												Service: &networkingv1.IngressServiceBackend{
+													Name: "test",
+													Port: networkingv1.ServiceBackendPort{
+														Number: 80,
+													},
+												},
+											},
+										}},
+									},
+								},
+							}},
+						},
+					},
+				},
+			},
+			expectedGatewayResources: i2gw.GatewayResources{
+				Gateways: map[types.NamespacedName]gatewayv1.Gateway{
+					{Namespace: "default", Name: "ingress-nginx"}: {
+						ObjectMeta: metav1.ObjectMeta{Name: "ingress-nginx", Namespace: "default"},
+						Spec: gatewayv1.GatewaySpec{
+							GatewayClassName: "ingress-nginx",
+							Listeners: []gatewayv1.Listener{{
+								Name:     "test-mydomain-com-http",
+								Port:     80,
+								Protocol: gatewayv1.HTTPProtocolType,
+								Hostname: ptrTo(gatewayv1.Hostname("test.mydomain.com")),
+							}},
+						},
+					},
+				},
+				HTTPRoutes: map[types.NamespacedName]gatewayv1.HTTPRoute{
+					{Namespace: "default", Name: "regex-test-mydomain-com"}: {
+						ObjectMeta: metav1.ObjectMeta{Name: "regex-test-mydomain-com", Namespace: "default"},
+						Spec: gatewayv1.HTTPRouteSpec{
+							CommonRouteSpec: gatewayv1.CommonRouteSpec{
+								ParentRefs: []gatewayv1.ParentReference{{
+									Name: "ingress-nginx",
+								}},
+							},
+							Hostnames: []gatewayv1.Hostname{"test.mydomain.com"},
+							Rules: []gatewayv1.HTTPRouteRule{
+								{
+									Matches: []gatewayv1.HTTPRouteMatch{{
+										Path: &gatewayv1.HTTPPathMatch{
+											Type:  &gRegex,
+											Value: ptrTo("(?i)/api/v[0-9]+.*"),
+										},
+									}},
+									BackendRefs: []gatewayv1.HTTPBackendRef{{
+										BackendRef: gatewayv1.BackendRef{
+											BackendObjectReference: gatewayv1.BackendObjectReference{
+												Name: "test",
+												Port: ptrTo(gatewayv1.PortNumber(80)),
+											},
+										},
+									}},
+								},
+								{
+									Matches: []gatewayv1.HTTPRouteMatch{{
+										Path: &gatewayv1.HTTPPathMatch{
+											Type:  &gRegex,
+											Value: ptrTo("(?i)/static.*"),
+										},
+									}},
+									BackendRefs: []gatewayv1.HTTPBackendRef{{
+										BackendRef: gatewayv1.BackendRef{
+											BackendObjectReference: gatewayv1.BackendObjectReference{
+												Name: "test",
+												Port: ptrTo(gatewayv1.PortNumber(80)),
+											},
+										},
+									}},
+								},
+								{
+									Matches: []gatewayv1.HTTPRouteMatch{{
+										Path: &gatewayv1.HTTPPathMatch{
+											Type:  &gExact,
+											Value: ptrTo("/health$"),
+										},
+									}},
+									BackendRefs: []gatewayv1.HTTPBackendRef{{
+										BackendRef: gatewayv1.BackendRef{
+											BackendObjectReference: gatewayv1.BackendObjectReference{
+												Name: "test",
+												Port: ptrTo(gatewayv1.PortNumber(80)),
+											},
+										},
+									}},
+								},
+							},
+						},
+					},
+				},
+			},
+			expectedErrors: field.ErrorList{},
+		},
+		{
+			name: "use-regex with an invalid regular expression",
+			ingresses: OrderedIngressMap{
+				ingressNames: []types.NamespacedName{{Namespace: "default", Name: "regex"}},
+				ingressObjects: map[types.NamespacedName]*networkingv1.Ingress{
+					{Namespace: "default", Name: "regex"}: {
+						ObjectMeta: metav1.ObjectMeta{
+							Name:      "regex",
+							Namespace: "default",
+							Annotations: map[string]string{
+								"nginx.ingress.kubernetes.io/use-regex": "true",
+							},
+						},
+						Spec: networkingv1.IngressSpec{
+							IngressClassName: ptrTo("ingress-nginx"),
+							Rules: []networkingv1.IngressRule{{
+								Host: "test.mydomain.com",
+								IngressRuleValue: networkingv1.IngressRuleValue{
+									HTTP: &networkingv1.HTTPIngressRuleValue{
+										Paths: []networkingv1.HTTPIngressPath{{
+											Path:     "/(?=lookahead)",
+											PathType: &iPrefix,
+											Backend: networkingv1.IngressBackend{
+												Service: &networkingv1.IngressServiceBackend{
+													Name: "test",
+													Port: networkingv1.ServiceBackendPort{
+														Number: 80,
+													},
+												},
+											},
+										}},
+									},
+								},
+							}},
+						},
+					},
+				},
+			},
+			expectedGatewayResources: i2gw.GatewayResources{
+				Gateways: map[types.NamespacedName]gatewayv1.Gateway{
+					{Namespace: "default", Name: "ingress-nginx"}: {
+						ObjectMeta: metav1.ObjectMeta{Name: "ingress-nginx", Namespace: "default"},
+						Spec: gatewayv1.GatewaySpec{
+							GatewayClassName: "ingress-nginx",
+							Listeners: []gatewayv1.Listener{{
+								Name:     "test-mydomain-com-http",
+								Port:     80,
+								Protocol: gatewayv1.HTTPProtocolType,
+								Hostname: ptrTo(gatewayv1.Hostname("test.mydomain.com")),
+							}},
+						},
+					},
+				},
+				HTTPRoutes: map[types.NamespacedName]gatewayv1.HTTPRoute{
+					{Namespace: "default", Name: "regex-test-mydomain-com"}: {
+						ObjectMeta: metav1.ObjectMeta{Name: "regex-test-mydomain-com", Namespace: "default"},
+						Spec: gatewayv1.HTTPRouteSpec{
+							CommonRouteSpec: gatewayv1.CommonRouteSpec{
+								ParentRefs: []gatewayv1.ParentReference{{
+									Name: "ingress-nginx",
+								}},
+							},
+							Hostnames: []gatewayv1.Hostname{"test.mydomain.com"},
+							Rules: []gatewayv1.HTTPRouteRule{{
+								Matches: []gatewayv1.HTTPRouteMatch{{
+									Path: &gatewayv1.HTTPPathMatch{
+										Type:  &gPathPrefix,
+										Value: ptrTo("/(?=lookahead)"),
+									},
+								}},
+								BackendRefs: []gatewayv1.HTTPBackendRef{{
+									BackendRef: gatewayv1.BackendRef{
+										BackendObjectReference: gatewayv1.BackendObjectReference{
+											Name: "test",
+											Port: ptrTo(gatewayv1.PortNumber(80)),
+										},
+									},
+								}},
+							}},
+						},
+					},
+				},
+			},
 			expectedErrors: field.ErrorList{
 				{
 					Type:     field.ErrorTypeInvalid,
-					Field:    "spec.rules[0].http.paths[0].pathType",
-					BadValue: ptr.To("ImplementationSpecific"),
-					Detail:   "implementationSpecific path type is not supported in generic translation, and your provider does not provide custom support to translate it",
+					Field:    "HTTPRoute.default.regex-test-mydomain-com.spec.rules[0].matches[0].path",
+					BadValue: "/(?=lookahead)",
 				},
 			},
 		},
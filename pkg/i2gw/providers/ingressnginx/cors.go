@@ -0,0 +1,191 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingressnginx
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+const (
+	enableCORSAnnotation           = "nginx.ingress.kubernetes.io/enable-cors"
+	corsAllowOriginAnnotation      = "nginx.ingress.kubernetes.io/cors-allow-origin"
+	corsAllowMethodsAnnotation     = "nginx.ingress.kubernetes.io/cors-allow-methods"
+	corsAllowHeadersAnnotation     = "nginx.ingress.kubernetes.io/cors-allow-headers"
+	corsExposeHeadersAnnotation    = "nginx.ingress.kubernetes.io/cors-expose-headers"
+	corsAllowCredentialsAnnotation = "nginx.ingress.kubernetes.io/cors-allow-credentials"
+	corsMaxAgeAnnotation           = "nginx.ingress.kubernetes.io/cors-max-age"
+
+	// The defaults of ingress-nginx.
+	defaultCORSAllowOrigin  = "*"
+	defaultCORSAllowMethods = "GET, PUT, POST, DELETE, PATCH, OPTIONS"
+	defaultCORSAllowHeaders = "DNT,Keep-Alive,User-Agent,X-Requested-With,If-Modified-Since,Cache-Control,Content-Type,Range,Authorization"
+	defaultCORSMaxAge       = 1728000
+)
+
+// ingressCORS is the CORS configuration of an Ingress.
+type ingressCORS struct {
+	allowOrigins     []string
+	allowMethods     []string
+	allowHeaders     []string
+	exposeHeaders    []string
+	allowCredentials bool
+	// maxAge is in seconds.
+	maxAge int
+}
+
+// corsFeature converts the CORS annotations.
+//
+// The Gateway API has no CORS filter, so they are converted to the CORS of
+// the SecurityPolicy of the rules of each Ingress, recorded in routePolicies,
+// with the envoy-gateway target implementation. Without a target
+// implementation, the CORS of each Ingress is reported, to be configured
+// manually.
+func corsFeature(target string, policies *routePolicies) i2gw.FeatureParser {
+	return func(ingresses []networkingv1.Ingress, gatewayResources *i2gw.GatewayResources) field.ErrorList {
+		var errs field.ErrorList
+		corsByIngress := map[types.NamespacedName]*ingressCORS{}
+		for i := range ingresses {
+			cors, parseErrs := parseCORS(ingresses[i])
+			errs = append(errs, parseErrs...)
+			if cors == nil {
+				continue
+			}
+			corsByIngress[types.NamespacedName{Namespace: ingresses[i].Namespace, Name: ingresses[i].Name}] = cors
+			if target == "" {
+				notifyManualAction(&ingresses[i], "CORS is unsupported by the Gateway API", cors)
+			}
+		}
+		if len(errs) > 0 || target == "" {
+			return errs
+		}
+
+		converted := map[types.NamespacedName]bool{}
+		forEachRuleSource(ingresses, gatewayResources, false, func(key types.NamespacedName, ruleIdx int, source ruleSource) {
+			ingressKey := types.NamespacedName{Namespace: source.ingress.Namespace, Name: source.ingress.Name}
+			cors := corsByIngress[ingressKey]
+			if cors == nil {
+				return
+			}
+			policies.set(key, ruleIdx, source, envoyGatewaySecurityPolicyGVK, "cors", cors.envoyGatewayCORS())
+			if !converted[ingressKey] {
+				converted[ingressKey] = true
+				notifications.NotificationAggr.DispatchNotification(notifications.NewNotification(
+					notifications.InfoNotification,
+					fmt.Sprintf("the CORS of %s/%s are converted to the SecurityPolicy of the HTTPRoutes of its rules", ingressKey.Namespace, ingressKey.Name),
+					&source.ingress,
+				), Name)
+			}
+		})
+
+		return nil
+	}
+}
+
+// parseCORS returns the CORS of the Ingress, with the defaults of
+// ingress-nginx, nil when CORS is not enabled.
+func parseCORS(ingress networkingv1.Ingress) (*ingressCORS, field.ErrorList) {
+	if enabled, err := strconv.ParseBool(ingress.Annotations[enableCORSAnnotation]); err != nil || !enabled {
+		return nil, nil
+	}
+
+	var errs field.ErrorList
+	fieldPath := field.NewPath(ingress.Name).Child("metadata").Child("annotations")
+	annotation := func(name, defaultValue string) string {
+		if value, ok := ingress.Annotations[name]; ok {
+			return value
+		}
+		return defaultValue
+	}
+
+	cors := &ingressCORS{
+		allowOrigins:     splitList(annotation(corsAllowOriginAnnotation, defaultCORSAllowOrigin)),
+		allowMethods:     splitList(annotation(corsAllowMethodsAnnotation, defaultCORSAllowMethods)),
+		allowHeaders:     splitList(annotation(corsAllowHeadersAnnotation, defaultCORSAllowHeaders)),
+		exposeHeaders:    splitList(annotation(corsExposeHeadersAnnotation, "")),
+		allowCredentials: true,
+		maxAge:           defaultCORSMaxAge,
+	}
+	if value, ok := ingress.Annotations[corsAllowCredentialsAnnotation]; ok {
+		allowCredentials, err := strconv.ParseBool(value)
+		if err != nil {
+			errs = append(errs, field.Invalid(fieldPath.Key(corsAllowCredentialsAnnotation), value, "must be true or false"))
+		}
+		cors.allowCredentials = allowCredentials
+	}
+	if value, ok := ingress.Annotations[corsMaxAgeAnnotation]; ok {
+		maxAge, err := strconv.Atoi(value)
+		if err != nil || maxAge < 0 {
+			errs = append(errs, field.Invalid(fieldPath.Key(corsMaxAgeAnnotation), value, "must be a number of seconds"))
+		}
+		cors.maxAge = maxAge
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return cors, nil
+}
+
+// String renders the CORS with the names of the annotations.
+func (c *ingressCORS) String() string {
+	return fmt.Sprintf("allow-origin [%s], allow-methods [%s], allow-headers [%s], expose-headers [%s], allow-credentials %t, max-age %ds",
+		strings.Join(c.allowOrigins, ", "), strings.Join(c.allowMethods, ", "), strings.Join(c.allowHeaders, ", "),
+		strings.Join(c.exposeHeaders, ", "), c.allowCredentials, c.maxAge)
+}
+
+// envoyGatewayCORS returns the cors field of an Envoy Gateway SecurityPolicy.
+func (c *ingressCORS) envoyGatewayCORS() map[string]interface{} {
+	cors := map[string]interface{}{
+		"allowOrigins":     toInterfaceSlice(c.allowOrigins),
+		"allowMethods":     toInterfaceSlice(c.allowMethods),
+		"allowHeaders":     toInterfaceSlice(c.allowHeaders),
+		"allowCredentials": c.allowCredentials,
+		"maxAge":           fmt.Sprintf("%ds", c.maxAge),
+	}
+	if len(c.exposeHeaders) > 0 {
+		cors["exposeHeaders"] = toInterfaceSlice(c.exposeHeaders)
+	}
+	return cors
+}
+
+// splitList splits a comma-separated annotation value.
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// toInterfaceSlice converts the strings to the type of the lists of
+// unstructured objects.
+func toInterfaceSlice(items []string) []interface{} {
+	converted := make([]interface{}, 0, len(items))
+	for _, item := range items {
+		converted = append(converted, item)
+	}
+	return converted
+}
@@ -0,0 +1,149 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingressnginx
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func Test_corsFeature(t *testing.T) {
+	withTLS := func(ingress networkingv1.Ingress) networkingv1.Ingress {
+		ingress.Spec.TLS = []networkingv1.IngressTLS{{Hosts: []string{"example.com"}, SecretName: "example-com-tls"}}
+		return ingress
+	}
+	envoyGateway := map[string]string{TargetImplementationFlag: envoyGatewayImplementation}
+	policyKey := func(name string) i2gw.ExtensionResourceKey {
+		return i2gw.ExtensionResourceKey{
+			GroupKind:      envoyGatewaySecurityPolicyGVK.GroupKind(),
+			NamespacedName: types.NamespacedName{Namespace: "default", Name: name},
+		}
+	}
+
+	testCases := []struct {
+		name                  string
+		flags                 map[string]string
+		ingresses             []networkingv1.Ingress
+		expectedPolicies      map[i2gw.ExtensionResourceKey]map[string]interface{}
+		expectedNotifications []string
+	}{{
+		name:  "envoy-gateway",
+		flags: envoyGateway,
+		ingresses: []networkingv1.Ingress{
+			testIngress("a", "example.com", "/", networkingv1.PathTypePrefix, map[string]string{
+				enableCORSAnnotation:           "true",
+				corsAllowOriginAnnotation:      "https://a.example.com, https://b.example.com",
+				corsAllowMethodsAnnotation:     "GET,POST",
+				corsExposeHeadersAnnotation:    "X-Request-Id",
+				corsAllowCredentialsAnnotation: "false",
+				corsMaxAgeAnnotation:           "600",
+			}),
+		},
+		expectedPolicies: map[i2gw.ExtensionResourceKey]map[string]interface{}{
+			policyKey("a-example-com"): {
+				"allowOrigins":     []interface{}{"https://a.example.com", "https://b.example.com"},
+				"allowMethods":     []interface{}{"GET", "POST"},
+				"allowHeaders":     toInterfaceSlice(splitList(defaultCORSAllowHeaders)),
+				"exposeHeaders":    []interface{}{"X-Request-Id"},
+				"allowCredentials": false,
+				"maxAge":           "600s",
+			},
+		},
+		expectedNotifications: []string{"the CORS of default/a are converted to the SecurityPolicy of the HTTPRoutes of its rules"},
+	}, {
+		name:  "rules split from the paths of other Ingresses, along with the redirect route",
+		flags: envoyGateway,
+		ingresses: []networkingv1.Ingress{
+			withTLS(testIngress("a", "example.com", "/", networkingv1.PathTypePrefix, map[string]string{
+				enableCORSAnnotation: "true",
+			})),
+			withTLS(testIngress("b", "example.com", "/b", networkingv1.PathTypePrefix, nil)),
+		},
+		expectedPolicies: map[i2gw.ExtensionResourceKey]map[string]interface{}{
+			policyKey("a-example-com-a"): {
+				"allowOrigins":     []interface{}{"*"},
+				"allowMethods":     toInterfaceSlice(splitList(defaultCORSAllowMethods)),
+				"allowHeaders":     toInterfaceSlice(splitList(defaultCORSAllowHeaders)),
+				"allowCredentials": true,
+				"maxAge":           "1728000s",
+			},
+			policyKey("a-example-com-http-a"): {
+				"allowOrigins":     []interface{}{"*"},
+				"allowMethods":     toInterfaceSlice(splitList(defaultCORSAllowMethods)),
+				"allowHeaders":     toInterfaceSlice(splitList(defaultCORSAllowHeaders)),
+				"allowCredentials": true,
+				"maxAge":           "1728000s",
+			},
+		},
+		expectedNotifications: []string{
+			"the rules of HTTPRoute default/a-example-com with the policies of default/a are moved to HTTPRoute default/a-example-com-a",
+			"the rules of HTTPRoute default/a-example-com-http with the policies of default/a are moved to HTTPRoute default/a-example-com-http-a",
+		},
+	}, {
+		name: "no target implementation",
+		ingresses: []networkingv1.Ingress{
+			testIngress("a", "example.com", "/", networkingv1.PathTypePrefix, map[string]string{
+				enableCORSAnnotation:      "true",
+				corsAllowOriginAnnotation: "https://a.example.com",
+			}),
+		},
+		expectedPolicies: map[i2gw.ExtensionResourceKey]map[string]interface{}{},
+		expectedNotifications: []string{
+			"CORS is unsupported by the Gateway API, manual action required: configure allow-origin [https://a.example.com], allow-methods [GET, PUT, POST, DELETE, PATCH, OPTIONS]",
+		},
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			conf := &i2gw.ProviderConf{ProviderSpecificFlags: map[string]map[string]string{Name: tc.flags}}
+			gatewayResources, notificationTable := convertTestIngresses(t, conf, tc.ingresses...)
+
+			policies := map[i2gw.ExtensionResourceKey]map[string]interface{}{}
+			for key, policy := range gatewayResources.ExtensionResources {
+				cors, _, err := unstructured.NestedMap(policy.Object, "spec", "cors")
+				if err != nil {
+					t.Fatalf("Unexpected SecurityPolicy %+v: %v", policy, err)
+				}
+				if targetName, _, _ := unstructured.NestedString(policy.Object, "spec", "targetRef", "name"); targetName != key.Name {
+					t.Errorf("Expected SecurityPolicy %s to target HTTPRoute %s, got %s", key.NamespacedName, key.Name, targetName)
+				}
+				policies[key] = cors
+			}
+			if diff := cmp.Diff(tc.expectedPolicies, policies); diff != "" {
+				t.Errorf("Unexpected SecurityPolicies, diff (-want +got):\n%s", diff)
+			}
+			for _, expected := range tc.expectedNotifications {
+				if !strings.Contains(notificationTable, expected) {
+					t.Errorf("Expected notification %q, got:\n%s", expected, notificationTable)
+				}
+			}
+		})
+	}
+
+	t.Run("unsupported target implementation", func(t *testing.T) {
+		provider := NewProvider(&i2gw.ProviderConf{ProviderSpecificFlags: map[string]map[string]string{Name: {TargetImplementationFlag: "unknown"}}})
+		if _, errs := provider.ToGatewayAPI(); len(errs) != 1 {
+			t.Errorf("Expected 1 error, got %+v", errs)
+		}
+	})
+}
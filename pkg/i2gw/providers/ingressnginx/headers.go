@@ -0,0 +1,195 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingressnginx
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/providers/common"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+const (
+	upstreamVhostAnnotation    = "nginx.ingress.kubernetes.io/upstream-vhost"
+	xForwardedPrefixAnnotation = "nginx.ingress.kubernetes.io/x-forwarded-prefix"
+	proxySetHeadersAnnotation  = "nginx.ingress.kubernetes.io/proxy-set-headers"
+	customHeadersAnnotation    = "nginx.ingress.kubernetes.io/custom-headers"
+
+	xForwardedPrefixHeader = "X-Forwarded-Prefix"
+)
+
+// ingressHeaders are the header settings of an Ingress.
+type ingressHeaders struct {
+	request  []gatewayv1.HTTPHeader
+	response []gatewayv1.HTTPHeader
+	// hostname is the Host header sent to the backends, empty when not set.
+	hostname string
+}
+
+// headers converts the header annotations: upstream-vhost to the hostname of
+// a URLRewrite filter, x-forwarded-prefix and the headers of the
+// proxy-set-headers ConfigMap to a RequestHeaderModifier filter, and the
+// headers of the custom-headers ConfigMap to a ResponseHeaderModifier filter.
+// The headers set by the annotations replace the ones set by the controller
+// ConfigMap, so this must run after controllerConfig.feature.
+type headers struct {
+	// configMaps are the ConfigMaps referenced by the annotations, missing
+	// when not found.
+	configMaps map[types.NamespacedName]*corev1.ConfigMap
+}
+
+func (h *headers) feature(ingresses []networkingv1.Ingress, gatewayResources *i2gw.GatewayResources) field.ErrorList {
+	headersByIngress := map[types.NamespacedName]ingressHeaders{}
+	for i := range ingresses {
+		headersByIngress[types.NamespacedName{Namespace: ingresses[i].Namespace, Name: ingresses[i].Name}] = h.parseIngressHeaders(&ingresses[i])
+	}
+
+	for _, rg := range sortedRuleGroups(ingresses) {
+		key := types.NamespacedName{Namespace: rg.Namespace, Name: common.RouteName(rg.Name, rg.Host)}
+		httpRoute, ok := gatewayResources.HTTPRoutes[key]
+		if !ok {
+			continue
+		}
+
+		sourcesByRule := ruleSourcesByIndex(rg, httpRoute)
+		for _, ruleIdx := range sortedRuleIndexes(sourcesByRule) {
+			sources := annotationSources(sourcesByRule[ruleIdx])
+			var builder common.HeaderFilterBuilder
+			var callingObjects []client.Object
+			for i, source := range sources {
+				callingObjects = append(callingObjects, &sources[i].ingress)
+				settings := headersByIngress[types.NamespacedName{Namespace: source.ingress.Namespace, Name: source.ingress.Name}]
+				builder.AddRequestHeaders(settings.request)
+				builder.AddResponseHeaders(settings.response)
+				builder.SetHostname(settings.hostname, upstreamVhostAnnotation)
+			}
+			if conflicts := builder.Conflicts(); len(conflicts) > 0 {
+				notifications.NotificationAggr.DispatchNotification(notifications.NewNotification(
+					notifications.WarningNotification,
+					fmt.Sprintf("Ingresses sharing path %q set %s differently, the value of %s/%s is used", sources[0].path.Path, strings.Join(conflicts, ", "), sources[0].ingress.Namespace, sources[0].ingress.Name),
+					callingObjects...,
+				), Name)
+			}
+			builder.Apply(&httpRoute.Spec.Rules[ruleIdx])
+		}
+		gatewayResources.HTTPRoutes[key] = httpRoute
+	}
+
+	return nil
+}
+
+// parseIngressHeaders returns the header settings of the Ingress, reporting the
+// ones which cannot be converted.
+func (h *headers) parseIngressHeaders(ingress *networkingv1.Ingress) ingressHeaders {
+	var parsed ingressHeaders
+	if vhost := ingress.Annotations[upstreamVhostAnnotation]; vhost != "" {
+		if strings.Contains(vhost, "$") {
+			notifications.NotificationAggr.DispatchNotification(notifications.NewNotification(
+				notifications.WarningNotification,
+				fmt.Sprintf("%s %q uses nginx variables, which cannot be converted", upstreamVhostAnnotation, vhost),
+				ingress,
+			), Name)
+		} else {
+			parsed.hostname = vhost
+		}
+	}
+	if prefix := ingress.Annotations[xForwardedPrefixAnnotation]; prefix != "" {
+		parsed.request = append(parsed.request, gatewayv1.HTTPHeader{Name: xForwardedPrefixHeader, Value: prefix})
+	}
+	parsed.request = append(parsed.request, h.configMapHeaders(ingress, proxySetHeadersAnnotation)...)
+	parsed.response = h.configMapHeaders(ingress, customHeadersAnnotation)
+	return parsed
+}
+
+// configMapHeaders returns the headers of the ConfigMap referenced by the
+// annotation of the Ingress, as name, of the Ingress namespace, or as
+// namespace/name.
+func (h *headers) configMapHeaders(ingress *networkingv1.Ingress, annotation string) []gatewayv1.HTTPHeader {
+	value := ingress.Annotations[annotation]
+	if value == "" {
+		return nil
+	}
+	key, err := objectReference(ingress.Namespace, value)
+	if err != nil {
+		notifications.NotificationAggr.DispatchNotification(notifications.NewNotification(
+			notifications.WarningNotification,
+			fmt.Sprintf("%s %q is ignored: %v", annotation, value, err),
+			ingress,
+		), Name)
+		return nil
+	}
+	configMap, ok := h.configMaps[key]
+	if !ok {
+		notifications.NotificationAggr.DispatchNotification(notifications.NewNotification(
+			notifications.WarningNotification,
+			fmt.Sprintf("%s is ignored: ConfigMap %s is not found", annotation, key),
+			ingress,
+		), Name)
+		return nil
+	}
+
+	headers, unconverted := headersFromConfigMap(configMap)
+	if len(unconverted) > 0 {
+		notifications.NotificationAggr.DispatchNotification(notifications.NewNotification(
+			notifications.WarningNotification,
+			fmt.Sprintf("headers %s of %s %s use nginx variables, which cannot be converted", strings.Join(unconverted, ", "), annotation, key),
+			ingress,
+		), Name)
+	}
+	return headers
+}
+
+// objectReference returns the object referenced by an annotation value,
+// as name, of the Ingress namespace, or as namespace/name.
+func objectReference(namespace, value string) (types.NamespacedName, error) {
+	if !strings.Contains(value, "/") {
+		return types.NamespacedName{Namespace: namespace, Name: value}, nil
+	}
+	return common.ParseNamespacedName(value)
+}
+
+// headersFromConfigMap returns the headers of a ConfigMap mapping header names
+// to values, sorted by name, along with the names of the headers whose value
+// uses nginx variables, which cannot be converted.
+func headersFromConfigMap(configMap *corev1.ConfigMap) ([]gatewayv1.HTTPHeader, []string) {
+	names := make([]string, 0, len(configMap.Data))
+	for name := range configMap.Data {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var headers []gatewayv1.HTTPHeader
+	var unconverted []string
+	for _, name := range names {
+		value := configMap.Data[name]
+		if strings.Contains(value, "$") {
+			unconverted = append(unconverted, name)
+			continue
+		}
+		headers = append(headers, gatewayv1.HTTPHeader{Name: gatewayv1.HTTPHeaderName(name), Value: value})
+	}
+	return headers, unconverted
+}
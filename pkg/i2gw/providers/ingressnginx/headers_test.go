@@ -0,0 +1,153 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingressnginx
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+func Test_headersFeature(t *testing.T) {
+	configMaps := map[types.NamespacedName]*corev1.ConfigMap{
+		{Namespace: "default", Name: "request-headers"}: {
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "request-headers"},
+			Data:       map[string]string{"X-Team": "payments", "X-Client": "$remote_addr"},
+		},
+		{Namespace: "default", Name: "other-request-headers"}: {
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "other-request-headers"},
+			Data:       map[string]string{"X-Team": "billing"},
+		},
+		{Namespace: "shared", Name: "response-headers"}: {
+			ObjectMeta: metav1.ObjectMeta{Namespace: "shared", Name: "response-headers"},
+			Data:       map[string]string{"X-Frame-Options": "DENY"},
+		},
+	}
+
+	testCases := []struct {
+		name                  string
+		ingresses             []networkingv1.Ingress
+		expectedFilters       []gatewayv1.HTTPRouteFilter
+		expectedNotifications []string
+	}{{
+		name: "header annotations",
+		ingresses: []networkingv1.Ingress{
+			testIngress("a", "example.com", "/", networkingv1.PathTypePrefix, map[string]string{
+				upstreamVhostAnnotation:    "internal.example.com",
+				xForwardedPrefixAnnotation: "/app",
+				proxySetHeadersAnnotation:  "request-headers",
+				customHeadersAnnotation:    "shared/response-headers",
+			}),
+		},
+		expectedFilters: []gatewayv1.HTTPRouteFilter{{
+			Type: gatewayv1.HTTPRouteFilterRequestHeaderModifier,
+			RequestHeaderModifier: &gatewayv1.HTTPHeaderFilter{
+				Set: []gatewayv1.HTTPHeader{{Name: "X-Forwarded-Prefix", Value: "/app"}, {Name: "X-Team", Value: "payments"}},
+			},
+		}, {
+			Type: gatewayv1.HTTPRouteFilterResponseHeaderModifier,
+			ResponseHeaderModifier: &gatewayv1.HTTPHeaderFilter{
+				Set: []gatewayv1.HTTPHeader{{Name: "X-Frame-Options", Value: "DENY"}},
+			},
+		}, {
+			Type:       gatewayv1.HTTPRouteFilterURLRewrite,
+			URLRewrite: &gatewayv1.HTTPURLRewriteFilter{Hostname: ptrTo(gatewayv1.PreciseHostname("internal.example.com"))},
+		}},
+		expectedNotifications: []string{"headers X-Client of nginx.ingress.kubernetes.io/proxy-set-headers default/request-headers use nginx variables"},
+	}, {
+		name: "upstream-vhost and rewrite-target share the URLRewrite filter",
+		ingresses: []networkingv1.Ingress{
+			testIngress("a", "example.com", "/app", networkingv1.PathTypePrefix, map[string]string{
+				upstreamVhostAnnotation: "internal.example.com",
+				rewriteTargetAnnotation: "/",
+			}),
+		},
+		expectedFilters: []gatewayv1.HTTPRouteFilter{{
+			Type: gatewayv1.HTTPRouteFilterURLRewrite,
+			URLRewrite: &gatewayv1.HTTPURLRewriteFilter{
+				Hostname: ptrTo(gatewayv1.PreciseHostname("internal.example.com")),
+				Path:     &gatewayv1.HTTPPathModifier{Type: gatewayv1.FullPathHTTPPathModifier, ReplaceFullPath: ptrTo("/")},
+			},
+		}},
+	}, {
+		name: "Ingresses sharing a rule merge their headers",
+		ingresses: []networkingv1.Ingress{
+			testIngress("a", "example.com", "/", networkingv1.PathTypePrefix, map[string]string{
+				proxySetHeadersAnnotation: "request-headers",
+			}),
+			testIngress("b", "example.com", "/", networkingv1.PathTypePrefix, map[string]string{
+				proxySetHeadersAnnotation:  "other-request-headers",
+				xForwardedPrefixAnnotation: "/b",
+			}),
+		},
+		expectedFilters: []gatewayv1.HTTPRouteFilter{{
+			Type: gatewayv1.HTTPRouteFilterRequestHeaderModifier,
+			RequestHeaderModifier: &gatewayv1.HTTPHeaderFilter{
+				Set: []gatewayv1.HTTPHeader{{Name: "X-Team", Value: "payments"}, {Name: "X-Forwarded-Prefix", Value: "/b"}},
+			},
+		}},
+		expectedNotifications: []string{"set header X-Team differently, the value of default/a is used"},
+	}, {
+		name: "missing ConfigMap",
+		ingresses: []networkingv1.Ingress{
+			testIngress("a", "example.com", "/", networkingv1.PathTypePrefix, map[string]string{
+				customHeadersAnnotation: "missing",
+			}),
+		},
+		expectedNotifications: []string{"ConfigMap default/missing is not found"},
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			provider := NewProvider(&i2gw.ProviderConf{}).(*Provider)
+			ingressMap := map[types.NamespacedName]*networkingv1.Ingress{}
+			for i := range tc.ingresses {
+				ingressMap[types.NamespacedName{Namespace: tc.ingresses[i].Namespace, Name: tc.ingresses[i].Name}] = &tc.ingresses[i]
+			}
+			provider.storage.Ingresses.FromMap(ingressMap)
+			provider.storage.ConfigMaps = configMaps
+
+			gatewayResources, errs := provider.ToGatewayAPI()
+			if len(errs) != 0 {
+				t.Fatalf("Unexpected errors: %+v", errs)
+			}
+			notificationTable := notifications.NotificationAggr.CreateNotificationTables()[Name]
+
+			key := types.NamespacedName{Namespace: "default", Name: "a-example-com"}
+			httpRoute, ok := gatewayResources.HTTPRoutes[key]
+			if !ok || len(httpRoute.Spec.Rules) != 1 {
+				t.Fatalf("Expected HTTPRoute %s with a single rule, got %+v", key, gatewayResources.HTTPRoutes)
+			}
+			if diff := cmp.Diff(tc.expectedFilters, httpRoute.Spec.Rules[0].Filters); diff != "" {
+				t.Errorf("Unexpected filters, diff (-want +got):\n%s", diff)
+			}
+			for _, expected := range tc.expectedNotifications {
+				if !strings.Contains(notificationTable, expected) {
+					t.Errorf("Expected notification %q, got:\n%s", expected, notificationTable)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,115 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingressnginx
+
+import (
+	"fmt"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/providers/common"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+const (
+	// TargetImplementationFlag is the provider-specific flag selecting the
+	// Gateway implementation whose policies are generated for the annotations
+	// with no Gateway API equivalent.
+	TargetImplementationFlag = "target-implementation"
+
+	envoyGatewayImplementation = "envoy-gateway"
+)
+
+var envoyGatewaySecurityPolicyGVK = schema.GroupVersionKind{
+	Group:   "gateway.envoyproxy.io",
+	Version: "v1alpha1",
+	Kind:    "SecurityPolicy",
+}
+
+var envoyGatewayBackendTrafficPolicyGVK = schema.GroupVersionKind{
+	Group:   "gateway.envoyproxy.io",
+	Version: "v1alpha1",
+	Kind:    "BackendTrafficPolicy",
+}
+
+// targetImplementations are the supported values of TargetImplementationFlag.
+var targetImplementations = []string{envoyGatewayImplementation}
+
+// validateTargetImplementation validates the value of TargetImplementationFlag,
+// which is empty when no implementation is targeted.
+func validateTargetImplementation(target string) field.ErrorList {
+	if target == "" {
+		return nil
+	}
+	for _, implementation := range targetImplementations {
+		if target == implementation {
+			return nil
+		}
+	}
+	return field.ErrorList{field.NotSupported(field.NewPath("ProviderSpecificFlags", Name, TargetImplementationFlag), target, targetImplementations)}
+}
+
+// notifyManualAction reports the setting of the Ingress, unsupported as
+// explained, to be configured manually as no target implementation is
+// selected.
+func notifyManualAction(ingress *networkingv1.Ingress, unsupported string, setting fmt.Stringer) {
+	notifications.NotificationAggr.DispatchNotification(notifications.NewNotification(
+		notifications.WarningNotification,
+		fmt.Sprintf("%s, manual action required: configure %s with the Gateway implementation, or select one with --%s-%s",
+			unsupported, setting, Name, TargetImplementationFlag),
+		ingress,
+	), Name)
+}
+
+// routePolicy returns the policy of kind gvk attached to the HTTPRoute,
+// adding it to the extension resources if it does not exist. The policy is
+// named after the HTTPRoute, and its spec is set by the features through the
+// returned object, which shares its content with the stored one.
+func routePolicy(gatewayResources *i2gw.GatewayResources, gvk schema.GroupVersionKind, routeKey types.NamespacedName) unstructured.Unstructured {
+	key := i2gw.ExtensionResourceKey{GroupKind: gvk.GroupKind(), NamespacedName: routeKey}
+	if gatewayResources.ExtensionResources == nil {
+		gatewayResources.ExtensionResources = map[i2gw.ExtensionResourceKey]unstructured.Unstructured{}
+	}
+	if policy, ok := gatewayResources.ExtensionResources[key]; ok {
+		return policy
+	}
+
+	policy := unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": map[string]interface{}{
+			"targetRef": map[string]interface{}{
+				"group": common.HTTPRouteGVK.Group,
+				"kind":  common.HTTPRouteGVK.Kind,
+				"name":  routeKey.Name,
+			},
+		},
+	}}
+	policy.SetGroupVersionKind(gvk)
+	policy.SetNamespace(routeKey.Namespace)
+	policy.SetName(routeKey.Name)
+	gatewayResources.ExtensionResources[key] = policy
+	return policy
+}
+
+// setPolicySpec sets the field of the spec of a policy returned by
+// routePolicy.
+func setPolicySpec(policy unstructured.Unstructured, name string, value interface{}) {
+	policy.Object["spec"].(map[string]interface{})[name] = value
+}
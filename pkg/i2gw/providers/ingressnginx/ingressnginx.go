@@ -21,6 +21,7 @@ import (
 	"fmt"
 
 	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/providers/common"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 )
 
@@ -30,6 +31,37 @@ const NginxIngressClass = "nginx"
 
 func init() {
 	i2gw.ProviderConstructorByName[Name] = NewProvider
+
+	i2gw.RegisterProviderSpecificFlag(Name, i2gw.ProviderSpecificFlag{
+		Name:        common.ControllerServiceFlag,
+		Description: common.ControllerServiceFlagDescription,
+	})
+	i2gw.RegisterProviderSpecificFlag(Name, i2gw.ProviderSpecificFlag{
+		Name:         DefaultSSLRedirectFlag,
+		Description:  "The ssl-redirect setting of the controller ConfigMap, applied to the Ingresses not setting the nginx.ingress.kubernetes.io/ssl-redirect annotation.",
+		DefaultValue: "true",
+	})
+	i2gw.RegisterProviderSpecificFlag(Name, i2gw.ProviderSpecificFlag{
+		Name:        ControllerConfigMapFlag,
+		Description: "The ConfigMap of the controller, as namespace/name. When set, its ssl-redirect, hsts, proxy-read-timeout and proxy-set-headers settings are converted as defaults of the Ingresses, which their annotations override.",
+	})
+	i2gw.RegisterProviderSpecificFlag(Name, i2gw.ProviderSpecificFlag{
+		Name:        TCPServicesConfigMapFlag,
+		Description: "The tcp-services ConfigMap of the controller, as namespace/name. When set, its entries are converted to TCP listeners and TCPRoutes.",
+	})
+	i2gw.RegisterProviderSpecificFlag(Name, i2gw.ProviderSpecificFlag{
+		Name:        UDPServicesConfigMapFlag,
+		Description: "The udp-services ConfigMap of the controller, as namespace/name. When set, its entries are converted to UDP listeners and UDPRoutes.",
+	})
+	i2gw.RegisterProviderSpecificFlag(Name, i2gw.ProviderSpecificFlag{
+		Name:        TargetImplementationFlag,
+		Description: "The Gateway implementation whose policies are generated for the annotations with no Gateway API equivalent. Supported: envoy-gateway. When not set, these annotations are reported.",
+	})
+	i2gw.RegisterProviderSpecificFlag(Name, i2gw.ProviderSpecificFlag{
+		Name:         AllowUnprotectedRoutesFlag,
+		Description:  "Convert the paths protected by authentication, IP access or snippet annotations which cannot be converted, without access control. When false, these paths are left out of the HTTPRoutes and GRPCRoutes.",
+		DefaultValue: "false",
+	})
 }
 
 // Provider implements the i2gw.Provider interface.
@@ -44,7 +76,7 @@ func NewProvider(conf *i2gw.ProviderConf) i2gw.Provider {
 	return &Provider{
 		storage:        newResourcesStorage(),
 		resourceReader: newResourceReader(conf),
-		converter:      newConverter(),
+		converter:      newConverter(conf),
 	}
 }
 
@@ -0,0 +1,134 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingressnginx
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+const (
+	whitelistSourceRangeAnnotation = "nginx.ingress.kubernetes.io/whitelist-source-range"
+	denylistSourceRangeAnnotation  = "nginx.ingress.kubernetes.io/denylist-source-range"
+	satisfyAnnotation              = "nginx.ingress.kubernetes.io/satisfy"
+
+	satisfyAll = "all"
+	satisfyAny = "any"
+)
+
+// parseIPAccess returns the IP access restriction of the
+// whitelist-source-range and denylist-source-range annotations of the
+// Ingress, nil when it sets none. It is converted to the authorization of a
+// SecurityPolicy, which only applies to the rules of the Ingress: ingress-nginx
+// restricts the access to the paths of each Ingress, even when it shares its
+// host with others.
+func (a *auth) parseIPAccess(ingress *networkingv1.Ingress) (*ingressAuth, field.ErrorList) {
+	var errs field.ErrorList
+	fieldPath := field.NewPath(ingress.Name).Child("metadata").Child("annotations")
+	parseRanges := func(annotation string) []string {
+		var cidrs []string
+		for _, value := range splitList(ingress.Annotations[annotation]) {
+			cidr, err := parseCIDR(value)
+			if err != nil {
+				errs = append(errs, field.Invalid(fieldPath.Key(annotation), ingress.Annotations[annotation], err.Error()))
+				return nil
+			}
+			cidrs = append(cidrs, cidr)
+		}
+		return cidrs
+	}
+	allowed := parseRanges(whitelistSourceRangeAnnotation)
+	denied := parseRanges(denylistSourceRangeAnnotation)
+	satisfy := ingress.Annotations[satisfyAnnotation]
+	if satisfy != "" && satisfy != satisfyAll && satisfy != satisfyAny {
+		errs = append(errs, field.NotSupported(fieldPath.Key(satisfyAnnotation), satisfy, []string{satisfyAll, satisfyAny}))
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	if len(allowed) == 0 && len(denied) == 0 {
+		return nil, nil
+	}
+
+	annotation := whitelistSourceRangeAnnotation
+	if len(allowed) == 0 {
+		annotation = denylistSourceRangeAnnotation
+	}
+	ipAccess := &ingressAuth{annotation: annotation, policyField: "authorization", restriction: true}
+	if a.target == "" {
+		ipAccess.unconverted = fmt.Sprintf("no target implementation is selected with --%s-%s", Name, TargetImplementationFlag)
+		return ipAccess, nil
+	}
+
+	// The denied ranges take precedence, as in nginx, where they are
+	// denied before the allowed ones are allowed.
+	var rules []interface{}
+	if len(denied) > 0 {
+		rules = append(rules, map[string]interface{}{
+			"name":      "denylist-source-range",
+			"action":    "Deny",
+			"principal": map[string]interface{}{"clientCIDRs": toInterfaceSlice(denied)},
+		})
+	}
+	defaultAction := "Allow"
+	if len(allowed) > 0 {
+		defaultAction = "Deny"
+		rules = append(rules, map[string]interface{}{
+			"name":      "whitelist-source-range",
+			"action":    "Allow",
+			"principal": map[string]interface{}{"clientCIDRs": toInterfaceSlice(allowed)},
+		})
+	}
+	ipAccess.policy = map[string]interface{}{
+		"defaultAction": defaultAction,
+		"rules":         rules,
+	}
+
+	message := fmt.Sprintf("the allowed source ranges [%s] and denied source ranges [%s] are converted to the authorization of the SecurityPolicy of the rules of the Ingress, which matches the address of the client connected to the Gateway, unless a ClientTrafficPolicy configures the trusted X-Forwarded-For hops",
+		strings.Join(allowed, ", "), strings.Join(denied, ", "))
+	_, hasExtAuth := ingress.Annotations[authURLAnnotation]
+	_, hasBasicAuth := ingress.Annotations[authTypeAnnotation]
+	if satisfy == satisfyAny && len(allowed) > 0 && (hasExtAuth || hasBasicAuth) {
+		message += fmt.Sprintf(", and %s %s cannot be converted: the requests must come from the allowed ranges and be authenticated, instead of either", satisfyAnnotation, satisfy)
+	}
+	ipAccess.converted = notifications.NewNotification(notifications.WarningNotification, message, ingress)
+
+	return ipAccess, nil
+}
+
+// parseCIDR validates a CIDR or an IP address, which is returned as a CIDR.
+func parseCIDR(value string) (string, error) {
+	if strings.Contains(value, "/") {
+		if _, _, err := net.ParseCIDR(value); err != nil {
+			return "", fmt.Errorf("%q is not a valid CIDR", value)
+		}
+		return value, nil
+	}
+	ip := net.ParseIP(value)
+	if ip == nil {
+		return "", fmt.Errorf("%q is not a valid IP address or CIDR", value)
+	}
+	if ip.To4() != nil {
+		return value + "/32", nil
+	}
+	return value + "/128", nil
+}
@@ -0,0 +1,157 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingressnginx
+
+import (
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func Test_ipAccessFeature(t *testing.T) {
+	withTLS := func(ingress networkingv1.Ingress) networkingv1.Ingress {
+		ingress.Spec.TLS = []networkingv1.IngressTLS{{Hosts: []string{"example.com"}, SecretName: "example-com-tls"}}
+		return ingress
+	}
+	allowOffice := map[string]interface{}{
+		"defaultAction": "Deny",
+		"rules": []interface{}{map[string]interface{}{
+			"name":      "whitelist-source-range",
+			"action":    "Allow",
+			"principal": map[string]interface{}{"clientCIDRs": []interface{}{"10.0.0.0/8", "192.168.1.10/32"}},
+		}},
+	}
+
+	testCases := []struct {
+		name      string
+		ingresses []networkingv1.Ingress
+		// expectedRoutes are the paths of the rules of the HTTPRoutes, and
+		// expectedAuthorizations the authorization of their SecurityPolicy.
+		expectedRoutes         map[string][]string
+		expectedAuthorizations map[string]map[string]interface{}
+		expectedNotifications  []string
+	}{{
+		name: "allowed and denied ranges",
+		ingresses: []networkingv1.Ingress{
+			testIngress("a", "example.com", "/", networkingv1.PathTypePrefix, map[string]string{
+				whitelistSourceRangeAnnotation: "10.0.0.0/8",
+				denylistSourceRangeAnnotation:  "10.0.0.1, 2001:db8::/32",
+			}),
+		},
+		expectedRoutes: map[string][]string{"a-example-com": {"/"}},
+		expectedAuthorizations: map[string]map[string]interface{}{
+			"a-example-com": {
+				"defaultAction": "Deny",
+				"rules": []interface{}{
+					map[string]interface{}{
+						"name":      "denylist-source-range",
+						"action":    "Deny",
+						"principal": map[string]interface{}{"clientCIDRs": []interface{}{"10.0.0.1/32", "2001:db8::/32"}},
+					},
+					map[string]interface{}{
+						"name":      "whitelist-source-range",
+						"action":    "Allow",
+						"principal": map[string]interface{}{"clientCIDRs": []interface{}{"10.0.0.0/8"}},
+					},
+				},
+			},
+		},
+		expectedNotifications: []string{"unless a ClientTrafficPolicy configures the trusted X-Forwarded-For hops"},
+	}, {
+		name: "rules of a shared host split by restriction, along with the redirect route",
+		ingresses: []networkingv1.Ingress{
+			withTLS(testIngress("a", "example.com", "/", networkingv1.PathTypePrefix, map[string]string{
+				enableCORSAnnotation: "true",
+			})),
+			withTLS(testIngress("admin", "example.com", "/admin", networkingv1.PathTypePrefix, map[string]string{
+				whitelistSourceRangeAnnotation: "10.0.0.0/8,192.168.1.10",
+			})),
+		},
+		expectedRoutes: map[string][]string{
+			"a-example-com":            {"/"},
+			"a-example-com-admin":      {"/admin"},
+			"a-example-com-http":       {"/"},
+			"a-example-com-http-admin": {"/admin"},
+		},
+		expectedAuthorizations: map[string]map[string]interface{}{
+			"a-example-com":            nil,
+			"a-example-com-admin":      allowOffice,
+			"a-example-com-http":       nil,
+			"a-example-com-http-admin": allowOffice,
+		},
+		expectedNotifications: []string{"the rules of HTTPRoute default/a-example-com with the policies of default/admin are moved to HTTPRoute default/a-example-com-admin"},
+	}, {
+		name: "satisfy any with authentication",
+		ingresses: []networkingv1.Ingress{
+			testIngress("a", "example.com", "/", networkingv1.PathTypePrefix, map[string]string{
+				whitelistSourceRangeAnnotation: "10.0.0.0/8,192.168.1.10",
+				satisfyAnnotation:              "any",
+				authTypeAnnotation:             "basic",
+				authSecretAnnotation:           "basic-auth",
+			}),
+		},
+		expectedRoutes:         map[string][]string{"a-example-com": {"/"}},
+		expectedAuthorizations: map[string]map[string]interface{}{"a-example-com": allowOffice},
+		expectedNotifications:  []string{"nginx.ingress.kubernetes.io/satisfy any cannot be converted"},
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			conf := &i2gw.ProviderConf{ProviderSpecificFlags: map[string]map[string]string{Name: {TargetImplementationFlag: envoyGatewayImplementation}}}
+			gatewayResources, notificationTable := convertTestIngresses(t, conf, tc.ingresses...)
+
+			routes := map[string][]string{}
+			for key, httpRoute := range gatewayResources.HTTPRoutes {
+				for _, rule := range httpRoute.Spec.Rules {
+					routes[key.Name] = append(routes[key.Name], *rule.Matches[0].Path.Value)
+				}
+				sort.Strings(routes[key.Name])
+			}
+			if diff := cmp.Diff(tc.expectedRoutes, routes); diff != "" {
+				t.Errorf("Unexpected HTTPRoutes, diff (-want +got):\n%s", diff)
+			}
+			authorizations := map[string]map[string]interface{}{}
+			for key, policy := range gatewayResources.ExtensionResources {
+				authorization, _, _ := unstructured.NestedMap(policy.Object, "spec", "authorization")
+				authorizations[key.Name] = authorization
+			}
+			if diff := cmp.Diff(tc.expectedAuthorizations, authorizations); diff != "" {
+				t.Errorf("Unexpected SecurityPolicy authorizations, diff (-want +got):\n%s", diff)
+			}
+			for _, expected := range tc.expectedNotifications {
+				if !strings.Contains(notificationTable, expected) {
+					t.Errorf("Expected notification %q, got:\n%s", expected, notificationTable)
+				}
+			}
+		})
+	}
+
+	t.Run("invalid range", func(t *testing.T) {
+		provider := NewProvider(&i2gw.ProviderConf{}).(*Provider)
+		ingress := testIngress("a", "example.com", "/", networkingv1.PathTypePrefix, map[string]string{whitelistSourceRangeAnnotation: "10.0.0.0/33"})
+		provider.storage.Ingresses.FromMap(map[types.NamespacedName]*networkingv1.Ingress{{Namespace: "default", Name: "a"}: &ingress})
+		if _, errs := provider.ToGatewayAPI(); len(errs) != 1 {
+			t.Errorf("Expected 1 error, got %+v", errs)
+		}
+	})
+}
@@ -0,0 +1,229 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingressnginx
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+const (
+	loadBalanceAnnotation    = "nginx.ingress.kubernetes.io/load-balance"
+	upstreamHashByAnnotation = "nginx.ingress.kubernetes.io/upstream-hash-by"
+
+	roundRobinLoadBalance = "round_robin"
+	ewmaLoadBalance       = "ewma"
+)
+
+// The types of the keys of consistent hashing.
+const (
+	sourceIPHashKey = "SourceIP"
+	headerHashKey   = "Header"
+	cookieHashKey   = "Cookie"
+	pathHashKey     = "Path"
+)
+
+// nginxVariableRegex matches an expression made of a single nginx variable.
+var nginxVariableRegex = regexp.MustCompile(`^\$(?:([a-z0-9_]+)|\{([a-z0-9_]+)\})$`)
+
+// hashKey is the key of the consistent hashing of upstream-hash-by.
+type hashKey struct {
+	keyType string
+	// name is the name of the header or cookie.
+	name string
+}
+
+// ingressLoadBalance is the load balancing of the backends of an Ingress.
+type ingressLoadBalance struct {
+	// algorithm is the load-balance algorithm, used when not hashing.
+	algorithm string
+	// hashBy is the upstream-hash-by expression, and hashKey its key, nil when
+	// it cannot be mapped to a key type.
+	hashBy  string
+	hashKey *hashKey
+}
+
+// loadBalanceFeature converts the load-balance and upstream-hash-by
+// annotations.
+//
+// They are converted to the load balancer of the BackendTrafficPolicy of the
+// rules of each Ingress, recorded in routePolicies, with the envoy-gateway
+// target implementation. As in ingress-nginx, the session affinity of the
+// Ingress takes precedence, which parseLoadBalance reports. Without a target
+// implementation, the load balancing of each Ingress is reported, to be
+// configured manually.
+func loadBalanceFeature(target string, policies *routePolicies) i2gw.FeatureParser {
+	return func(ingresses []networkingv1.Ingress, gatewayResources *i2gw.GatewayResources) field.ErrorList {
+		var errs field.ErrorList
+		loadBalanceByIngress := map[types.NamespacedName]*ingressLoadBalance{}
+		for i := range ingresses {
+			loadBalance, parseErrs := parseLoadBalance(ingresses[i])
+			errs = append(errs, parseErrs...)
+			if loadBalance == nil {
+				continue
+			}
+			loadBalanceByIngress[types.NamespacedName{Namespace: ingresses[i].Namespace, Name: ingresses[i].Name}] = loadBalance
+			if target == "" {
+				notifyManualAction(&ingresses[i], "load balancing is unsupported by the Gateway API", loadBalance)
+			}
+		}
+		if len(errs) > 0 || target == "" {
+			return errs
+		}
+
+		converted := map[types.NamespacedName]bool{}
+		forEachRuleSource(ingresses, gatewayResources, true, func(key types.NamespacedName, ruleIdx int, source ruleSource) {
+			ingressKey := types.NamespacedName{Namespace: source.ingress.Namespace, Name: source.ingress.Name}
+			loadBalance := loadBalanceByIngress[ingressKey]
+			if loadBalance == nil {
+				return
+			}
+			loadBalancer, lost := loadBalance.envoyGatewayLoadBalancer()
+			if loadBalancer != nil {
+				policies.set(key, ruleIdx, source, envoyGatewayBackendTrafficPolicyGVK, "loadBalancer", loadBalancer)
+			}
+			if converted[ingressKey] {
+				return
+			}
+			converted[ingressKey] = true
+			notificationType := notifications.InfoNotification
+			var message string
+			switch {
+			case loadBalancer == nil:
+				notificationType = notifications.WarningNotification
+				message = fmt.Sprintf("the %s of %s/%s cannot be converted: %s", loadBalance, ingressKey.Namespace, ingressKey.Name, lost)
+			case lost != "":
+				notificationType = notifications.WarningNotification
+				message = fmt.Sprintf("the %s of %s/%s is converted to the %s load balancer of the BackendTrafficPolicy of the HTTPRoutes of its rules, but %s",
+					loadBalance, ingressKey.Namespace, ingressKey.Name, loadBalancer["type"], lost)
+			default:
+				message = fmt.Sprintf("the %s of %s/%s is converted to the %s load balancer of the BackendTrafficPolicy of the HTTPRoutes of its rules",
+					loadBalance, ingressKey.Namespace, ingressKey.Name, loadBalancer["type"])
+			}
+			notifications.NotificationAggr.DispatchNotification(notifications.NewNotification(notificationType, message, &source.ingress), Name)
+		})
+
+		return nil
+	}
+}
+
+// parseLoadBalance returns the load balancing of the Ingress, nil when it
+// sets none, or when its session affinity takes precedence.
+func parseLoadBalance(ingress networkingv1.Ingress) (*ingressLoadBalance, field.ErrorList) {
+	algorithm, hasAlgorithm := ingress.Annotations[loadBalanceAnnotation]
+	hashBy := ingress.Annotations[upstreamHashByAnnotation]
+	if !hasAlgorithm && hashBy == "" {
+		return nil, nil
+	}
+	if hasAlgorithm && algorithm != roundRobinLoadBalance && algorithm != ewmaLoadBalance {
+		fieldPath := field.NewPath(ingress.Name).Child("metadata").Child("annotations")
+		return nil, field.ErrorList{field.NotSupported(fieldPath.Key(loadBalanceAnnotation), algorithm, []string{roundRobinLoadBalance, ewmaLoadBalance})}
+	}
+	if ingress.Annotations[affinityAnnotation] == cookieAffinity {
+		notifications.NotificationAggr.DispatchNotification(notifications.NewNotification(
+			notifications.WarningNotification,
+			fmt.Sprintf("%s and %s are ignored, as %s takes precedence, like in ingress-nginx", loadBalanceAnnotation, upstreamHashByAnnotation, affinityAnnotation),
+			&ingress,
+		), Name)
+		return nil, nil
+	}
+
+	loadBalance := &ingressLoadBalance{algorithm: algorithm, hashBy: hashBy}
+	if hashBy != "" {
+		loadBalance.hashKey = parseHashKey(hashBy)
+	}
+	return loadBalance, nil
+}
+
+// parseHashKey returns the key of an upstream-hash-by expression made of a
+// single nginx variable, nil when it cannot be mapped to a key type.
+func parseHashKey(expression string) *hashKey {
+	match := nginxVariableRegex.FindStringSubmatch(strings.TrimSpace(expression))
+	if match == nil {
+		return nil
+	}
+	variable := match[1] + match[2]
+	switch {
+	case variable == "remote_addr" || variable == "binary_remote_addr":
+		return &hashKey{keyType: sourceIPHashKey}
+	case strings.HasPrefix(variable, "http_") && len(variable) > len("http_"):
+		// nginx exposes the headers lowercased, with dashes as underscores.
+		return &hashKey{keyType: headerHashKey, name: strings.ReplaceAll(strings.TrimPrefix(variable, "http_"), "_", "-")}
+	case strings.HasPrefix(variable, "cookie_") && len(variable) > len("cookie_"):
+		return &hashKey{keyType: cookieHashKey, name: strings.TrimPrefix(variable, "cookie_")}
+	case variable == "request_uri" || variable == "uri" || variable == "document_uri":
+		return &hashKey{keyType: pathHashKey}
+	}
+	return nil
+}
+
+// String renders the load balancing with the names of the annotations.
+func (l *ingressLoadBalance) String() string {
+	if l.hashBy == "" {
+		return fmt.Sprintf("load-balance %s", l.algorithm)
+	}
+	hashKey := "which maps to no key type"
+	if l.hashKey != nil {
+		hashKey = fmt.Sprintf("hashing the %s", strings.ToLower(l.hashKey.keyType))
+		if l.hashKey.name != "" {
+			hashKey += " " + l.hashKey.name
+		}
+	}
+	return fmt.Sprintf("upstream-hash-by %q, %s", l.hashBy, hashKey)
+}
+
+// envoyGatewayLoadBalancer returns the loadBalancer field of an Envoy Gateway
+// BackendTrafficPolicy, nil when it cannot be converted, along with what is
+// lost in the conversion.
+func (l *ingressLoadBalance) envoyGatewayLoadBalancer() (map[string]interface{}, string) {
+	if l.hashBy == "" {
+		if l.algorithm == ewmaLoadBalance {
+			return map[string]interface{}{"type": "LeastRequest"},
+				"the least request balancing of Envoy favors the backends with the fewest active requests, while ewma favors the ones with the lowest latency"
+		}
+		return map[string]interface{}{"type": "RoundRobin"}, ""
+	}
+
+	var lost []string
+	if l.algorithm != "" {
+		lost = append(lost, fmt.Sprintf("%s %s is ignored, as %s takes precedence, like in ingress-nginx", loadBalanceAnnotation, l.algorithm, upstreamHashByAnnotation))
+	}
+	if l.hashKey == nil {
+		return nil, fmt.Sprintf("%q is not a single $remote_addr, $http_<header>, $cookie_<name> or path variable", l.hashBy)
+	}
+	consistentHash := map[string]interface{}{"type": l.hashKey.keyType}
+	switch l.hashKey.keyType {
+	case pathHashKey:
+		return nil, "the consistent hashing of Envoy Gateway has no path key"
+	case headerHashKey:
+		consistentHash["header"] = map[string]interface{}{"name": l.hashKey.name}
+	case cookieHashKey:
+		consistentHash["cookie"] = map[string]interface{}{"name": l.hashKey.name}
+		lost = append(lost, "the requests without the cookie are balanced at random, as no cookie is generated")
+	}
+	return map[string]interface{}{
+		"type":           "ConsistentHash",
+		"consistentHash": consistentHash,
+	}, strings.Join(lost, ", and ")
+}
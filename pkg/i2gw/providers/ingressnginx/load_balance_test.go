@@ -0,0 +1,157 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingressnginx
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func Test_parseHashKey(t *testing.T) {
+	testCases := []struct {
+		expression string
+		expected   *hashKey
+	}{
+		{expression: "$remote_addr", expected: &hashKey{keyType: sourceIPHashKey}},
+		{expression: "$binary_remote_addr", expected: &hashKey{keyType: sourceIPHashKey}},
+		{expression: "$http_x_user_id", expected: &hashKey{keyType: headerHashKey, name: "x-user-id"}},
+		{expression: "${cookie_session}", expected: &hashKey{keyType: cookieHashKey, name: "session"}},
+		{expression: "$request_uri", expected: &hashKey{keyType: pathHashKey}},
+		{expression: "$host$request_uri"},
+		{expression: "$args"},
+		{expression: "user"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.expression, func(t *testing.T) {
+			if diff := cmp.Diff(tc.expected, parseHashKey(tc.expression), cmp.AllowUnexported(hashKey{})); diff != "" {
+				t.Errorf("Unexpected hash key, diff (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_loadBalanceFeature(t *testing.T) {
+	envoyGateway := map[string]string{TargetImplementationFlag: envoyGatewayImplementation}
+
+	testCases := []struct {
+		name                  string
+		flags                 map[string]string
+		annotations           map[string]string
+		otherAnnotations      map[string]string
+		expectedLoadBalancer  map[string]interface{}
+		expectedNotifications []string
+	}{{
+		name:                 "round robin",
+		flags:                envoyGateway,
+		annotations:          map[string]string{loadBalanceAnnotation: "round_robin"},
+		expectedLoadBalancer: map[string]interface{}{"type": "RoundRobin"},
+	}, {
+		name:                  "ewma",
+		flags:                 envoyGateway,
+		annotations:           map[string]string{loadBalanceAnnotation: "ewma"},
+		expectedLoadBalancer:  map[string]interface{}{"type": "LeastRequest"},
+		expectedNotifications: []string{"while ewma favors the ones with the lowest latency"},
+	}, {
+		name:  "hash by header, taking precedence over load-balance",
+		flags: envoyGateway,
+		annotations: map[string]string{
+			loadBalanceAnnotation:    "ewma",
+			upstreamHashByAnnotation: "$http_x_user_id",
+		},
+		expectedLoadBalancer: map[string]interface{}{
+			"type": "ConsistentHash",
+			"consistentHash": map[string]interface{}{
+				"type":   "Header",
+				"header": map[string]interface{}{"name": "x-user-id"},
+			},
+		},
+		expectedNotifications: []string{"nginx.ingress.kubernetes.io/load-balance ewma is ignored"},
+	}, {
+		name:                  "hash by path",
+		flags:                 envoyGateway,
+		annotations:           map[string]string{upstreamHashByAnnotation: "$request_uri"},
+		expectedNotifications: []string{`the upstream-hash-by "$request_uri", hashing the path of default/a cannot be converted: the consistent hashing of Envoy Gateway has no path key`},
+	}, {
+		name:                  "unmappable expression",
+		flags:                 envoyGateway,
+		annotations:           map[string]string{upstreamHashByAnnotation: "$host$request_uri"},
+		expectedNotifications: []string{`"$host$request_uri" is not a single $remote_addr, $http_<header>, $cookie_<name> or path variable`},
+	}, {
+		name:  "session affinity takes precedence",
+		flags: envoyGateway,
+		annotations: map[string]string{
+			affinityAnnotation:       "cookie",
+			upstreamHashByAnnotation: "$remote_addr",
+		},
+		expectedLoadBalancer: map[string]interface{}{
+			"type": "ConsistentHash",
+			"consistentHash": map[string]interface{}{
+				"type":   "Cookie",
+				"cookie": map[string]interface{}{"name": "INGRESSCOOKIE", "ttl": "0s"},
+			},
+		},
+		expectedNotifications: []string{"are ignored, as nginx.ingress.kubernetes.io/affinity takes precedence"},
+	}, {
+		name:                 "session affinity of another Ingress of the host",
+		flags:                envoyGateway,
+		annotations:          map[string]string{loadBalanceAnnotation: "round_robin"},
+		otherAnnotations:     map[string]string{affinityAnnotation: "cookie"},
+		expectedLoadBalancer: map[string]interface{}{"type": "RoundRobin"},
+		expectedNotifications: []string{
+			"the rules of HTTPRoute default/a-example-com with the policies of default/b are moved to HTTPRoute default/a-example-com-b",
+		},
+	}, {
+		name:                  "no target implementation",
+		annotations:           map[string]string{upstreamHashByAnnotation: "$cookie_session"},
+		expectedNotifications: []string{`manual action required: configure upstream-hash-by "$cookie_session", hashing the cookie session`},
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			conf := &i2gw.ProviderConf{ProviderSpecificFlags: map[string]map[string]string{Name: tc.flags}}
+			ingresses := []networkingv1.Ingress{testIngress("a", "example.com", "/", networkingv1.PathTypePrefix, tc.annotations)}
+			if tc.otherAnnotations != nil {
+				ingresses = append(ingresses, testIngress("b", "example.com", "/b", networkingv1.PathTypePrefix, tc.otherAnnotations))
+			}
+			gatewayResources, notificationTable := convertTestIngresses(t, conf, ingresses...)
+
+			var loadBalancer map[string]interface{}
+			key := i2gw.ExtensionResourceKey{
+				GroupKind:      envoyGatewayBackendTrafficPolicyGVK.GroupKind(),
+				NamespacedName: types.NamespacedName{Namespace: "default", Name: "a-example-com"},
+			}
+			if policy, ok := gatewayResources.ExtensionResources[key]; ok {
+				loadBalancer, _, _ = unstructured.NestedMap(policy.Object, "spec", "loadBalancer")
+			}
+			if diff := cmp.Diff(tc.expectedLoadBalancer, loadBalancer); diff != "" {
+				t.Errorf("Unexpected load balancer, diff (-want +got):\n%s", diff)
+			}
+			for _, expected := range tc.expectedNotifications {
+				if !strings.Contains(notificationTable, expected) {
+					t.Errorf("Expected notification %q, got:\n%s", expected, notificationTable)
+				}
+			}
+		})
+	}
+}
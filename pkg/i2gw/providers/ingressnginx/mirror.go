@@ -0,0 +1,257 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingressnginx
+
+import (
+	"fmt"
+	"net"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/providers/common"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
+)
+
+const (
+	mirrorTargetAnnotation      = "nginx.ingress.kubernetes.io/mirror-target"
+	mirrorRequestBodyAnnotation = "nginx.ingress.kubernetes.io/mirror-request-body"
+	mirrorHostAnnotation        = "nginx.ingress.kubernetes.io/mirror-host"
+)
+
+// ingressMirror is the mirror of the requests to the paths of an Ingress.
+type ingressMirror struct {
+	service types.NamespacedName
+	port    int32
+}
+
+// mirrorFeature converts the mirror-target annotation to RequestMirror
+// filters.
+//
+// ingress-nginx mirrors the requests to any URL, while RequestMirror filters
+// only mirror them to backends, so the host of the URL is resolved to a
+// Service from its in-cluster DNS name: <service>, or
+// <service>.<namespace>.svc with an optional cluster domain. Other hosts are
+// reported, as are the settings of the mirrored requests which cannot be
+// converted: their path, Host header (mirror-host), and whether their body is
+// sent (mirror-request-body).
+//
+// The rules are found by their path match, so this must run before
+// rewriteFeature and regexFeature.
+func mirrorFeature(ingresses []networkingv1.Ingress, gatewayResources *i2gw.GatewayResources) field.ErrorList {
+	var errs field.ErrorList
+	mirrorsByIngress := map[types.NamespacedName]*ingressMirror{}
+	for i := range ingresses {
+		mirror, mirrorErrs := parseMirror(&ingresses[i])
+		errs = append(errs, mirrorErrs...)
+		if mirror != nil {
+			mirrorsByIngress[types.NamespacedName{Namespace: ingresses[i].Namespace, Name: ingresses[i].Name}] = mirror
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+
+	for _, rg := range sortedRuleGroups(ingresses) {
+		key := types.NamespacedName{Namespace: rg.Namespace, Name: common.RouteName(rg.Name, rg.Host)}
+		httpRoute, ok := gatewayResources.HTTPRoutes[key]
+		if !ok {
+			continue
+		}
+
+		sourcesByRule := ruleSourcesByIndex(rg, httpRoute)
+		for _, ruleIdx := range sortedRuleIndexes(sourcesByRule) {
+			mirror := sameMirror(annotationSources(sourcesByRule[ruleIdx]), mirrorsByIngress)
+			if mirror == nil {
+				continue
+			}
+
+			backendRef := gatewayv1.BackendObjectReference{
+				Name: gatewayv1.ObjectName(mirror.service.Name),
+				Port: ptr.To(gatewayv1.PortNumber(mirror.port)),
+			}
+			if mirror.service.Namespace != httpRoute.Namespace {
+				backendRef.Namespace = ptr.To(gatewayv1.Namespace(mirror.service.Namespace))
+				addReferenceGrant(gatewayResources, common.HTTPRouteGVK, httpRoute.Namespace, "Service", mirror.service)
+			}
+			rule := &httpRoute.Spec.Rules[ruleIdx]
+			rule.Filters = append(rule.Filters, gatewayv1.HTTPRouteFilter{
+				Type:          gatewayv1.HTTPRouteFilterRequestMirror,
+				RequestMirror: &gatewayv1.HTTPRequestMirrorFilter{BackendRef: backendRef},
+			})
+		}
+		gatewayResources.HTTPRoutes[key] = httpRoute
+	}
+
+	return nil
+}
+
+// parseMirror returns the mirror of the mirror-target annotation of the
+// Ingress, nil when it sets none or when it cannot be converted.
+func parseMirror(ingress *networkingv1.Ingress) (*ingressMirror, field.ErrorList) {
+	target := ingress.Annotations[mirrorTargetAnnotation]
+	if target == "" {
+		return nil, nil
+	}
+	fieldPath := field.NewPath(ingress.Name).Child("metadata").Child("annotations")
+
+	// A trailing $request_uri keeps the path and query of the request, which
+	// RequestMirror filters always do.
+	rawURL, _ := strings.CutSuffix(target, requestURIVariable)
+	if strings.Contains(rawURL, "$") {
+		notifications.NotificationAggr.DispatchNotification(notifications.NewNotification(
+			notifications.ErrorNotification,
+			fmt.Sprintf("%s %q uses nginx variables, which cannot be converted: the mirror must be configured manually", mirrorTargetAnnotation, target),
+			ingress,
+		), Name)
+		return nil, nil
+	}
+	targetURL, err := common.ParseHTTPURL(rawURL)
+	if err != nil {
+		return nil, field.ErrorList{field.Invalid(fieldPath.Key(mirrorTargetAnnotation), target, err.Error())}
+	}
+
+	service, ok := serviceFromHost(targetURL.Hostname(), ingress.Namespace)
+	if !ok {
+		notifications.NotificationAggr.DispatchNotification(notifications.NewNotification(
+			notifications.ErrorNotification,
+			fmt.Sprintf("%s %q cannot be converted: RequestMirror filters only mirror the requests to backends, and %q is an external host, not the DNS name of a Service: the mirror must be configured with the Gateway implementation",
+				mirrorTargetAnnotation, target, targetURL.Host),
+			ingress,
+		), Name)
+		return nil, nil
+	}
+
+	mirror := &ingressMirror{service: service, port: 80}
+	if targetURL.Scheme == "https" {
+		mirror.port = 443
+	}
+	if port := targetURL.Port(); port != "" {
+		portNumber, err := strconv.ParseInt(port, 10, 32)
+		if err != nil {
+			return nil, field.ErrorList{field.Invalid(fieldPath.Key(mirrorTargetAnnotation), target, err.Error())}
+		}
+		mirror.port = int32(portNumber)
+	}
+
+	var lost []string
+	if path := strings.TrimSuffix(targetURL.Path, "/"); path != "" || targetURL.RawQuery != "" {
+		lost = append(lost, fmt.Sprintf("the mirrored requests keep the path and query of the request instead of %q", targetURL.RequestURI()))
+	}
+	if targetURL.Scheme == "https" {
+		lost = append(lost, "the mirrored requests are sent over TLS only if a BackendTLSPolicy is added for the Service")
+	}
+	if host := ingress.Annotations[mirrorHostAnnotation]; host != "" {
+		lost = append(lost, fmt.Sprintf("the Host header of the mirrored requests cannot be set to %s %q", mirrorHostAnnotation, host))
+	}
+	if ingress.Annotations[mirrorRequestBodyAnnotation] == "off" {
+		lost = append(lost, fmt.Sprintf("the body of the requests is mirrored, as %s off cannot be converted", mirrorRequestBodyAnnotation))
+	}
+
+	message := fmt.Sprintf("%s %q is converted to RequestMirror filters to port %d of Service %s", mirrorTargetAnnotation, target, mirror.port, service)
+	notificationType := notifications.InfoNotification
+	if len(lost) > 0 {
+		message += ", but " + strings.Join(lost, ", and ")
+		notificationType = notifications.WarningNotification
+	}
+	notifications.NotificationAggr.DispatchNotification(notifications.NewNotification(notificationType, message, ingress), Name)
+
+	return mirror, nil
+}
+
+// serviceFromHost returns the Service whose in-cluster DNS name is the host,
+// resolved like nginx does from the namespace of the Ingress: <service>, or
+// <service>.<namespace>.svc followed by the cluster domain. Two-label names
+// are not resolved, as they cannot be told apart from external hosts.
+func serviceFromHost(host, namespace string) (types.NamespacedName, bool) {
+	if net.ParseIP(host) != nil {
+		return types.NamespacedName{}, false
+	}
+	labels := strings.Split(host, ".")
+	switch {
+	case len(labels) == 1:
+		return types.NamespacedName{Namespace: namespace, Name: labels[0]}, true
+	case len(labels) >= 3 && labels[2] == "svc":
+		return types.NamespacedName{Namespace: labels[1], Name: labels[0]}, true
+	}
+	return types.NamespacedName{}, false
+}
+
+// sameMirror returns the mirror of the rule sources, reporting when they set
+// different ones, in which case the one of the first source is used.
+func sameMirror(sources []ruleSource, mirrorsByIngress map[types.NamespacedName]*ingressMirror) *ingressMirror {
+	var mirror *ingressMirror
+	var callingObjects []client.Object
+	same := true
+	for i, source := range sources {
+		callingObjects = append(callingObjects, &sources[i].ingress)
+		sourceMirror := mirrorsByIngress[types.NamespacedName{Namespace: source.ingress.Namespace, Name: source.ingress.Name}]
+		if i == 0 {
+			mirror = sourceMirror
+		} else if !reflect.DeepEqual(mirror, sourceMirror) {
+			same = false
+		}
+	}
+	if !same {
+		notifications.NotificationAggr.DispatchNotification(notifications.NewNotification(
+			notifications.WarningNotification,
+			fmt.Sprintf("Ingresses sharing path %q set different %s annotations, the one of %s/%s is used", sources[0].path.Path, mirrorTargetAnnotation, sources[0].ingress.Namespace, sources[0].ingress.Name),
+			callingObjects...,
+		), Name)
+	}
+	return mirror
+}
+
+// addReferenceGrant allows the resources of the kind fromGVK in the namespace
+// fromNamespace to reference the object to, unless already allowed.
+func addReferenceGrant(gatewayResources *i2gw.GatewayResources, fromGVK schema.GroupVersionKind, fromNamespace string, toKind gatewayv1.Kind, to types.NamespacedName) {
+	name := fmt.Sprintf("from-%s-to-%s-%s", fromNamespace, strings.ToLower(string(toKind)), to.Name)
+	key := types.NamespacedName{Namespace: to.Namespace, Name: name}
+	if gatewayResources.ReferenceGrants == nil {
+		gatewayResources.ReferenceGrants = map[types.NamespacedName]gatewayv1beta1.ReferenceGrant{}
+	}
+	if _, ok := gatewayResources.ReferenceGrants[key]; ok {
+		return
+	}
+
+	referenceGrant := gatewayv1beta1.ReferenceGrant{
+		ObjectMeta: metav1.ObjectMeta{Namespace: key.Namespace, Name: key.Name},
+		Spec: gatewayv1beta1.ReferenceGrantSpec{
+			From: []gatewayv1beta1.ReferenceGrantFrom{{
+				Group:     gatewayv1.Group(fromGVK.Group),
+				Kind:      gatewayv1.Kind(fromGVK.Kind),
+				Namespace: gatewayv1.Namespace(fromNamespace),
+			}},
+			To: []gatewayv1beta1.ReferenceGrantTo{{
+				Kind: toKind,
+				Name: ptr.To(gatewayv1.ObjectName(to.Name)),
+			}},
+		},
+	}
+	referenceGrant.SetGroupVersionKind(common.ReferenceGrantGVK)
+	gatewayResources.ReferenceGrants[key] = referenceGrant
+}
@@ -0,0 +1,115 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingressnginx
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/types"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+func Test_mirrorFeature(t *testing.T) {
+	testCases := []struct {
+		name                    string
+		annotations             map[string]string
+		expectedFilters         []gatewayv1.HTTPRouteFilter
+		expectedReferenceGrants []types.NamespacedName
+		expectedNotification    string
+	}{{
+		name: "Service of the Ingress namespace",
+		annotations: map[string]string{
+			mirrorTargetAnnotation: "http://shadow:8080$request_uri",
+		},
+		expectedFilters: []gatewayv1.HTTPRouteFilter{{
+			Type: gatewayv1.HTTPRouteFilterRequestMirror,
+			RequestMirror: &gatewayv1.HTTPRequestMirrorFilter{
+				BackendRef: gatewayv1.BackendObjectReference{Name: "shadow", Port: ptrTo(gatewayv1.PortNumber(8080))},
+			},
+		}},
+		expectedNotification: "is converted to RequestMirror filters to port 8080 of Service default/shadow",
+	}, {
+		name: "Service of another namespace",
+		annotations: map[string]string{
+			mirrorTargetAnnotation: "https://shadow.testing.svc.cluster.local/mirror",
+			mirrorHostAnnotation:   "shadow.example.com",
+		},
+		expectedFilters: []gatewayv1.HTTPRouteFilter{{
+			Type: gatewayv1.HTTPRouteFilterRequestMirror,
+			RequestMirror: &gatewayv1.HTTPRequestMirrorFilter{
+				BackendRef: gatewayv1.BackendObjectReference{
+					Name:      "shadow",
+					Namespace: ptrTo(gatewayv1.Namespace("testing")),
+					Port:      ptrTo(gatewayv1.PortNumber(443)),
+				},
+			},
+		}},
+		expectedReferenceGrants: []types.NamespacedName{{Namespace: "testing", Name: "from-default-to-service-shadow"}},
+		expectedNotification:    "the Host header of the mirrored requests cannot be set",
+	}, {
+		name: "external host",
+		annotations: map[string]string{
+			mirrorTargetAnnotation: "https://shadow.example.com$request_uri",
+		},
+		expectedNotification: "is an external host",
+	}, {
+		name: "redirected paths are not mirrored",
+		annotations: map[string]string{
+			mirrorTargetAnnotation:      "http://shadow$request_uri",
+			permanentRedirectAnnotation: "https://www.example.com",
+		},
+		expectedFilters: []gatewayv1.HTTPRouteFilter{{
+			Type: gatewayv1.HTTPRouteFilterRequestRedirect,
+			RequestRedirect: &gatewayv1.HTTPRequestRedirectFilter{
+				Scheme:     ptrTo("https"),
+				Hostname:   ptrTo(gatewayv1.PreciseHostname("www.example.com")),
+				Path:       &gatewayv1.HTTPPathModifier{Type: gatewayv1.FullPathHTTPPathModifier, ReplaceFullPath: ptrTo("/")},
+				StatusCode: ptrTo(301),
+			},
+		}},
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			gatewayResources, notificationTable := convertTestIngresses(t, &i2gw.ProviderConf{},
+				testIngress("a", "example.com", "/", networkingv1.PathTypePrefix, tc.annotations))
+
+			key := types.NamespacedName{Namespace: "default", Name: "a-example-com"}
+			httpRoute, ok := gatewayResources.HTTPRoutes[key]
+			if !ok || len(httpRoute.Spec.Rules) != 1 {
+				t.Fatalf("Expected HTTPRoute %s with a single rule, got %+v", key, gatewayResources.HTTPRoutes)
+			}
+			if diff := cmp.Diff(tc.expectedFilters, httpRoute.Spec.Rules[0].Filters); diff != "" {
+				t.Errorf("Unexpected filters, diff (-want +got):\n%s", diff)
+			}
+			var referenceGrants []types.NamespacedName
+			for key := range gatewayResources.ReferenceGrants {
+				referenceGrants = append(referenceGrants, key)
+			}
+			if diff := cmp.Diff(tc.expectedReferenceGrants, referenceGrants); diff != "" {
+				t.Errorf("Unexpected ReferenceGrants, diff (-want +got):\n%s", diff)
+			}
+			if !strings.Contains(notificationTable, tc.expectedNotification) {
+				t.Errorf("Expected notification %q, got:\n%s", tc.expectedNotification, notificationTable)
+			}
+		})
+	}
+}
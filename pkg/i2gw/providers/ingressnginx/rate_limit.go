@@ -0,0 +1,220 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingressnginx
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+const (
+	limitRPSAnnotation             = "nginx.ingress.kubernetes.io/limit-rps"
+	limitRPMAnnotation             = "nginx.ingress.kubernetes.io/limit-rpm"
+	limitConnectionsAnnotation     = "nginx.ingress.kubernetes.io/limit-connections"
+	limitBurstMultiplierAnnotation = "nginx.ingress.kubernetes.io/limit-burst-multiplier"
+	limitWhitelistAnnotation       = "nginx.ingress.kubernetes.io/limit-whitelist"
+
+	// The default of ingress-nginx.
+	defaultLimitBurstMultiplier = 5
+)
+
+// ingressRateLimit is the rate limiting of the requests of each client
+// address to the paths of an Ingress.
+type ingressRateLimit struct {
+	rps, rpm    int
+	connections int
+	// burstMultiplier gives the burst of the limits of nginx, which allows
+	// limit * burstMultiplier requests above them.
+	burstMultiplier int
+	whitelist       []string
+}
+
+// rateLimitFeature converts the rate limiting annotations.
+//
+// The Gateway API has no rate limiting, so they are converted to the rate
+// limit of the BackendTrafficPolicy of the rules of each Ingress, recorded in
+// routePolicies, with the envoy-gateway target implementation. nginx limits
+// the requests of each client address, which requires the Global rate limit
+// of Envoy Gateway. Without a target implementation, the limits of each
+// Ingress are reported, to be configured manually.
+//
+// As ingress-nginx answers the redirects before limiting the requests, this
+// must run after redirectFeature, whose rules are skipped.
+func rateLimitFeature(target string, policies *routePolicies) i2gw.FeatureParser {
+	return func(ingresses []networkingv1.Ingress, gatewayResources *i2gw.GatewayResources) field.ErrorList {
+		var errs field.ErrorList
+		rateLimitByIngress := map[types.NamespacedName]*ingressRateLimit{}
+		for i := range ingresses {
+			rateLimit, parseErrs := parseRateLimit(ingresses[i])
+			errs = append(errs, parseErrs...)
+			if rateLimit == nil {
+				continue
+			}
+			rateLimitByIngress[types.NamespacedName{Namespace: ingresses[i].Namespace, Name: ingresses[i].Name}] = rateLimit
+			if target == "" {
+				notifyManualAction(&ingresses[i], "rate limiting is unsupported by the Gateway API", rateLimit)
+			}
+		}
+		if len(errs) > 0 || target == "" {
+			return errs
+		}
+
+		converted := map[types.NamespacedName]bool{}
+		forEachRuleSource(ingresses, gatewayResources, true, func(key types.NamespacedName, ruleIdx int, source ruleSource) {
+			ingressKey := types.NamespacedName{Namespace: source.ingress.Namespace, Name: source.ingress.Name}
+			rateLimit := rateLimitByIngress[ingressKey]
+			if rateLimit == nil {
+				return
+			}
+			if rules := rateLimit.envoyGatewayRules(); len(rules) > 0 {
+				policies.set(key, ruleIdx, source, envoyGatewayBackendTrafficPolicyGVK, "rateLimit", map[string]interface{}{
+					"type":   "Global",
+					"global": map[string]interface{}{"rules": rules},
+				})
+			}
+			if !converted[ingressKey] {
+				converted[ingressKey] = true
+				notifyRateLimit(rateLimit, &source.ingress)
+			}
+		})
+
+		return nil
+	}
+}
+
+// notifyRateLimit reports the conversion of the rate limiting of the Ingress,
+// and each of its settings which are lost.
+func notifyRateLimit(rateLimit *ingressRateLimit, ingress *networkingv1.Ingress) {
+	var lost []string
+	if len(rateLimit.envoyGatewayRules()) > 0 {
+		notifications.NotificationAggr.DispatchNotification(notifications.NewNotification(
+			notifications.InfoNotification,
+			fmt.Sprintf("the rate limits of %s/%s are converted to the Global rate limit of the BackendTrafficPolicy of the HTTPRoutes of its rules, which requires the rate limit service of Envoy Gateway to be enabled, with its Redis",
+				ingress.Namespace, ingress.Name),
+			ingress,
+		), Name)
+		lost = append(lost,
+			"the limits of ingress-nginx apply per controller replica, while the Global rate limit applies to all the Envoy proxies together: multiply the limits by the number of controller replicas to keep the same total",
+			fmt.Sprintf("the policy is stricter than ingress-nginx, as Envoy Gateway has no burst: the bursts of %s above the limits are rejected", rateLimit.bursts()))
+	}
+	if rateLimit.connections > 0 {
+		lost = append(lost, fmt.Sprintf("%s %d cannot be converted, as it has no equivalent", limitConnectionsAnnotation, rateLimit.connections))
+	}
+	if len(rateLimit.whitelist) > 0 {
+		lost = append(lost, fmt.Sprintf("the addresses of %s [%s] cannot be exempted from the limits", limitWhitelistAnnotation, strings.Join(rateLimit.whitelist, ", ")))
+	}
+	for _, message := range lost {
+		notifications.NotificationAggr.DispatchNotification(notifications.NewNotification(notifications.WarningNotification, message, ingress), Name)
+	}
+}
+
+// parseRateLimit returns the rate limiting of the Ingress, nil when it sets
+// no limit.
+func parseRateLimit(ingress networkingv1.Ingress) (*ingressRateLimit, field.ErrorList) {
+	var errs field.ErrorList
+	fieldPath := field.NewPath(ingress.Name).Child("metadata").Child("annotations")
+	parseInt := func(annotation string, defaultValue int) int {
+		value, ok := ingress.Annotations[annotation]
+		if !ok {
+			return defaultValue
+		}
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 0 {
+			errs = append(errs, field.Invalid(fieldPath.Key(annotation), value, "must be a positive number"))
+		}
+		return parsed
+	}
+
+	rateLimit := &ingressRateLimit{
+		rps:             parseInt(limitRPSAnnotation, 0),
+		rpm:             parseInt(limitRPMAnnotation, 0),
+		connections:     parseInt(limitConnectionsAnnotation, 0),
+		burstMultiplier: parseInt(limitBurstMultiplierAnnotation, defaultLimitBurstMultiplier),
+	}
+	// As ingress-nginx, a multiplier of 0 falls back to the default.
+	if rateLimit.burstMultiplier == 0 {
+		rateLimit.burstMultiplier = defaultLimitBurstMultiplier
+	}
+	for _, value := range splitList(ingress.Annotations[limitWhitelistAnnotation]) {
+		cidr, err := parseCIDR(value)
+		if err != nil {
+			errs = append(errs, field.Invalid(fieldPath.Key(limitWhitelistAnnotation), ingress.Annotations[limitWhitelistAnnotation], err.Error()))
+			break
+		}
+		rateLimit.whitelist = append(rateLimit.whitelist, cidr)
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	if rateLimit.rps == 0 && rateLimit.rpm == 0 && rateLimit.connections == 0 {
+		return nil, nil
+	}
+	return rateLimit, nil
+}
+
+// bursts renders the bursts of nginx, limit * burst multiplier.
+func (r *ingressRateLimit) bursts() string {
+	var bursts []string
+	if r.rps > 0 {
+		bursts = append(bursts, fmt.Sprintf("%d requests per second", r.rps*r.burstMultiplier))
+	}
+	if r.rpm > 0 {
+		bursts = append(bursts, fmt.Sprintf("%d requests per minute", r.rpm*r.burstMultiplier))
+	}
+	return strings.Join(bursts, " and ")
+}
+
+// String renders the limits with the names of the annotations.
+func (r *ingressRateLimit) String() string {
+	bursts := r.bursts()
+	if bursts == "" {
+		bursts = "no requests"
+	}
+	return fmt.Sprintf("limit-rps %d, limit-rpm %d, limit-connections %d, limit-burst-multiplier %d (bursts of %s), limit-whitelist [%s]",
+		r.rps, r.rpm, r.connections, r.burstMultiplier, bursts, strings.Join(r.whitelist, ", "))
+}
+
+// envoyGatewayRules returns the rules of the Global rate limit of an Envoy
+// Gateway BackendTrafficPolicy, limiting each client address, IPv4 or IPv6,
+// as nginx.
+func (r *ingressRateLimit) envoyGatewayRules() []interface{} {
+	var rules []interface{}
+	for _, limit := range []struct {
+		requests int
+		unit     string
+	}{{r.rps, "Second"}, {r.rpm, "Minute"}} {
+		if limit.requests == 0 {
+			continue
+		}
+		for _, cidr := range []string{"0.0.0.0/0", "::/0"} {
+			rules = append(rules, map[string]interface{}{
+				"clientSelectors": []interface{}{map[string]interface{}{
+					"sourceCIDR": map[string]interface{}{"type": "Distinct", "value": cidr},
+				}},
+				"limit": map[string]interface{}{"requests": int64(limit.requests), "unit": limit.unit},
+			})
+		}
+	}
+	return rules
+}
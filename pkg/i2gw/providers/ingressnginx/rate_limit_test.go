@@ -0,0 +1,151 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingressnginx
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func Test_rateLimitFeature(t *testing.T) {
+	envoyGateway := map[string]string{TargetImplementationFlag: envoyGatewayImplementation}
+	clientRules := func(requests int64, unit string) []interface{} {
+		var rules []interface{}
+		for _, cidr := range []string{"0.0.0.0/0", "::/0"} {
+			rules = append(rules, map[string]interface{}{
+				"clientSelectors": []interface{}{map[string]interface{}{
+					"sourceCIDR": map[string]interface{}{"type": "Distinct", "value": cidr},
+				}},
+				"limit": map[string]interface{}{"requests": requests, "unit": unit},
+			})
+		}
+		return rules
+	}
+
+	testCases := []struct {
+		name                  string
+		flags                 map[string]string
+		annotations           map[string]string
+		sharedHost            bool
+		expectedRateLimit     map[string]interface{}
+		expectedNotifications []string
+	}{{
+		name:  "requests per second and minute",
+		flags: envoyGateway,
+		annotations: map[string]string{
+			limitRPSAnnotation:             "10",
+			limitRPMAnnotation:             "300",
+			limitBurstMultiplierAnnotation: "3",
+		},
+		expectedRateLimit: map[string]interface{}{
+			"type": "Global",
+			"global": map[string]interface{}{
+				"rules": append(clientRules(10, "Second"), clientRules(300, "Minute")...),
+			},
+		},
+		expectedNotifications: []string{
+			"the rate limits of default/a are converted to the Global rate limit of the BackendTrafficPolicy",
+			"the limits of ingress-nginx apply per controller replica",
+			"multiply the limits by the number of controller replicas to keep the same total",
+			"the policy is stricter than ingress-nginx, as Envoy Gateway has no burst: the bursts of 30 requests per second and 900 requests per minute above the limits are rejected",
+		},
+	}, {
+		name:  "shared with the paths of another Ingress",
+		flags: envoyGateway,
+		annotations: map[string]string{
+			limitRPSAnnotation: "10",
+		},
+		sharedHost: true,
+		expectedRateLimit: map[string]interface{}{
+			"type":   "Global",
+			"global": map[string]interface{}{"rules": clientRules(10, "Second")},
+		},
+		expectedNotifications: []string{
+			"the rules of HTTPRoute default/a-example-com with the policies of default/a are moved to HTTPRoute default/a-example-com-a",
+		},
+	}, {
+		name:  "connections and whitelist",
+		flags: envoyGateway,
+		annotations: map[string]string{
+			limitRPSAnnotation:         "5",
+			limitConnectionsAnnotation: "20",
+			limitWhitelistAnnotation:   "10.0.0.0/8",
+		},
+		expectedRateLimit: map[string]interface{}{
+			"type":   "Global",
+			"global": map[string]interface{}{"rules": clientRules(5, "Second")},
+		},
+		expectedNotifications: []string{
+			"the bursts of 25 requests per second",
+			"nginx.ingress.kubernetes.io/limit-connections 20 cannot be converted, as it has no equivalent",
+			"the addresses of nginx.ingress.kubernetes.io/limit-whitelist [10.0.0.0/8] cannot be exempted",
+		},
+	}, {
+		name: "no target implementation",
+		annotations: map[string]string{
+			limitRPMAnnotation: "60",
+		},
+		expectedNotifications: []string{
+			"rate limiting is unsupported by the Gateway API, manual action required: configure limit-rps 0, limit-rpm 60, limit-connections 0, limit-burst-multiplier 5 (bursts of 300 requests per minute)",
+		},
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			conf := &i2gw.ProviderConf{ProviderSpecificFlags: map[string]map[string]string{Name: tc.flags}}
+			ingresses := []networkingv1.Ingress{testIngress("a", "example.com", "/", networkingv1.PathTypePrefix, tc.annotations)}
+			routeName := "a-example-com"
+			if tc.sharedHost {
+				ingresses = append(ingresses, testIngress("b", "example.com", "/b", networkingv1.PathTypePrefix, nil))
+				routeName = "a-example-com-a"
+			}
+			gatewayResources, notificationTable := convertTestIngresses(t, conf, ingresses...)
+
+			if tc.sharedHost {
+				unlimitedKey := i2gw.ExtensionResourceKey{
+					GroupKind:      envoyGatewayBackendTrafficPolicyGVK.GroupKind(),
+					NamespacedName: types.NamespacedName{Namespace: "default", Name: "a-example-com"},
+				}
+				if _, ok := gatewayResources.ExtensionResources[unlimitedKey]; ok {
+					t.Errorf("Expected no BackendTrafficPolicy for the paths of the Ingress without limits")
+				}
+			}
+			var rateLimit map[string]interface{}
+			key := i2gw.ExtensionResourceKey{
+				GroupKind:      envoyGatewayBackendTrafficPolicyGVK.GroupKind(),
+				NamespacedName: types.NamespacedName{Namespace: "default", Name: routeName},
+			}
+			if policy, ok := gatewayResources.ExtensionResources[key]; ok {
+				rateLimit, _, _ = unstructured.NestedMap(policy.Object, "spec", "rateLimit")
+			}
+			if diff := cmp.Diff(tc.expectedRateLimit, rateLimit); diff != "" {
+				t.Errorf("Unexpected rate limit, diff (-want +got):\n%s", diff)
+			}
+			for _, expected := range tc.expectedNotifications {
+				if !strings.Contains(notificationTable, expected) {
+					t.Errorf("Expected notification %q, got:\n%s", expected, notificationTable)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,348 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingressnginx
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/providers/common"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+const (
+	permanentRedirectAnnotation     = "nginx.ingress.kubernetes.io/permanent-redirect"
+	permanentRedirectCodeAnnotation = "nginx.ingress.kubernetes.io/permanent-redirect-code"
+	temporalRedirectAnnotation      = "nginx.ingress.kubernetes.io/temporal-redirect"
+	appRootAnnotation               = "nginx.ingress.kubernetes.io/app-root"
+
+	permanentRedirectCode = 301
+	temporalRedirectCode  = 302
+	// appRootRedirectCode is the status code ingress-nginx redirects the root
+	// path to the app-root with.
+	appRootRedirectCode = 302
+
+	// requestURIVariable is the nginx variable commonly appended to redirect
+	// URLs to keep the path and query of the request, which is what
+	// RequestRedirect filters without a path modifier do.
+	requestURIVariable = "$request_uri"
+)
+
+// redirectFeature converts the permanent-redirect, permanent-redirect-code,
+// temporal-redirect and app-root annotations.
+//
+// ingress-nginx answers the requests to the paths of a redirected Ingress
+// itself, so their rules are replaced by rules with a single RequestRedirect
+// filter and no backendRefs. app-root redirects the requests to the root path
+// only, which is converted to a rule with an Exact "/" match.
+//
+// The rules are found by their path match, so this must run before
+// rewriteFeature and regexFeature, and after the features adding filters to
+// the rules, which the redirect rules drop.
+func redirectFeature(ingresses []networkingv1.Ingress, gatewayResources *i2gw.GatewayResources) field.ErrorList {
+	var errs field.ErrorList
+	redirectsByIngress := map[types.NamespacedName]*gatewayv1.HTTPRequestRedirectFilter{}
+	appRootsByIngress := map[types.NamespacedName]string{}
+	for i := range ingresses {
+		ingressKey := types.NamespacedName{Namespace: ingresses[i].Namespace, Name: ingresses[i].Name}
+		redirect, redirectErrs := parseRedirect(&ingresses[i])
+		errs = append(errs, redirectErrs...)
+		if redirect != nil {
+			redirectsByIngress[ingressKey] = redirect
+		}
+		appRoot, appRootErrs := parseAppRoot(ingresses[i])
+		errs = append(errs, appRootErrs...)
+		if appRoot != "" {
+			appRootsByIngress[ingressKey] = appRoot
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+
+	for _, rg := range sortedRuleGroups(ingresses) {
+		key := types.NamespacedName{Namespace: rg.Namespace, Name: common.RouteName(rg.Name, rg.Host)}
+		httpRoute, ok := gatewayResources.HTTPRoutes[key]
+		if !ok {
+			continue
+		}
+
+		var appRoot string
+		var appRootSources []ruleSource
+		sourcesByRule := ruleSourcesByIndex(rg, httpRoute)
+		for _, ruleIdx := range sortedRuleIndexes(sourcesByRule) {
+			sources := annotationSources(sourcesByRule[ruleIdx])
+			if sources[0].path.Path == "/" && appRootSources == nil {
+				appRootSources = sources
+				appRoot = appRootsByIngress[types.NamespacedName{Namespace: sources[0].ingress.Namespace, Name: sources[0].ingress.Name}]
+			}
+
+			redirect := sameRedirect(sources, redirectsByIngress)
+			if redirect == nil {
+				continue
+			}
+			httpRoute.Spec.Rules[ruleIdx] = gatewayv1.HTTPRouteRule{
+				Matches: httpRoute.Spec.Rules[ruleIdx].Matches,
+				Filters: []gatewayv1.HTTPRouteFilter{{
+					Type:            gatewayv1.HTTPRouteFilterRequestRedirect,
+					RequestRedirect: redirect.DeepCopy(),
+				}},
+			}
+		}
+
+		notifyIgnoredAppRoots(rg, appRootSources, appRootsByIngress)
+		if appRoot != "" {
+			addAppRootRule(&httpRoute, appRoot)
+		}
+		gatewayResources.HTTPRoutes[key] = httpRoute
+	}
+
+	return nil
+}
+
+// parseRedirect returns the RequestRedirect filter of the permanent-redirect
+// or temporal-redirect annotation of the Ingress, nil when it sets none or
+// when it cannot be converted. Like ingress-nginx, temporal-redirect takes
+// precedence, and the URLs must be valid http or https URLs.
+func parseRedirect(ingress *networkingv1.Ingress) (*gatewayv1.HTTPRequestRedirectFilter, field.ErrorList) {
+	fieldPath := field.NewPath(ingress.Name).Child("metadata").Child("annotations")
+	annotation := temporalRedirectAnnotation
+	value := ingress.Annotations[temporalRedirectAnnotation]
+	code := temporalRedirectCode
+	if value == "" {
+		annotation = permanentRedirectAnnotation
+		value = ingress.Annotations[permanentRedirectAnnotation]
+		code = permanentRedirectCode
+		if codeValue, ok := ingress.Annotations[permanentRedirectCodeAnnotation]; ok {
+			parsed, err := strconv.Atoi(codeValue)
+			if err != nil || parsed < 300 || parsed > 308 {
+				// Like ingress-nginx, invalid codes fall back to the default.
+				notifications.NotificationAggr.DispatchNotification(notifications.NewNotification(
+					notifications.WarningNotification,
+					fmt.Sprintf("%s %q is not a redirect status code, between 300 and 308, status code %d is used instead", permanentRedirectCodeAnnotation, codeValue, permanentRedirectCode),
+					ingress,
+				), Name)
+			} else {
+				code = parsed
+			}
+		}
+	}
+	if value == "" {
+		return nil, nil
+	}
+
+	filter, lost, unconverted, err := redirectFilter(value, code)
+	if err != nil {
+		return nil, field.ErrorList{field.Invalid(fieldPath.Key(annotation), value, err.Error())}
+	}
+	if unconverted != "" {
+		notifications.NotificationAggr.DispatchNotification(notifications.NewNotification(
+			notifications.ErrorNotification,
+			fmt.Sprintf("%s %q %s, which cannot be converted: the redirect must be configured manually", annotation, value, unconverted),
+			ingress,
+		), Name)
+		return nil, nil
+	}
+	code = *filter.StatusCode
+
+	message := fmt.Sprintf("%s %q is converted to RequestRedirect rules with status code %d, without the backends of the paths of the Ingress", annotation, value, code)
+	notificationType := notifications.InfoNotification
+	if len(lost) > 0 {
+		message += ", but " + strings.Join(lost, ", and ")
+		notificationType = notifications.WarningNotification
+	}
+	notifications.NotificationAggr.DispatchNotification(notifications.NewNotification(notificationType, message, ingress), Name)
+
+	return filter, nil
+}
+
+// redirectFilter returns the RequestRedirect filter redirecting to the URL
+// with the status code, along with what is lost in the conversion, or why it
+// cannot be converted. The URL must be a valid http or https URL.
+func redirectFilter(value string, code int) (*gatewayv1.HTTPRequestRedirectFilter, []string, string, error) {
+	// A trailing $request_uri keeps the path and query of the request, which
+	// is the default of RequestRedirect filters.
+	rawURL, keepRequestURI := strings.CutSuffix(value, requestURIVariable)
+	if strings.Contains(rawURL, "$") {
+		return nil, nil, "uses nginx variables", nil
+	}
+
+	redirectURL, err := common.ParseHTTPURL(rawURL)
+	if err != nil {
+		return nil, nil, "", err
+	}
+	if keepRequestURI && strings.TrimSuffix(redirectURL.Path, "/") != "" {
+		return nil, nil, "prefixes the path of the request", nil
+	}
+
+	filter := &gatewayv1.HTTPRequestRedirectFilter{
+		Scheme:   ptr.To(redirectURL.Scheme),
+		Hostname: ptr.To(gatewayv1.PreciseHostname(redirectURL.Hostname())),
+	}
+	if port := redirectURL.Port(); port != "" {
+		portNumber, err := strconv.Atoi(port)
+		if err != nil {
+			return nil, nil, "", err
+		}
+		// The port is left out of the Location header when it is the default
+		// one of the scheme.
+		if !(redirectURL.Scheme == "http" && portNumber == 80) && !(redirectURL.Scheme == "https" && portNumber == 443) {
+			filter.Port = ptr.To(gatewayv1.PortNumber(portNumber))
+		}
+	}
+	if !keepRequestURI {
+		// nginx redirects to the URL as is, without the path of the request.
+		path := redirectURL.Path
+		if path == "" {
+			path = "/"
+		}
+		filter.Path = &gatewayv1.HTTPPathModifier{
+			Type:            gatewayv1.FullPathHTTPPathModifier,
+			ReplaceFullPath: ptr.To(path),
+		}
+	}
+
+	var lost []string
+	switch code {
+	case permanentRedirectCode, temporalRedirectCode:
+	case 303, 307:
+		lost = append(lost, fmt.Sprintf("status code %d is converted to %d, as HTTPRoute redirects only use 301 or 302", code, temporalRedirectCode))
+		code = temporalRedirectCode
+	default:
+		lost = append(lost, fmt.Sprintf("status code %d is converted to %d, as HTTPRoute redirects only use 301 or 302", code, permanentRedirectCode))
+		code = permanentRedirectCode
+	}
+	filter.StatusCode = ptr.To(code)
+	if redirectURL.RawQuery != "" || redirectURL.Fragment != "" {
+		lost = append(lost, "its query and fragment are dropped, as RequestRedirect filters cannot set them")
+	}
+	return filter, lost, "", nil
+}
+
+// parseAppRoot returns the app-root annotation of the Ingress, which must be
+// an absolute path.
+func parseAppRoot(ingress networkingv1.Ingress) (string, field.ErrorList) {
+	appRoot := ingress.Annotations[appRootAnnotation]
+	if appRoot == "" {
+		return "", nil
+	}
+	if parsed, err := url.Parse(appRoot); err != nil || !strings.HasPrefix(appRoot, "/") || parsed.Path != appRoot {
+		fieldPath := field.NewPath(ingress.Name).Child("metadata").Child("annotations")
+		return "", field.ErrorList{field.Invalid(fieldPath.Key(appRootAnnotation), appRoot, "must be an absolute path, without query")}
+	}
+	return appRoot, nil
+}
+
+// sameRedirect returns the redirect of the rule sources, reporting when they
+// set different ones, in which case the one of the first source is used.
+func sameRedirect(sources []ruleSource, redirectsByIngress map[types.NamespacedName]*gatewayv1.HTTPRequestRedirectFilter) *gatewayv1.HTTPRequestRedirectFilter {
+	var redirect *gatewayv1.HTTPRequestRedirectFilter
+	var callingObjects []client.Object
+	same := true
+	for i, source := range sources {
+		callingObjects = append(callingObjects, &sources[i].ingress)
+		sourceRedirect := redirectsByIngress[types.NamespacedName{Namespace: source.ingress.Namespace, Name: source.ingress.Name}]
+		if i == 0 {
+			redirect = sourceRedirect
+		} else if !reflect.DeepEqual(redirect, sourceRedirect) {
+			same = false
+		}
+	}
+	if !same {
+		notifications.NotificationAggr.DispatchNotification(notifications.NewNotification(
+			notifications.WarningNotification,
+			fmt.Sprintf("Ingresses sharing path %q set different redirects, the one of %s/%s is used", sources[0].path.Path, sources[0].ingress.Namespace, sources[0].ingress.Name),
+			callingObjects...,
+		), Name)
+	}
+	return redirect
+}
+
+// notifyIgnoredAppRoots reports the app-root annotations which ingress-nginx
+// ignores, as they are only applied by the location of the root path.
+func notifyIgnoredAppRoots(rg common.IngressRuleGroup, rootSources []ruleSource, appRootsByIngress map[types.NamespacedName]string) {
+	applied := types.NamespacedName{}
+	if rootSources != nil {
+		applied = types.NamespacedName{Namespace: rootSources[0].ingress.Namespace, Name: rootSources[0].ingress.Name}
+	}
+	notified := map[types.NamespacedName]bool{}
+	for _, rule := range rg.Rules {
+		ingressKey := types.NamespacedName{Namespace: rule.Ingress.Namespace, Name: rule.Ingress.Name}
+		if appRootsByIngress[ingressKey] == "" || ingressKey == applied || notified[ingressKey] {
+			continue
+		}
+		notified[ingressKey] = true
+		ingress := rule.Ingress
+		notifications.NotificationAggr.DispatchNotification(notifications.NewNotification(
+			notifications.WarningNotification,
+			fmt.Sprintf("%s %q is not converted: it only applies to the Ingress serving the root path \"/\" of host %q", appRootAnnotation, appRootsByIngress[ingressKey], rg.Host),
+			&ingress,
+		), Name)
+	}
+}
+
+// addAppRootRule redirects the requests to the root path of the HTTPRoute to
+// the app root, replacing the rule matching exactly "/" if any.
+func addAppRootRule(httpRoute *gatewayv1.HTTPRoute, appRoot string) {
+	rootMatch := gatewayv1.HTTPRouteMatch{
+		Path: &gatewayv1.HTTPPathMatch{
+			Type:  ptr.To(gatewayv1.PathMatchExact),
+			Value: ptr.To("/"),
+		},
+	}
+	rule := gatewayv1.HTTPRouteRule{
+		Matches: []gatewayv1.HTTPRouteMatch{rootMatch},
+		Filters: []gatewayv1.HTTPRouteFilter{{
+			Type: gatewayv1.HTTPRouteFilterRequestRedirect,
+			RequestRedirect: &gatewayv1.HTTPRequestRedirectFilter{
+				Path: &gatewayv1.HTTPPathModifier{
+					Type:            gatewayv1.FullPathHTTPPathModifier,
+					ReplaceFullPath: ptr.To(appRoot),
+				},
+				StatusCode: ptr.To(appRootRedirectCode),
+			},
+		}},
+	}
+	for _, ruleIdx := range findHTTPRouteRules(*httpRoute, networkingv1.HTTPIngressPath{Path: "/", PathType: ptr.To(networkingv1.PathTypeExact)}) {
+		if len(httpRoute.Spec.Rules[ruleIdx].Matches) == 1 {
+			httpRoute.Spec.Rules[ruleIdx] = rule
+			return
+		}
+	}
+	httpRoute.Spec.Rules = append(httpRoute.Spec.Rules, rule)
+}
+
+// hasRequestRedirect returns whether the rule redirects the requests instead
+// of forwarding them, in which case no other filter may modify them.
+func hasRequestRedirect(rule gatewayv1.HTTPRouteRule) bool {
+	for _, filter := range rule.Filters {
+		if filter.Type == gatewayv1.HTTPRouteFilterRequestRedirect {
+			return true
+		}
+	}
+	return false
+}
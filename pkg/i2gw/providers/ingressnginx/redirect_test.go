@@ -0,0 +1,201 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingressnginx
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/types"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+func Test_redirectFeature(t *testing.T) {
+	prefixMatch := func(path string) []gatewayv1.HTTPRouteMatch {
+		return []gatewayv1.HTTPRouteMatch{{
+			Path: &gatewayv1.HTTPPathMatch{Type: ptrTo(gatewayv1.PathMatchPathPrefix), Value: ptrTo(path)},
+		}}
+	}
+	redirectRule := func(matches []gatewayv1.HTTPRouteMatch, redirect gatewayv1.HTTPRequestRedirectFilter) gatewayv1.HTTPRouteRule {
+		return gatewayv1.HTTPRouteRule{
+			Matches: matches,
+			Filters: []gatewayv1.HTTPRouteFilter{{
+				Type:            gatewayv1.HTTPRouteFilterRequestRedirect,
+				RequestRedirect: &redirect,
+			}},
+		}
+	}
+	backendRule := func(path string) gatewayv1.HTTPRouteRule {
+		return gatewayv1.HTTPRouteRule{
+			Matches: prefixMatch(path),
+			BackendRefs: []gatewayv1.HTTPBackendRef{{
+				BackendRef: gatewayv1.BackendRef{
+					BackendObjectReference: gatewayv1.BackendObjectReference{Name: "test", Port: ptrTo(gatewayv1.PortNumber(80))},
+				},
+			}},
+		}
+	}
+
+	testCases := []struct {
+		name                  string
+		ingresses             []networkingv1.Ingress
+		expectedRules         []gatewayv1.HTTPRouteRule
+		expectedNotifications []string
+		expectedErrors        int
+	}{{
+		name: "permanent-redirect",
+		ingresses: []networkingv1.Ingress{
+			testIngress("a", "example.com", "/", networkingv1.PathTypePrefix, map[string]string{
+				permanentRedirectAnnotation: "https://www.example.com:8443/new",
+			}),
+		},
+		expectedRules: []gatewayv1.HTTPRouteRule{redirectRule(prefixMatch("/"), gatewayv1.HTTPRequestRedirectFilter{
+			Scheme:     ptrTo("https"),
+			Hostname:   ptrTo(gatewayv1.PreciseHostname("www.example.com")),
+			Port:       ptrTo(gatewayv1.PortNumber(8443)),
+			Path:       &gatewayv1.HTTPPathModifier{Type: gatewayv1.FullPathHTTPPathModifier, ReplaceFullPath: ptrTo("/new")},
+			StatusCode: ptrTo(301),
+		})},
+		expectedNotifications: []string{"is converted to RequestRedirect rules with status code 301"},
+	}, {
+		name: "permanent-redirect-code and request URI",
+		ingresses: []networkingv1.Ingress{
+			testIngress("a", "example.com", "/", networkingv1.PathTypePrefix, map[string]string{
+				permanentRedirectAnnotation:     "http://www.example.com$request_uri",
+				permanentRedirectCodeAnnotation: "308",
+			}),
+		},
+		expectedRules: []gatewayv1.HTTPRouteRule{redirectRule(prefixMatch("/"), gatewayv1.HTTPRequestRedirectFilter{
+			Scheme:     ptrTo("http"),
+			Hostname:   ptrTo(gatewayv1.PreciseHostname("www.example.com")),
+			StatusCode: ptrTo(301),
+		})},
+		expectedNotifications: []string{"status code 308 is converted to 301"},
+	}, {
+		name: "invalid permanent-redirect-code falls back to 301",
+		ingresses: []networkingv1.Ingress{
+			testIngress("a", "example.com", "/", networkingv1.PathTypePrefix, map[string]string{
+				permanentRedirectAnnotation:     "http://www.example.com$request_uri",
+				permanentRedirectCodeAnnotation: "200",
+			}),
+		},
+		expectedRules: []gatewayv1.HTTPRouteRule{redirectRule(prefixMatch("/"), gatewayv1.HTTPRequestRedirectFilter{
+			Scheme:     ptrTo("http"),
+			Hostname:   ptrTo(gatewayv1.PreciseHostname("www.example.com")),
+			StatusCode: ptrTo(301),
+		})},
+		expectedNotifications: []string{`nginx.ingress.kubernetes.io/permanent-redirect-code "200" is not a redirect status code, between 300 and 308, status code 301 is used instead`},
+	}, {
+		name: "temporal-redirect takes precedence",
+		ingresses: []networkingv1.Ingress{
+			testIngress("a", "example.com", "/", networkingv1.PathTypePrefix, map[string]string{
+				permanentRedirectAnnotation: "https://www.example.com/permanent",
+				temporalRedirectAnnotation:  "https://www.example.com/temporal?from=a",
+			}),
+		},
+		expectedRules: []gatewayv1.HTTPRouteRule{redirectRule(prefixMatch("/"), gatewayv1.HTTPRequestRedirectFilter{
+			Scheme:     ptrTo("https"),
+			Hostname:   ptrTo(gatewayv1.PreciseHostname("www.example.com")),
+			Path:       &gatewayv1.HTTPPathModifier{Type: gatewayv1.FullPathHTTPPathModifier, ReplaceFullPath: ptrTo("/temporal")},
+			StatusCode: ptrTo(302),
+		})},
+		expectedNotifications: []string{"its query and fragment are dropped"},
+	}, {
+		name: "app-root",
+		ingresses: []networkingv1.Ingress{
+			testIngress("a", "example.com", "/", networkingv1.PathTypePrefix, map[string]string{
+				appRootAnnotation: "/app",
+			}),
+		},
+		expectedRules: []gatewayv1.HTTPRouteRule{backendRule("/"), redirectRule([]gatewayv1.HTTPRouteMatch{{
+			Path: &gatewayv1.HTTPPathMatch{Type: ptrTo(gatewayv1.PathMatchExact), Value: ptrTo("/")},
+		}}, gatewayv1.HTTPRequestRedirectFilter{
+			Path:       &gatewayv1.HTTPPathModifier{Type: gatewayv1.FullPathHTTPPathModifier, ReplaceFullPath: ptrTo("/app")},
+			StatusCode: ptrTo(302),
+		})},
+	}, {
+		name: "app-root of an Ingress not serving the root path",
+		ingresses: []networkingv1.Ingress{
+			testIngress("a", "example.com", "/", networkingv1.PathTypePrefix, nil),
+			testIngress("b", "example.com", "/b", networkingv1.PathTypePrefix, map[string]string{
+				appRootAnnotation: "/app",
+			}),
+		},
+		expectedRules:         []gatewayv1.HTTPRouteRule{backendRule("/"), backendRule("/b")},
+		expectedNotifications: []string{"it only applies to the Ingress serving the root path"},
+	}, {
+		name: "nginx variables",
+		ingresses: []networkingv1.Ingress{
+			testIngress("a", "example.com", "/", networkingv1.PathTypePrefix, map[string]string{
+				permanentRedirectAnnotation: "https://$host/new",
+			}),
+		},
+		expectedRules:         []gatewayv1.HTTPRouteRule{backendRule("/")},
+		expectedNotifications: []string{"uses nginx variables, which cannot be converted"},
+	}, {
+		name: "invalid annotations",
+		ingresses: []networkingv1.Ingress{
+			testIngress("a", "example.com", "/", networkingv1.PathTypePrefix, map[string]string{
+				permanentRedirectAnnotation:     "ftp://www.example.com",
+				permanentRedirectCodeAnnotation: "200",
+			}),
+			testIngress("b", "example.com", "/", networkingv1.PathTypePrefix, map[string]string{
+				temporalRedirectAnnotation: "/relative",
+				appRootAnnotation:          "app",
+			}),
+		},
+		expectedErrors: 3,
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			provider := NewProvider(&i2gw.ProviderConf{}).(*Provider)
+			ingressMap := map[types.NamespacedName]*networkingv1.Ingress{}
+			for i := range tc.ingresses {
+				ingressMap[types.NamespacedName{Namespace: tc.ingresses[i].Namespace, Name: tc.ingresses[i].Name}] = &tc.ingresses[i]
+			}
+			provider.storage.Ingresses.FromMap(ingressMap)
+
+			gatewayResources, errs := provider.ToGatewayAPI()
+			if len(errs) != tc.expectedErrors {
+				t.Fatalf("Expected %d errors, got %+v", tc.expectedErrors, errs)
+			}
+			if tc.expectedErrors > 0 {
+				return
+			}
+			notificationTable := notifications.NotificationAggr.CreateNotificationTables()[Name]
+
+			key := types.NamespacedName{Namespace: "default", Name: "a-example-com"}
+			httpRoute, ok := gatewayResources.HTTPRoutes[key]
+			if !ok {
+				t.Fatalf("Expected HTTPRoute %s, got %+v", key, gatewayResources.HTTPRoutes)
+			}
+			if diff := cmp.Diff(tc.expectedRules, httpRoute.Spec.Rules); diff != "" {
+				t.Errorf("Unexpected rules, diff (-want +got):\n%s", diff)
+			}
+			for _, expected := range tc.expectedNotifications {
+				if !strings.Contains(notificationTable, expected) {
+					t.Errorf("Expected notification %q, got:\n%s", expected, notificationTable)
+				}
+			}
+		})
+	}
+}
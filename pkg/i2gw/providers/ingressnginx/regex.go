@@ -0,0 +1,164 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingressnginx
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+const useRegexAnnotation = "nginx.ingress.kubernetes.io/use-regex"
+
+// implementationSpecificHTTPPathTypeMatch converts ImplementationSpecific paths
+// to prefix matches, which is how ingress-nginx treats them. Paths of hosts
+// using regular expressions are converted afterwards by regexFeature.
+func implementationSpecificHTTPPathTypeMatch(path *gatewayv1.HTTPPathMatch) {
+	path.Type = ptr.To(gatewayv1.PathMatchPathPrefix)
+}
+
+// usesRegex returns whether the paths of the Ingress are interpreted by
+// ingress-nginx as regular expressions, which rewrite-target implies.
+func usesRegex(ingress networkingv1.Ingress) bool {
+	return ingress.Annotations[useRegexAnnotation] == "true" || ingress.Annotations[rewriteTargetAnnotation] != ""
+}
+
+// regexFeature converts the prefix matches of every host with an Ingress using
+// regular expressions to case-insensitive regular expression matches.
+//
+// ingress-nginx renders the locations of such hosts as "location ~* ^<path>",
+// for every Ingress of the host, regardless of the Ingress the annotation is
+// set on. Exact paths are kept as exact locations, and so are the prefixes
+// rewritten by rewriteFeature, as a prefix rewrite requires a prefix match.
+func regexFeature(ingresses []networkingv1.Ingress, gatewayResources *i2gw.GatewayResources) field.ErrorList {
+	ingressesByHost := map[string][]networkingv1.Ingress{}
+	regexIngressesByHost := map[string][]networkingv1.Ingress{}
+	for _, ingress := range ingresses {
+		hosts := map[string]struct{}{}
+		for _, rule := range ingress.Spec.Rules {
+			hosts[rule.Host] = struct{}{}
+		}
+		for host := range hosts {
+			ingressesByHost[host] = append(ingressesByHost[host], ingress)
+			if usesRegex(ingress) {
+				regexIngressesByHost[host] = append(regexIngressesByHost[host], ingress)
+			}
+		}
+	}
+	if len(regexIngressesByHost) == 0 {
+		return nil
+	}
+
+	routeKeys := make([]types.NamespacedName, 0, len(gatewayResources.HTTPRoutes))
+	for key := range gatewayResources.HTTPRoutes {
+		routeKeys = append(routeKeys, key)
+	}
+	sort.Slice(routeKeys, func(i, j int) bool { return routeKeys[i].String() < routeKeys[j].String() })
+
+	var errs field.ErrorList
+	for _, key := range routeKeys {
+		httpRoute := gatewayResources.HTTPRoutes[key]
+		host := ""
+		if len(httpRoute.Spec.Hostnames) > 0 {
+			host = string(httpRoute.Spec.Hostnames[0])
+		}
+		if _, ok := regexIngressesByHost[host]; !ok {
+			continue
+		}
+		for i, rule := range httpRoute.Spec.Rules {
+			if hasPrefixRewrite(rule) {
+				continue
+			}
+			for j, match := range rule.Matches {
+				if match.Path == nil || match.Path.Type == nil || *match.Path.Type != gatewayv1.PathMatchPathPrefix {
+					continue
+				}
+				value := regexPathValue(*match.Path.Value)
+				if _, err := regexp.Compile(value); err != nil {
+					fieldPath := field.NewPath("HTTPRoute", key.Namespace, key.Name, "spec", "rules").Index(i).Child("matches").Index(j).Child("path")
+					errs = append(errs, field.Invalid(fieldPath, *match.Path.Value, fmt.Sprintf("path is not a valid regular expression: %v", err)))
+					continue
+				}
+				httpRoute.Spec.Rules[i].Matches[j].Path = &gatewayv1.HTTPPathMatch{
+					Type:  ptr.To(gatewayv1.PathMatchRegularExpression),
+					Value: ptr.To(value),
+				}
+			}
+		}
+		gatewayResources.HTTPRoutes[key] = httpRoute
+	}
+
+	hosts := make([]string, 0, len(regexIngressesByHost))
+	for host := range regexIngressesByHost {
+		hosts = append(hosts, host)
+	}
+	sort.Strings(hosts)
+	for _, host := range hosts {
+		notifyRegexHost(host, regexIngressesByHost[host], ingressesByHost[host])
+	}
+
+	return errs
+}
+
+// regexPathValue returns the regular expression matching the paths ingress-nginx
+// matches with "location ~* ^<path>". Gateway API implementations match the
+// whole path against the expression, hence the trailing wildcard.
+func regexPathValue(path string) string {
+	if !strings.HasSuffix(path, "$") {
+		path += ".*"
+	}
+	return "(?i)" + path
+}
+
+func notifyRegexHost(host string, regexIngresses, hostIngresses []networkingv1.Ingress) {
+	hostName := host
+	if hostName == "" {
+		hostName = "<none>"
+	}
+
+	var regexNames []string
+	for _, ingress := range regexIngresses {
+		regexNames = append(regexNames, fmt.Sprintf("%s/%s", ingress.Namespace, ingress.Name))
+	}
+
+	var callingObjects []client.Object
+	var otherNames []string
+	for i := range hostIngresses {
+		callingObjects = append(callingObjects, &hostIngresses[i])
+		if !usesRegex(hostIngresses[i]) {
+			otherNames = append(otherNames, fmt.Sprintf("%s/%s", hostIngresses[i].Namespace, hostIngresses[i].Name))
+		}
+	}
+
+	message := fmt.Sprintf("%s or %s is set on %s: the prefix paths of host %s are converted to case-insensitive regular expressions", useRegexAnnotation, rewriteTargetAnnotation, strings.Join(regexNames, ", "), hostName)
+	messageType := notifications.InfoNotification
+	if len(otherNames) > 0 {
+		message = fmt.Sprintf("%s, including the paths of %s which do not set the annotation", message, strings.Join(otherNames, ", "))
+		messageType = notifications.WarningNotification
+	}
+	notifications.NotificationAggr.DispatchNotification(notifications.NewNotification(messageType, message, callingObjects...), Name)
+}
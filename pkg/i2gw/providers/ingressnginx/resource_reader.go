@@ -18,9 +18,14 @@ package ingressnginx
 
 import (
 	"context"
+	"fmt"
+	"strconv"
 
 	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw"
 	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/providers/common"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/sets"
 )
 
@@ -39,21 +44,169 @@ func newResourceReader(conf *i2gw.ProviderConf) *resourceReader {
 func (r *resourceReader) readResourcesFromCluster(ctx context.Context) (*storage, error) {
 	storage := newResourcesStorage()
 
-	ingresses, err := common.ReadIngressesFromCluster(ctx, r.conf.Client, sets.New(NginxIngressClass))
+	ingresses, err := common.ReadIngressesFromCluster(ctx, r.conf.Cache, sets.New(NginxIngressClass))
 	if err != nil {
 		return nil, err
 	}
 	storage.Ingresses.FromMap(ingresses)
+
+	storage.Services, err = common.ReadServicesFromCluster(ctx, r.conf.Client, ingresses)
+	if err != nil {
+		return nil, err
+	}
+
+	storage.ControllerService, err = common.ReadControllerServiceFromCluster(ctx, r.conf, Name)
+	if err != nil {
+		return nil, err
+	}
+
+	readConfigMap := func(key types.NamespacedName) (*corev1.ConfigMap, error) {
+		return common.ReadConfigMapFromCluster(ctx, r.conf.ClusterReader, key)
+	}
+	if err := r.readConfigMaps(storage, readConfigMap); err != nil {
+		return nil, err
+	}
+	if err := r.readStreamServices(ctx, storage); err != nil {
+		return nil, err
+	}
 	return storage, nil
 }
 
+// readStreamServices gets the Services of the tcp-services and udp-services
+// entries with a named port from the cluster, which are needed to resolve it.
+// Missing Services are reported during the conversion.
+func (r *resourceReader) readStreamServices(ctx context.Context, storage *storage) error {
+	for _, configMap := range []*corev1.ConfigMap{storage.TCPServices, storage.UDPServices} {
+		if configMap == nil {
+			continue
+		}
+		for port, value := range configMap.Data {
+			entry, err := parseStreamService(port, value)
+			if err != nil {
+				continue
+			}
+			if _, err := strconv.ParseInt(entry.servicePort, 10, 32); err == nil {
+				continue
+			}
+			if _, ok := storage.Services[entry.service]; ok {
+				continue
+			}
+			service, err := common.ReadServiceFromCluster(ctx, r.conf.ClusterReader, entry.service)
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			storage.Services[entry.service] = service
+		}
+	}
+	return nil
+}
+
 func (r *resourceReader) readResourcesFromFile(filename string) (*storage, error) {
 	storage := newResourcesStorage()
 
-	ingresses, err := common.ReadIngressesFromFile(filename, r.conf.Namespace, sets.New(NginxIngressClass))
+	ingresses, err := common.ReadIngressesFromFile(filename, r.conf, sets.New(NginxIngressClass))
 	if err != nil {
 		return nil, err
 	}
 	storage.Ingresses.FromMap(ingresses)
+
+	storage.Services, err = common.ReadServicesFromFile(filename, r.conf)
+	if err != nil {
+		return nil, err
+	}
+
+	storage.ControllerService, err = common.ReadControllerServiceFromFile(filename, r.conf, Name)
+	if err != nil {
+		return nil, err
+	}
+
+	readConfigMap := func(key types.NamespacedName) (*corev1.ConfigMap, error) {
+		return common.ReadConfigMapFromFile(filename, r.conf, key)
+	}
+	if err := r.readConfigMaps(storage, readConfigMap); err != nil {
+		return nil, err
+	}
 	return storage, nil
 }
+
+// readConfigMaps reads the controller ConfigMaps named by the provider-specific
+// flags into the storage, with the given read function, along with the
+// ConfigMap of the proxy-set-headers setting of the controller ConfigMap.
+func (r *resourceReader) readConfigMaps(storage *storage, read func(types.NamespacedName) (*corev1.ConfigMap, error)) error {
+	if err := readAnnotationConfigMaps(storage, read); err != nil {
+		return err
+	}
+
+	var err error
+	storage.TCPServices, err = r.readConfigMap(TCPServicesConfigMapFlag, read)
+	if err != nil {
+		return err
+	}
+	storage.UDPServices, err = r.readConfigMap(UDPServicesConfigMapFlag, read)
+	if err != nil {
+		return err
+	}
+	storage.ControllerConfigMap, err = r.readConfigMap(ControllerConfigMapFlag, read)
+	if err != nil || storage.ControllerConfigMap == nil {
+		return err
+	}
+
+	value := storage.ControllerConfigMap.Data[proxySetHeadersKey]
+	if value == "" {
+		return nil
+	}
+	key, err := common.ParseNamespacedName(value)
+	if err != nil {
+		return fmt.Errorf("invalid %s setting of configmap %s/%s: %w", proxySetHeadersKey, storage.ControllerConfigMap.Namespace, storage.ControllerConfigMap.Name, err)
+	}
+	storage.ProxySetHeaders, err = read(key)
+	return err
+}
+
+// readAnnotationConfigMaps reads the ConfigMaps referenced by the annotations of
+// the Ingresses into the storage. Missing ConfigMaps are reported during the
+// conversion.
+func readAnnotationConfigMaps(storage *storage, read func(types.NamespacedName) (*corev1.ConfigMap, error)) error {
+	for _, ingress := range storage.Ingresses.List() {
+		for _, annotation := range []string{proxySetHeadersAnnotation, customHeadersAnnotation} {
+			value := ingress.Annotations[annotation]
+			if value == "" {
+				continue
+			}
+			key, err := objectReference(ingress.Namespace, value)
+			if err != nil {
+				continue
+			}
+			if _, ok := storage.ConfigMaps[key]; ok {
+				continue
+			}
+			configMap, err := read(key)
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			storage.ConfigMaps[key] = configMap
+		}
+	}
+	return nil
+}
+
+// readConfigMap reads the ConfigMap named by the provider-specific flag, as
+// namespace/name, with the given read function. It returns nil if the flag is
+// not set.
+func (r *resourceReader) readConfigMap(flag string, read func(types.NamespacedName) (*corev1.ConfigMap, error)) (*corev1.ConfigMap, error) {
+	value := r.conf.ProviderSpecificFlags[Name][flag]
+	if value == "" {
+		return nil, nil
+	}
+	key, err := common.ParseNamespacedName(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s-%s flag: %w", Name, flag, err)
+	}
+	return read(key)
+}
@@ -0,0 +1,140 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingressnginx
+
+import (
+	"fmt"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/providers/common"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+const rewriteTargetAnnotation = "nginx.ingress.kubernetes.io/rewrite-target"
+
+// rewriteFeature converts the rewrite-target annotation to URLRewrite filters.
+// Rewrites with no Gateway API equivalent are reported as errors, and their
+// rules are kept without URLRewrite filter, forwarding the requests to the
+// backends with their original path.
+func rewriteFeature(ingresses []networkingv1.Ingress, gatewayResources *i2gw.GatewayResources) field.ErrorList {
+	for _, rg := range sortedRuleGroups(ingresses) {
+		key := types.NamespacedName{Namespace: rg.Namespace, Name: common.RouteName(rg.Name, rg.Host)}
+		httpRoute, ok := gatewayResources.HTTPRoutes[key]
+		if !ok {
+			continue
+		}
+
+		sourcesByRule := ruleSourcesByIndex(rg, httpRoute)
+		for _, ruleIdx := range sortedRuleIndexes(sourcesByRule) {
+			applyRewriteToRule(&httpRoute.Spec.Rules[ruleIdx], sourcesByRule[ruleIdx])
+		}
+		gatewayResources.HTTPRoutes[key] = httpRoute
+	}
+
+	return nil
+}
+
+func applyRewriteToRule(rule *gatewayv1.HTTPRouteRule, sources []ruleSource) {
+	source := sources[0]
+	target := source.ingress.Annotations[rewriteTargetAnnotation]
+	var callingObjects []client.Object
+	sameTarget := true
+	for i := range sources {
+		callingObjects = append(callingObjects, &sources[i].ingress)
+		sameTarget = sameTarget && sources[i].ingress.Annotations[rewriteTargetAnnotation] == target
+	}
+	if !sameTarget {
+		notifications.NotificationAggr.DispatchNotification(notifications.NewNotification(
+			notifications.ErrorNotification,
+			fmt.Sprintf("Ingresses sharing path %q set different %s annotations, the rewrite must be configured manually: the requests are forwarded with their original path", source.path.Path, rewriteTargetAnnotation),
+			callingObjects...,
+		), Name)
+		return
+	}
+	if target == "" || hasRequestRedirect(*rule) {
+		return
+	}
+
+	rewritePath, err := common.ToRewritePath(source.path.Path, target)
+	if err == nil && rewritePath.MatchPrefix != "" && source.path.PathType != nil && *source.path.PathType == networkingv1.PathTypeExact {
+		err = fmt.Errorf("path %q is an Exact path, which cannot be rewritten by prefix", source.path.Path)
+	}
+	if err != nil {
+		notifications.NotificationAggr.DispatchNotification(notifications.NewNotification(
+			notifications.ErrorNotification,
+			fmt.Sprintf("%s %q cannot be converted: %v, the requests are forwarded with their original path", rewriteTargetAnnotation, target, err),
+			callingObjects...,
+		), Name)
+		return
+	}
+
+	if rewritePath.MatchPrefix != "" {
+		for i := range rule.Matches {
+			rule.Matches[i].Path = &gatewayv1.HTTPPathMatch{
+				Type:  ptr.To(gatewayv1.PathMatchPathPrefix),
+				Value: ptr.To(rewritePath.MatchPrefix),
+			}
+		}
+	}
+	common.RuleURLRewrite(rule).Path = &rewritePath.Modifier
+
+	if rewritePath.Difference != "" {
+		notifications.NotificationAggr.DispatchNotification(notifications.NewNotification(
+			notifications.WarningNotification,
+			fmt.Sprintf("%s %q of path %q is converted to a prefix replacement, but %s", rewriteTargetAnnotation, target, source.path.Path, rewritePath.Difference),
+			callingObjects...,
+		), Name)
+	}
+}
+
+// findHTTPRouteRules returns the indexes of the HTTPRoute rules the Ingress
+// path was converted to, which are several when canaryFeature adds rules
+// matching the headers of a canary.
+func findHTTPRouteRules(httpRoute gatewayv1.HTTPRoute, path networkingv1.HTTPIngressPath) []int {
+	matchType := gatewayv1.PathMatchPathPrefix
+	if path.PathType != nil && *path.PathType == networkingv1.PathTypeExact {
+		matchType = gatewayv1.PathMatchExact
+	}
+	var ruleIdxs []int
+	for i, rule := range httpRoute.Spec.Rules {
+		for _, match := range rule.Matches {
+			if match.Path != nil && match.Path.Type != nil && *match.Path.Type == matchType &&
+				match.Path.Value != nil && *match.Path.Value == path.Path {
+				ruleIdxs = append(ruleIdxs, i)
+				break
+			}
+		}
+	}
+	return ruleIdxs
+}
+
+// hasPrefixRewrite returns whether the rule rewrites the prefix its path
+// matches, which requires the path to be matched as a prefix.
+func hasPrefixRewrite(rule gatewayv1.HTTPRouteRule) bool {
+	for _, filter := range rule.Filters {
+		if filter.URLRewrite != nil && filter.URLRewrite.Path != nil && filter.URLRewrite.Path.Type == gatewayv1.PrefixMatchHTTPPathModifier {
+			return true
+		}
+	}
+	return false
+}
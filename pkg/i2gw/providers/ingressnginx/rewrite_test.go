@@ -0,0 +1,174 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingressnginx
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/providers/common"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+// testIngress returns an ingress-nginx Ingress routing the path of the host to
+// the test Service.
+func testIngress(name, host, path string, pathType networkingv1.PathType, annotations map[string]string) networkingv1.Ingress {
+	return networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Annotations: annotations},
+		Spec: networkingv1.IngressSpec{
+			IngressClassName: ptrTo("ingress-nginx"),
+			Rules: []networkingv1.IngressRule{{
+				Host: host,
+				IngressRuleValue: networkingv1.IngressRuleValue{
+					HTTP: &networkingv1.HTTPIngressRuleValue{
+						Paths: []networkingv1.HTTPIngressPath{{
+							Path:     path,
+							PathType: &pathType,
+							Backend: networkingv1.IngressBackend{
+								Service: &networkingv1.IngressServiceBackend{
+									Name: "test",
+									Port: networkingv1.ServiceBackendPort{Number: 80},
+								},
+							},
+						}},
+					},
+				},
+			}},
+		},
+	}
+}
+
+// convertTestIngresses converts the Ingresses with the ingress-nginx provider,
+// and returns the resulting resources along with the notification table.
+func convertTestIngresses(t *testing.T, conf *i2gw.ProviderConf, ingresses ...networkingv1.Ingress) (i2gw.GatewayResources, string) {
+	t.Helper()
+	provider := NewProvider(conf).(*Provider)
+	ingressMap := map[types.NamespacedName]*networkingv1.Ingress{}
+	for i := range ingresses {
+		ingressMap[types.NamespacedName{Namespace: ingresses[i].Namespace, Name: ingresses[i].Name}] = &ingresses[i]
+	}
+	provider.storage.Ingresses.FromMap(ingressMap)
+
+	gatewayResources, errs := provider.ToGatewayAPI()
+	if len(errs) != 0 {
+		t.Fatalf("Unexpected errors: %+v", errs)
+	}
+	return gatewayResources, notifications.NotificationAggr.CreateNotificationTables()[Name]
+}
+
+func Test_rewriteFeature(t *testing.T) {
+	testCases := []struct {
+		name                 string
+		ingresses            []networkingv1.Ingress
+		expectedMatch        gatewayv1.HTTPPathMatch
+		expectedFilters      []gatewayv1.HTTPRouteFilter
+		expectedNotification string
+	}{{
+		name: "static target",
+		ingresses: []networkingv1.Ingress{
+			testIngress("static", "example.com", "/foo", networkingv1.PathTypePrefix, map[string]string{
+				rewriteTargetAnnotation: "/",
+			}),
+		},
+		expectedMatch: gatewayv1.HTTPPathMatch{Type: ptrTo(gatewayv1.PathMatchRegularExpression), Value: ptrTo("(?i)/foo.*")},
+		expectedFilters: []gatewayv1.HTTPRouteFilter{{
+			Type: gatewayv1.HTTPRouteFilterURLRewrite,
+			URLRewrite: &gatewayv1.HTTPURLRewriteFilter{
+				Path: &gatewayv1.HTTPPathModifier{Type: gatewayv1.FullPathHTTPPathModifier, ReplaceFullPath: ptrTo("/")},
+			},
+		}},
+	}, {
+		name: "static target on a capture group path",
+		ingresses: []networkingv1.Ingress{
+			testIngress("static", "example.com", "/api(/|$)(.*)", networkingv1.PathTypeImplementationSpecific, map[string]string{
+				rewriteTargetAnnotation: "/",
+			}),
+		},
+		expectedMatch: gatewayv1.HTTPPathMatch{Type: ptrTo(gatewayv1.PathMatchRegularExpression), Value: ptrTo("(?i)/api(/|$)(.*).*")},
+		expectedFilters: []gatewayv1.HTTPRouteFilter{{
+			Type: gatewayv1.HTTPRouteFilterURLRewrite,
+			URLRewrite: &gatewayv1.HTTPURLRewriteFilter{
+				Path: &gatewayv1.HTTPPathModifier{Type: gatewayv1.FullPathHTTPPathModifier, ReplaceFullPath: ptrTo("/")},
+			},
+		}},
+	}, {
+		name: "capture group rewrite",
+		ingresses: []networkingv1.Ingress{
+			testIngress("capture", "example.com", "/foo(/|$)(.*)", networkingv1.PathTypeImplementationSpecific, map[string]string{
+				rewriteTargetAnnotation: "/bar/$2",
+			}),
+		},
+		expectedMatch: gatewayv1.HTTPPathMatch{Type: ptrTo(gatewayv1.PathMatchPathPrefix), Value: ptrTo("/foo")},
+		expectedFilters: []gatewayv1.HTTPRouteFilter{{
+			Type: gatewayv1.HTTPRouteFilterURLRewrite,
+			URLRewrite: &gatewayv1.HTTPURLRewriteFilter{
+				Path: &gatewayv1.HTTPPathModifier{Type: gatewayv1.PrefixMatchHTTPPathModifier, ReplacePrefixMatch: ptrTo("/bar")},
+			},
+		}},
+	}, {
+		name: "rewrite without equivalent",
+		ingresses: []networkingv1.Ingress{
+			testIngress("reorder", "example.com", "/foo/(.*)/(.*)", networkingv1.PathTypeImplementationSpecific, map[string]string{
+				rewriteTargetAnnotation: "/$2/$1",
+			}),
+		},
+		expectedMatch:        gatewayv1.HTTPPathMatch{Type: ptrTo(gatewayv1.PathMatchRegularExpression), Value: ptrTo("(?i)/foo/(.*)/(.*).*")},
+		expectedNotification: `nginx.ingress.kubernetes.io/rewrite-target "/$2/$1" cannot be converted`,
+	}, {
+		name: "ingresses sharing a path with different targets",
+		ingresses: []networkingv1.Ingress{
+			testIngress("a", "example.com", "/foo", networkingv1.PathTypePrefix, map[string]string{
+				rewriteTargetAnnotation: "/a",
+			}),
+			testIngress("b", "example.com", "/foo", networkingv1.PathTypePrefix, map[string]string{
+				"nginx.ingress.kubernetes.io/canary":        "true",
+				"nginx.ingress.kubernetes.io/canary-weight": "10",
+				rewriteTargetAnnotation:                     "/b",
+			}),
+		},
+		expectedMatch:        gatewayv1.HTTPPathMatch{Type: ptrTo(gatewayv1.PathMatchRegularExpression), Value: ptrTo("(?i)/foo.*")},
+		expectedNotification: "set different nginx.ingress.kubernetes.io/rewrite-target annotations",
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			gatewayResources, notificationTable := convertTestIngresses(t, &i2gw.ProviderConf{}, tc.ingresses...)
+
+			key := types.NamespacedName{Namespace: "default", Name: common.RouteName(tc.ingresses[0].Name, "example.com")}
+			httpRoute, ok := gatewayResources.HTTPRoutes[key]
+			if !ok || len(httpRoute.Spec.Rules) != 1 {
+				t.Fatalf("Expected HTTPRoute %s with a single rule, got %+v", key, gatewayResources.HTTPRoutes)
+			}
+			rule := httpRoute.Spec.Rules[0]
+			if diff := cmp.Diff(tc.expectedMatch, *rule.Matches[0].Path); diff != "" {
+				t.Errorf("Unexpected path match, diff (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.expectedFilters, rule.Filters); diff != "" {
+				t.Errorf("Unexpected filters, diff (-want +got):\n%s", diff)
+			}
+			if tc.expectedNotification != "" && !strings.Contains(notificationTable, tc.expectedNotification) {
+				t.Errorf("Expected notification %q, got:\n%s", tc.expectedNotification, notificationTable)
+			}
+		})
+	}
+}
@@ -0,0 +1,236 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingressnginx
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/providers/common"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+// routePolicies attaches the policies converted from the annotations of the
+// Ingresses to their HTTPRoutes.
+//
+// ingress-nginx configures each path with the annotations of its Ingress,
+// while a policy applies to every rule of the HTTPRoute it targets. So the
+// features record the policy fields of each rule, and feature moves the rules
+// with different policies to HTTPRoutes of their own, named after the Ingress
+// of their first rule, before attaching the policies. This is done once the
+// HTTPRoutes are final, so that the copies of the redirected HTTPRoutes made
+// by sslRedirect.redirectFeature are split likewise.
+type routePolicies struct {
+	sslRedirect *sslRedirect
+	// rules are the policy fields of the rules of the HTTPRoutes, by rule
+	// index.
+	rules map[types.NamespacedName]map[int]*rulePolicies
+}
+
+// rulePolicies are the policy fields of an HTTPRoute rule, and the source of
+// the rule.
+type rulePolicies struct {
+	source ruleSource
+	fields map[policyField]interface{}
+}
+
+// policyField is a field of the spec of the policies of a kind.
+type policyField struct {
+	gvk  schema.GroupVersionKind
+	name string
+}
+
+func newRoutePolicies(sslRedirect *sslRedirect) *routePolicies {
+	return &routePolicies{
+		sslRedirect: sslRedirect,
+		rules:       map[types.NamespacedName]map[int]*rulePolicies{},
+	}
+}
+
+// set records the value of the field of the policy of kind gvk of the rule.
+func (p *routePolicies) set(key types.NamespacedName, ruleIdx int, source ruleSource, gvk schema.GroupVersionKind, name string, value interface{}) {
+	if p.rules[key] == nil {
+		p.rules[key] = map[int]*rulePolicies{}
+	}
+	rule, ok := p.rules[key][ruleIdx]
+	if !ok {
+		rule = &rulePolicies{source: source, fields: map[policyField]interface{}{}}
+		p.rules[key][ruleIdx] = rule
+	}
+	rule.fields[policyField{gvk: gvk, name: name}] = value
+}
+
+// get returns the value of the field of the policy of kind gvk of the rule,
+// nil when it is not set.
+func (p *routePolicies) get(key types.NamespacedName, ruleIdx int, gvk schema.GroupVersionKind, name string) interface{} {
+	rule, ok := p.rules[key][ruleIdx]
+	if !ok {
+		return nil
+	}
+	return rule.fields[policyField{gvk: gvk, name: name}]
+}
+
+// removeRules drops the policies of the rules removed from the HTTPRoute,
+// shifting the indexes of the following rules.
+func (p *routePolicies) removeRules(key types.NamespacedName, removed map[int]bool) {
+	rules, ok := p.rules[key]
+	if !ok || len(removed) == 0 {
+		return
+	}
+	shifted := map[int]*rulePolicies{}
+	for ruleIdx, rule := range rules {
+		if removed[ruleIdx] {
+			continue
+		}
+		newIdx := ruleIdx
+		for removedIdx := range removed {
+			if removedIdx < ruleIdx {
+				newIdx--
+			}
+		}
+		shifted[newIdx] = rule
+	}
+	p.rules[key] = shifted
+}
+
+// forEachRuleSource calls fn for every rule of the HTTPRoutes of the
+// Ingresses, with the source whose annotations configure it, in a stable
+// order. As ingress-nginx answers the redirects first, the rules converted by
+// redirectFeature are skipped when skipRedirects is set.
+func forEachRuleSource(ingresses []networkingv1.Ingress, gatewayResources *i2gw.GatewayResources, skipRedirects bool, fn func(key types.NamespacedName, ruleIdx int, source ruleSource)) {
+	for _, rg := range sortedRuleGroups(ingresses) {
+		key := types.NamespacedName{Namespace: rg.Namespace, Name: common.RouteName(rg.Name, rg.Host)}
+		httpRoute, ok := gatewayResources.HTTPRoutes[key]
+		if !ok {
+			continue
+		}
+		sourcesByRule := ruleSourcesByIndex(rg, httpRoute)
+		for _, ruleIdx := range sortedRuleIndexes(sourcesByRule) {
+			if skipRedirects && hasRequestRedirect(httpRoute.Spec.Rules[ruleIdx]) {
+				continue
+			}
+			fn(key, ruleIdx, annotationSources(sourcesByRule[ruleIdx])[0])
+		}
+	}
+}
+
+// feature splits the HTTPRoutes by the policies of their rules, and attaches
+// the policies to them.
+func (p *routePolicies) feature(_ []networkingv1.Ingress, gatewayResources *i2gw.GatewayResources) field.ErrorList {
+	routeKeys := make([]types.NamespacedName, 0, len(p.rules))
+	for key := range p.rules {
+		routeKeys = append(routeKeys, key)
+	}
+	sort.Slice(routeKeys, func(i, j int) bool { return routeKeys[i].String() < routeKeys[j].String() })
+
+	for _, key := range routeKeys {
+		keys := []types.NamespacedName{key}
+		if redirectKey, ok := p.sslRedirect.redirectRouteKey(key); ok && redirectKey != key {
+			keys = append(keys, redirectKey)
+		}
+		for _, routeKey := range keys {
+			splitRouteByPolicies(gatewayResources, routeKey, p.rules[key])
+		}
+	}
+	return nil
+}
+
+// splitRouteByPolicies attaches the policies of its rules to the HTTPRoute,
+// moving the rules with different policies to HTTPRoutes of their own.
+func splitRouteByPolicies(gatewayResources *i2gw.GatewayResources, key types.NamespacedName, rules map[int]*rulePolicies) {
+	httpRoute, ok := gatewayResources.HTTPRoutes[key]
+	if !ok {
+		return
+	}
+	fieldsOf := func(ruleIdx int) map[policyField]interface{} {
+		if rule, ok := rules[ruleIdx]; ok {
+			return rule.fields
+		}
+		return nil
+	}
+
+	// The rules are grouped by policies. The rules without policies stay in
+	// the HTTPRoute, or else the group of its first rule.
+	var groups [][]int
+	for i := range httpRoute.Spec.Rules {
+		grouped := false
+		for g, group := range groups {
+			if reflect.DeepEqual(fieldsOf(group[0]), fieldsOf(i)) {
+				groups[g] = append(group, i)
+				grouped = true
+				break
+			}
+		}
+		if !grouped {
+			groups = append(groups, []int{i})
+		}
+	}
+	if len(groups) == 0 {
+		return
+	}
+	for g, group := range groups {
+		if fieldsOf(group[0]) == nil {
+			groups[0], groups[g] = groups[g], groups[0]
+			break
+		}
+	}
+	groupRules := func(group []int) []gatewayv1.HTTPRouteRule {
+		httpRules := make([]gatewayv1.HTTPRouteRule, 0, len(group))
+		for _, i := range group {
+			httpRules = append(httpRules, httpRoute.Spec.Rules[i])
+		}
+		return httpRules
+	}
+
+	groupKeys := []types.NamespacedName{key}
+	for g, group := range groups[1:] {
+		source := rules[group[0]].source
+		splitKey := types.NamespacedName{Namespace: key.Namespace, Name: fmt.Sprintf("%s-%s", key.Name, source.ingress.Name)}
+		if _, exists := gatewayResources.HTTPRoutes[splitKey]; exists {
+			splitKey.Name = fmt.Sprintf("%s-%d", splitKey.Name, g+1)
+		}
+		splitRoute := *httpRoute.DeepCopy()
+		splitRoute.Name = splitKey.Name
+		splitRoute.Spec.Rules = groupRules(group)
+		gatewayResources.HTTPRoutes[splitKey] = splitRoute
+		groupKeys = append(groupKeys, splitKey)
+
+		notifications.NotificationAggr.DispatchNotification(notifications.NewNotification(
+			notifications.InfoNotification,
+			fmt.Sprintf("the rules of HTTPRoute %s with the policies of %s/%s are moved to HTTPRoute %s, as a policy applies to every rule of an HTTPRoute",
+				key, source.ingress.Namespace, source.ingress.Name, splitKey),
+			&source.ingress,
+		), Name)
+	}
+	if len(groups) > 1 {
+		httpRoute.Spec.Rules = groupRules(groups[0])
+		gatewayResources.HTTPRoutes[key] = httpRoute
+	}
+
+	for g, group := range groups {
+		for policyField, value := range fieldsOf(group[0]) {
+			setPolicySpec(routePolicy(gatewayResources, policyField.gvk, groupKeys[g]), policyField.name, value)
+		}
+	}
+}
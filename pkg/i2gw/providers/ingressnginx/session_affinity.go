@@ -0,0 +1,198 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingressnginx
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+const (
+	affinityAnnotation            = "nginx.ingress.kubernetes.io/affinity"
+	affinityModeAnnotation        = "nginx.ingress.kubernetes.io/affinity-mode"
+	sessionCookieNameAnnotation   = "nginx.ingress.kubernetes.io/session-cookie-name"
+	sessionCookieMaxAgeAnnotation = "nginx.ingress.kubernetes.io/session-cookie-max-age"
+	sessionCookiePathAnnotation   = "nginx.ingress.kubernetes.io/session-cookie-path"
+
+	cookieAffinity           = "cookie"
+	balancedAffinityMode     = "balanced"
+	persistentAffinityMode   = "persistent"
+	defaultSessionCookieName = "INGRESSCOOKIE"
+)
+
+// ingressSessionAffinity is the cookie session affinity of an Ingress.
+type ingressSessionAffinity struct {
+	mode       string
+	cookieName string
+	// maxAge is in seconds, nil for a session cookie.
+	maxAge *int
+	// path is empty when not set, in which case ingress-nginx uses the path
+	// of the Ingress.
+	path string
+}
+
+// sessionAffinityFeature converts the session affinity annotations.
+//
+// The sessionPersistence of the HTTPRoute rules is not part of the Gateway
+// API version of this tool, so they are converted to the cookie-based
+// consistent hash load balancer of the BackendTrafficPolicy of the rules of
+// each Ingress, recorded in routePolicies, with the envoy-gateway target
+// implementation. The differences in the cookie semantics are reported.
+// Without a target implementation, the affinity of each Ingress is reported,
+// to be configured manually.
+func sessionAffinityFeature(target string, policies *routePolicies) i2gw.FeatureParser {
+	return func(ingresses []networkingv1.Ingress, gatewayResources *i2gw.GatewayResources) field.ErrorList {
+		var errs field.ErrorList
+		affinityByIngress := map[types.NamespacedName]*ingressSessionAffinity{}
+		for i := range ingresses {
+			affinity, parseErrs := parseSessionAffinity(ingresses[i])
+			errs = append(errs, parseErrs...)
+			if affinity == nil {
+				continue
+			}
+			affinityByIngress[types.NamespacedName{Namespace: ingresses[i].Namespace, Name: ingresses[i].Name}] = affinity
+			if target == "" {
+				notifyManualAction(&ingresses[i], "session affinity requires the sessionPersistence of Gateway API v1.1 or later", affinity)
+			}
+		}
+		if len(errs) > 0 || target == "" {
+			return errs
+		}
+
+		converted := map[types.NamespacedName]bool{}
+		forEachRuleSource(ingresses, gatewayResources, true, func(key types.NamespacedName, ruleIdx int, source ruleSource) {
+			ingressKey := types.NamespacedName{Namespace: source.ingress.Namespace, Name: source.ingress.Name}
+			affinity := affinityByIngress[ingressKey]
+			if affinity == nil {
+				return
+			}
+			policies.set(key, ruleIdx, source, envoyGatewayBackendTrafficPolicyGVK, "loadBalancer", affinity.envoyGatewayLoadBalancer())
+			if !converted[ingressKey] {
+				converted[ingressKey] = true
+				notifications.NotificationAggr.DispatchNotification(notifications.NewNotification(
+					notifications.WarningNotification,
+					fmt.Sprintf("the session affinity of %s/%s is converted to the consistent hash on cookie %s of the BackendTrafficPolicy of the HTTPRoutes of its rules: %s",
+						ingressKey.Namespace, ingressKey.Name, affinity.cookieName, strings.Join(affinity.cookieDifferences(), ", ")),
+					&source.ingress,
+				), Name)
+			}
+		})
+
+		return nil
+	}
+}
+
+// parseSessionAffinity returns the session affinity of the Ingress, nil when
+// it has none.
+func parseSessionAffinity(ingress networkingv1.Ingress) (*ingressSessionAffinity, field.ErrorList) {
+	affinityType, ok := ingress.Annotations[affinityAnnotation]
+	if !ok {
+		return nil, nil
+	}
+	fieldPath := field.NewPath(ingress.Name).Child("metadata").Child("annotations")
+	if affinityType != cookieAffinity {
+		return nil, field.ErrorList{field.NotSupported(fieldPath.Key(affinityAnnotation), affinityType, []string{cookieAffinity})}
+	}
+
+	var errs field.ErrorList
+	affinity := &ingressSessionAffinity{
+		mode:       balancedAffinityMode,
+		cookieName: defaultSessionCookieName,
+		path:       ingress.Annotations[sessionCookiePathAnnotation],
+	}
+	if mode, ok := ingress.Annotations[affinityModeAnnotation]; ok {
+		if mode != balancedAffinityMode && mode != persistentAffinityMode {
+			errs = append(errs, field.NotSupported(fieldPath.Key(affinityModeAnnotation), mode, []string{balancedAffinityMode, persistentAffinityMode}))
+		}
+		affinity.mode = mode
+	}
+	if name := ingress.Annotations[sessionCookieNameAnnotation]; name != "" {
+		affinity.cookieName = name
+	}
+	if value, ok := ingress.Annotations[sessionCookieMaxAgeAnnotation]; ok {
+		maxAge, err := strconv.Atoi(value)
+		if err != nil || maxAge < 0 {
+			errs = append(errs, field.Invalid(fieldPath.Key(sessionCookieMaxAgeAnnotation), value, "must be a number of seconds"))
+		}
+		affinity.maxAge = &maxAge
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return affinity, nil
+}
+
+// String renders the affinity with the names of the annotations.
+func (s *ingressSessionAffinity) String() string {
+	maxAge := "none"
+	if s.maxAge != nil {
+		maxAge = fmt.Sprintf("%ds", *s.maxAge)
+	}
+	return fmt.Sprintf("affinity cookie, affinity-mode %s, session-cookie-name %s, session-cookie-max-age %s, session-cookie-path %q",
+		s.mode, s.cookieName, maxAge, s.path)
+}
+
+// envoyGatewayLoadBalancer returns the loadBalancer field of an Envoy Gateway
+// BackendTrafficPolicy. A TTL of zero makes Envoy generate session cookies.
+func (s *ingressSessionAffinity) envoyGatewayLoadBalancer() map[string]interface{} {
+	ttl := 0
+	if s.maxAge != nil {
+		ttl = *s.maxAge
+	}
+	cookie := map[string]interface{}{
+		"name": s.cookieName,
+		"ttl":  fmt.Sprintf("%ds", ttl),
+	}
+	if s.path != "" {
+		cookie["attributes"] = map[string]interface{}{"Path": s.path}
+	}
+	return map[string]interface{}{
+		"type": "ConsistentHash",
+		"consistentHash": map[string]interface{}{
+			"type":   "Cookie",
+			"cookie": cookie,
+		},
+	}
+}
+
+// cookieDifferences describes how the cookie of Envoy Gateway differs from the
+// one of ingress-nginx.
+func (s *ingressSessionAffinity) cookieDifferences() []string {
+	differences := []string{
+		"the cookies issued by ingress-nginx name its upstreams, so the existing sessions are assigned a new backend once",
+	}
+	if s.mode == persistentAffinityMode {
+		differences = append(differences, fmt.Sprintf("%s %s cannot be converted, and a share of the sessions move to another backend when the endpoints change, as with %s",
+			affinityModeAnnotation, s.mode, balancedAffinityMode))
+	} else {
+		differences = append(differences, "a share of the sessions move to another backend when the endpoints change, as with the balanced mode")
+	}
+	if s.maxAge == nil {
+		differences = append(differences, "the cookie is a session cookie, as without session-cookie-max-age, generated with a TTL of 0")
+	}
+	if s.path == "" {
+		differences = append(differences, "the cookie has no Path attribute, while ingress-nginx sets the path of the Ingress, so that the browsers scope it to the directory of the request path")
+	}
+	return differences
+}
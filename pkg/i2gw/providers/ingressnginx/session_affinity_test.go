@@ -0,0 +1,116 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingressnginx
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func Test_sessionAffinityFeature(t *testing.T) {
+	envoyGateway := map[string]string{TargetImplementationFlag: envoyGatewayImplementation}
+
+	testCases := []struct {
+		name                  string
+		flags                 map[string]string
+		annotations           map[string]string
+		expectedLoadBalancer  map[string]interface{}
+		expectedNotifications []string
+	}{{
+		name:  "cookie with max age and path",
+		flags: envoyGateway,
+		annotations: map[string]string{
+			affinityAnnotation:            "cookie",
+			sessionCookieNameAnnotation:   "route",
+			sessionCookieMaxAgeAnnotation: "172800",
+			sessionCookiePathAnnotation:   "/app",
+		},
+		expectedLoadBalancer: map[string]interface{}{
+			"type": "ConsistentHash",
+			"consistentHash": map[string]interface{}{
+				"type": "Cookie",
+				"cookie": map[string]interface{}{
+					"name":       "route",
+					"ttl":        "172800s",
+					"attributes": map[string]interface{}{"Path": "/app"},
+				},
+			},
+		},
+		expectedNotifications: []string{
+			"is converted to the consistent hash on cookie route of the BackendTrafficPolicy of the HTTPRoutes of its rules",
+			"the existing sessions are assigned a new backend once",
+		},
+	}, {
+		name:  "persistent session cookie",
+		flags: envoyGateway,
+		annotations: map[string]string{
+			affinityAnnotation:     "cookie",
+			affinityModeAnnotation: "persistent",
+		},
+		expectedLoadBalancer: map[string]interface{}{
+			"type": "ConsistentHash",
+			"consistentHash": map[string]interface{}{
+				"type":   "Cookie",
+				"cookie": map[string]interface{}{"name": "INGRESSCOOKIE", "ttl": "0s"},
+			},
+		},
+		expectedNotifications: []string{
+			"nginx.ingress.kubernetes.io/affinity-mode persistent cannot be converted",
+			"the cookie is a session cookie",
+			"the cookie has no Path attribute",
+		},
+	}, {
+		name: "no target implementation",
+		annotations: map[string]string{
+			affinityAnnotation: "cookie",
+		},
+		expectedNotifications: []string{
+			"session affinity requires the sessionPersistence of Gateway API v1.1 or later, manual action required: configure affinity cookie, affinity-mode balanced, session-cookie-name INGRESSCOOKIE",
+		},
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			conf := &i2gw.ProviderConf{ProviderSpecificFlags: map[string]map[string]string{Name: tc.flags}}
+			gatewayResources, notificationTable := convertTestIngresses(t, conf,
+				testIngress("a", "example.com", "/", networkingv1.PathTypePrefix, tc.annotations))
+
+			var loadBalancer map[string]interface{}
+			key := i2gw.ExtensionResourceKey{
+				GroupKind:      envoyGatewayBackendTrafficPolicyGVK.GroupKind(),
+				NamespacedName: types.NamespacedName{Namespace: "default", Name: "a-example-com"},
+			}
+			if policy, ok := gatewayResources.ExtensionResources[key]; ok {
+				loadBalancer, _, _ = unstructured.NestedMap(policy.Object, "spec", "loadBalancer")
+			}
+			if diff := cmp.Diff(tc.expectedLoadBalancer, loadBalancer); diff != "" {
+				t.Errorf("Unexpected load balancer, diff (-want +got):\n%s", diff)
+			}
+			for _, expected := range tc.expectedNotifications {
+				if !strings.Contains(notificationTable, expected) {
+					t.Errorf("Expected notification %q, got:\n%s", expected, notificationTable)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,529 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingressnginx
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/providers/common"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+const (
+	configurationSnippetAnnotation = "nginx.ingress.kubernetes.io/configuration-snippet"
+	serverSnippetAnnotation        = "nginx.ingress.kubernetes.io/server-snippet"
+	authSnippetAnnotation          = "nginx.ingress.kubernetes.io/auth-snippet"
+	streamSnippetAnnotation        = "nginx.ingress.kubernetes.io/stream-snippet"
+)
+
+// redirectStatusCodes are the status codes of the return directives redirecting
+// to their URL.
+var redirectStatusCodes = sets.New(301, 302, 303, 307, 308)
+
+// headerDirectives are the directives translated to header filters.
+var headerDirectives = sets.New("more_set_headers", "add_header", "proxy_set_header")
+
+// snippetRisk is the risk of leaving a snippet directive out of the
+// conversion.
+type snippetRisk string
+
+const (
+	// highSnippetRisk is for the directives controlling the access to the
+	// paths, which would be open once left out, so auth leaves their rules
+	// out of the HTTPRoutes unless AllowUnprotectedRoutesFlag is set.
+	highSnippetRisk   snippetRisk = "high"
+	mediumSnippetRisk snippetRisk = "medium"
+)
+
+// snippetDirective is a directive, or a block, of a snippet.
+type snippetDirective struct {
+	// text is the directive as written, with its whitespace collapsed.
+	text string
+	// args are the name and the unquoted arguments of the directive.
+	args  []string
+	block bool
+}
+
+// untranslatedDirective is a snippet directive left out of the conversion.
+type untranslatedDirective struct {
+	annotation string
+	text       string
+	risk       snippetRisk
+	reason     string
+}
+
+// snippetTranslation is the translation of the directives of a snippet.
+type snippetTranslation struct {
+	requestSet     []gatewayv1.HTTPHeader
+	requestRemove  []string
+	responseSet    []gatewayv1.HTTPHeader
+	responseAdd    []gatewayv1.HTTPHeader
+	redirect       *gatewayv1.HTTPRequestRedirectFilter
+	translated     []string
+	untranslated   []untranslatedDirective
+	translatedNote []string
+}
+
+// ingressSnippets are the translations of the snippets of an Ingress.
+type ingressSnippets struct {
+	// location applies to the paths of the Ingress, and server to every path
+	// of its hosts.
+	location, server *snippetTranslation
+}
+
+// snippetFeature converts the snippet annotations, whose raw nginx
+// configuration can never be translated as a whole.
+//
+// The directives of configuration-snippet and server-snippet with a Gateway
+// API equivalent are translated: more_set_headers and add_header to
+// ResponseHeaderModifier filters, proxy_set_header to RequestHeaderModifier
+// filters, and return with a redirect status code to RequestRedirect rules.
+// The configuration-snippet applies to the rules of the Ingress, and the
+// server-snippet to every rule of the HTTPRoute of the host. The other
+// directives, and the auth-snippet and stream-snippet annotations, are
+// reported one by one with their risk, the directives controlling the access
+// to the paths being high risk: like the authentications which cannot
+// be converted, their paths are left out by auth.feature, through
+// parseSnippetAccess and serverSnippetAccess.
+//
+// The redirects drop the filters of the rules, so this must run after the
+// features adding filters, and before redirectFeature, whose redirects take
+// precedence.
+func snippetFeature(ingresses []networkingv1.Ingress, gatewayResources *i2gw.GatewayResources) field.ErrorList {
+	snippetsByIngress := map[types.NamespacedName]ingressSnippets{}
+	for i := range ingresses {
+		ingress := &ingresses[i]
+		snippets := ingressSnippets{
+			location: translateSnippet(configurationSnippetAnnotation, ingress.Annotations[configurationSnippetAnnotation]),
+			server:   translateSnippet(serverSnippetAnnotation, ingress.Annotations[serverSnippetAnnotation]),
+		}
+		notifySnippetRisks(ingress, []*snippetTranslation{snippets.location, snippets.server}, untranslatedSnippets(ingress))
+		if snippets.location != nil || snippets.server != nil {
+			snippetsByIngress[types.NamespacedName{Namespace: ingress.Namespace, Name: ingress.Name}] = snippets
+		}
+	}
+
+	for _, rg := range sortedRuleGroups(ingresses) {
+		key := types.NamespacedName{Namespace: rg.Namespace, Name: common.RouteName(rg.Name, rg.Host)}
+		httpRoute, ok := gatewayResources.HTTPRoutes[key]
+		if !ok {
+			continue
+		}
+
+		// Like ingress-nginx, a single server-snippet applies to the host.
+		var server *snippetTranslation
+		var serverIngress types.NamespacedName
+		seen := map[types.NamespacedName]bool{}
+		for i := range rg.Rules {
+			ingress := &rg.Rules[i].Ingress
+			ingressKey := types.NamespacedName{Namespace: ingress.Namespace, Name: ingress.Name}
+			snippets := snippetsByIngress[ingressKey]
+			if snippets.server == nil || seen[ingressKey] {
+				continue
+			}
+			seen[ingressKey] = true
+			if server == nil {
+				server, serverIngress = snippets.server, ingressKey
+				continue
+			}
+			notifications.NotificationAggr.DispatchNotification(notifications.NewNotification(
+				notifications.WarningNotification,
+				fmt.Sprintf("the %s of %s is ignored, as the one of %s applies to host %q",
+					serverSnippetAnnotation, ingressKey, serverIngress, rg.Host),
+				ingress,
+			), Name)
+		}
+
+		sourcesByRule := ruleSourcesByIndex(rg, httpRoute)
+		for _, ruleIdx := range sortedRuleIndexes(sourcesByRule) {
+			rule := &httpRoute.Spec.Rules[ruleIdx]
+			if hasRequestRedirect(*rule) {
+				continue
+			}
+			source := annotationSources(sourcesByRule[ruleIdx])[0]
+			location := snippetsByIngress[types.NamespacedName{Namespace: source.ingress.Namespace, Name: source.ingress.Name}].location
+			// A server redirect answers the requests before the locations are
+			// matched. Otherwise, the server directives apply first, so that
+			// the ones of the location win.
+			translations := []*snippetTranslation{server, location}
+			if server != nil && server.redirect != nil {
+				translations = []*snippetTranslation{server}
+			}
+			for _, translation := range translations {
+				if translation != nil {
+					translation.apply(rule)
+				}
+			}
+		}
+		gatewayResources.HTTPRoutes[key] = httpRoute
+	}
+
+	return nil
+}
+
+// untranslatedSnippets returns the auth-snippet and stream-snippet of the
+// Ingress, which have no equivalent.
+func untranslatedSnippets(ingress *networkingv1.Ingress) []untranslatedDirective {
+	var untranslated []untranslatedDirective
+	for _, annotation := range []string{authSnippetAnnotation, streamSnippetAnnotation} {
+		snippet := strings.TrimSpace(ingress.Annotations[annotation])
+		if snippet == "" {
+			continue
+		}
+		risk, reason := mediumSnippetRisk, "the TCP and UDP proxying of nginx has no equivalent"
+		if annotation == authSnippetAnnotation {
+			risk, reason = highSnippetRisk, "the requests to the authentication service cannot be customized"
+		}
+		untranslated = append(untranslated, untranslatedDirective{annotation: annotation, text: collapseWhitespace(snippet), risk: risk, reason: reason})
+	}
+	return untranslated
+}
+
+// parseSnippetAccess returns the access control of the configuration-snippet
+// and auth-snippet of the Ingress, which cannot be converted, nil when none of
+// their directives left out is high risk.
+func parseSnippetAccess(ingress *networkingv1.Ingress) (*ingressAuth, field.ErrorList) {
+	untranslated := untranslatedSnippets(ingress)
+	if location := translateSnippet(configurationSnippetAnnotation, ingress.Annotations[configurationSnippetAnnotation]); location != nil {
+		untranslated = append(location.untranslated, untranslated...)
+	}
+	for _, directive := range untranslated {
+		if directive.risk == highSnippetRisk {
+			return &ingressAuth{annotation: directive.annotation, unconverted: fmt.Sprintf("%q is left out of the conversion", directive.text)}, nil
+		}
+	}
+	return nil, nil
+}
+
+// serverSnippetAccess returns the access control of the server-snippet of the
+// HTTPRoutes, which cannot be converted, by HTTPRoute. Like snippetFeature,
+// the server-snippet of the first Ingress of the host setting one applies to
+// every path of the host.
+func serverSnippetAccess(ingresses []networkingv1.Ingress) map[types.NamespacedName]*ingressAuth {
+	serverAccess := map[types.NamespacedName]*ingressAuth{}
+	for _, rg := range sortedRuleGroups(ingresses) {
+		for i := range rg.Rules {
+			ingress := &rg.Rules[i].Ingress
+			server := translateSnippet(serverSnippetAnnotation, ingress.Annotations[serverSnippetAnnotation])
+			if server == nil {
+				continue
+			}
+			for _, directive := range server.untranslated {
+				if directive.risk == highSnippetRisk {
+					serverAccess[types.NamespacedName{Namespace: rg.Namespace, Name: common.RouteName(rg.Name, rg.Host)}] = &ingressAuth{
+						annotation:  serverSnippetAnnotation,
+						unconverted: fmt.Sprintf("%q of %s/%s, which applies to host %q, is left out of the conversion", directive.text, ingress.Namespace, ingress.Name, rg.Host),
+					}
+					break
+				}
+			}
+			break
+		}
+	}
+	return serverAccess
+}
+
+// translateSnippet parses the directives of a configuration-snippet or
+// server-snippet, nil when it is empty.
+func translateSnippet(annotation, snippet string) *snippetTranslation {
+	if strings.TrimSpace(snippet) == "" {
+		return nil
+	}
+	translation := &snippetTranslation{}
+	directives := parseSnippet(snippet)
+	for i, directive := range directives {
+		translation.translate(annotation, directive, directives[i+1:])
+	}
+	return translation
+}
+
+// translate translates the directive, or records why it is left out. The
+// directives following it tell whether an allow directive restricts the
+// access, when followed by deny all.
+func (t *snippetTranslation) translate(annotation string, directive snippetDirective, following []snippetDirective) {
+	untranslated := func(risk snippetRisk, reason string) {
+		t.untranslated = append(t.untranslated, untranslatedDirective{annotation: annotation, text: directive.text, risk: risk, reason: reason})
+	}
+	if directive.block {
+		if blockDenies(directive) {
+			untranslated(highSnippetRisk, fmt.Sprintf("the IP access restrictions of snippets cannot be converted, use %s or %s", whitelistSourceRangeAnnotation, denylistSourceRangeAnnotation))
+			return
+		}
+		untranslated(mediumSnippetRisk, "nginx blocks have no equivalent")
+		return
+	}
+	name, args := directive.args[0], directive.args[1:]
+	if headerDirectives.Has(name) {
+		for _, arg := range args {
+			if strings.Contains(arg, "$") {
+				untranslated(mediumSnippetRisk, "nginx variables cannot be converted")
+				return
+			}
+		}
+	}
+
+	switch name {
+	case "more_set_headers":
+		var headers []gatewayv1.HTTPHeader
+		for _, arg := range args {
+			headerName, value, ok := strings.Cut(arg, ":")
+			value = strings.TrimSpace(value)
+			if strings.HasPrefix(arg, "-") || !ok || value == "" {
+				untranslated(mediumSnippetRisk, "only the headers set on every response, with a value, can be converted")
+				return
+			}
+			headers = append(headers, gatewayv1.HTTPHeader{Name: gatewayv1.HTTPHeaderName(strings.TrimSpace(headerName)), Value: value})
+		}
+		if len(headers) == 0 {
+			untranslated(mediumSnippetRisk, "it sets no header")
+			return
+		}
+		t.responseSet = append(t.responseSet, headers...)
+	case "add_header":
+		if len(args) < 2 || len(args) > 3 || (len(args) == 3 && args[2] != "always") {
+			untranslated(mediumSnippetRisk, "it is not a valid add_header directive")
+			return
+		}
+		t.responseAdd = append(t.responseAdd, gatewayv1.HTTPHeader{Name: gatewayv1.HTTPHeaderName(args[0]), Value: args[1]})
+		if len(args) == 2 {
+			t.translatedNote = append(t.translatedNote, fmt.Sprintf("%q is added to every response, while nginx only adds it to the successful and redirect ones without always", directive.text))
+		}
+	case "proxy_set_header":
+		if len(args) != 2 {
+			untranslated(mediumSnippetRisk, "it is not a valid proxy_set_header directive")
+			return
+		}
+		if args[1] == "" {
+			t.requestRemove = append(t.requestRemove, args[0])
+		} else {
+			t.requestSet = append(t.requestSet, gatewayv1.HTTPHeader{Name: gatewayv1.HTTPHeaderName(args[0]), Value: args[1]})
+		}
+	case "return":
+		if len(args) == 0 {
+			untranslated(mediumSnippetRisk, "it is not a valid return directive")
+			return
+		}
+		code, err := strconv.Atoi(args[0])
+		if len(args) != 2 || err != nil || !redirectStatusCodes.Has(code) {
+			risk := mediumSnippetRisk
+			if err == nil && code >= 400 {
+				risk = highSnippetRisk
+			}
+			untranslated(risk, "only the redirects to a URL can be converted")
+			return
+		}
+		filter, lost, unconverted, err := redirectFilter(args[1], code)
+		switch {
+		case err != nil:
+			untranslated(mediumSnippetRisk, err.Error())
+			return
+		case unconverted != "":
+			untranslated(mediumSnippetRisk, fmt.Sprintf("its URL %s", unconverted))
+			return
+		}
+		t.redirect = filter
+		for _, note := range lost {
+			t.translatedNote = append(t.translatedNote, fmt.Sprintf("for %q, %s", directive.text, note))
+		}
+	case "rewrite":
+		untranslated(mediumSnippetRisk, fmt.Sprintf("nginx rewrites cannot be converted, use %s", rewriteTargetAnnotation))
+		return
+	case "allow", "deny":
+		// An allow directive only restricts the access when the others are
+		// denied afterwards.
+		if name == "allow" && !deniesAll(following) {
+			untranslated(mediumSnippetRisk, "allow directives without a following deny all restrict no client")
+			return
+		}
+		untranslated(highSnippetRisk, fmt.Sprintf("the IP access restrictions of snippets cannot be converted, use %s or %s", whitelistSourceRangeAnnotation, denylistSourceRangeAnnotation))
+		return
+	default:
+		untranslated(mediumSnippetRisk, "the directive has no equivalent")
+		return
+	}
+	t.translated = append(t.translated, directive.text)
+}
+
+// deniesAll returns whether one of the directives is deny all.
+func deniesAll(directives []snippetDirective) bool {
+	for _, directive := range directives {
+		if !directive.block && len(directive.args) == 2 && directive.args[0] == "deny" && directive.args[1] == "all" {
+			return true
+		}
+	}
+	return false
+}
+
+// blockDenies returns whether the block, or one of its nested blocks,
+// contains a deny directive.
+func blockDenies(block snippetDirective) bool {
+	start, end := strings.Index(block.text, "{"), strings.LastIndex(block.text, "}")
+	if start < 0 || end <= start {
+		return false
+	}
+	for _, directive := range parseSnippet(block.text[start+1 : end]) {
+		if (!directive.block && directive.args[0] == "deny") || (directive.block && blockDenies(directive)) {
+			return true
+		}
+	}
+	return false
+}
+
+// apply adds the translated directives to the rule.
+func (t *snippetTranslation) apply(rule *gatewayv1.HTTPRouteRule) {
+	if t.redirect != nil {
+		*rule = gatewayv1.HTTPRouteRule{
+			Matches: rule.Matches,
+			Filters: []gatewayv1.HTTPRouteFilter{{
+				Type:            gatewayv1.HTTPRouteFilterRequestRedirect,
+				RequestRedirect: t.redirect.DeepCopy(),
+			}},
+		}
+		return
+	}
+	common.SetRuleHeaders(rule, gatewayv1.HTTPRouteFilterRequestHeaderModifier, t.requestSet)
+	common.SetRuleHeaders(rule, gatewayv1.HTTPRouteFilterResponseHeaderModifier, t.responseSet)
+	if len(t.requestRemove) > 0 {
+		headerFilter := common.RuleHeaderFilter(rule, gatewayv1.HTTPRouteFilterRequestHeaderModifier)
+		headerFilter.Remove = append(headerFilter.Remove, t.requestRemove...)
+	}
+	if len(t.responseAdd) > 0 {
+		headerFilter := common.RuleHeaderFilter(rule, gatewayv1.HTTPRouteFilterResponseHeaderModifier)
+		headerFilter.Add = append(headerFilter.Add, t.responseAdd...)
+	}
+}
+
+// notifySnippetRisks reports the translated directives of the snippets of the
+// Ingress, and each directive left out with its own severity: an error when it
+// controls the access to the paths, a warning otherwise.
+func notifySnippetRisks(ingress *networkingv1.Ingress, translations []*snippetTranslation, untranslated []untranslatedDirective) {
+	var translated, notes []string
+	for _, translation := range translations {
+		if translation == nil {
+			continue
+		}
+		translated = append(translated, translation.translated...)
+		notes = append(notes, translation.translatedNote...)
+		untranslated = append(untranslated, translation.untranslated...)
+	}
+
+	if len(translated) > 0 {
+		notifications.NotificationAggr.DispatchNotification(notifications.NewNotification(
+			notifications.InfoNotification,
+			fmt.Sprintf("the snippet directives %q are translated", translated),
+			ingress,
+		), Name)
+	}
+	for _, note := range notes {
+		notifications.NotificationAggr.DispatchNotification(notifications.NewNotification(notifications.WarningNotification, note, ingress), Name)
+	}
+	for _, directive := range untranslated {
+		notificationType := notifications.WarningNotification
+		if directive.risk == highSnippetRisk {
+			notificationType = notifications.ErrorNotification
+		}
+		notifications.NotificationAggr.DispatchNotification(notifications.NewNotification(
+			notificationType,
+			fmt.Sprintf("[%s risk] %s %q is left out: %s", directive.risk, directive.annotation, directive.text, directive.reason),
+			ingress,
+		), Name)
+	}
+}
+
+// parseSnippet splits the nginx configuration of a snippet into its
+// directives, blocks being kept whole.
+func parseSnippet(snippet string) []snippetDirective {
+	var directives []snippetDirective
+	var args []string
+	var current strings.Builder
+	hasToken := false
+	endToken := func() {
+		if hasToken {
+			args = append(args, current.String())
+		}
+		current.Reset()
+		hasToken = false
+	}
+	start := 0
+	for i := 0; i < len(snippet); i++ {
+		c := snippet[i]
+		switch {
+		case c == '#':
+			endToken()
+			for i < len(snippet) && snippet[i] != '\n' {
+				i++
+			}
+			if len(args) == 0 {
+				start = i + 1
+			}
+		case c == '"' || c == '\'':
+			hasToken = true
+			for i++; i < len(snippet) && snippet[i] != c; i++ {
+				if snippet[i] == '\\' && i+1 < len(snippet) {
+					i++
+				}
+				current.WriteByte(snippet[i])
+			}
+		case c == ';':
+			endToken()
+			if len(args) > 0 {
+				directives = append(directives, snippetDirective{text: collapseWhitespace(snippet[start : i+1]), args: args})
+			}
+			args, start = nil, i+1
+		case c == '{':
+			endToken()
+			depth := 1
+			for i++; i < len(snippet) && depth > 0; i++ {
+				switch snippet[i] {
+				case '{':
+					depth++
+				case '}':
+					depth--
+				}
+			}
+			i--
+			directives = append(directives, snippetDirective{text: collapseWhitespace(snippet[start : i+1]), args: args, block: true})
+			args, start = nil, i+1
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			endToken()
+			if len(args) == 0 {
+				start = i + 1
+			}
+		default:
+			hasToken = true
+			current.WriteByte(c)
+		}
+	}
+	endToken()
+	if len(args) > 0 {
+		directives = append(directives, snippetDirective{text: collapseWhitespace(snippet[start:]), args: args})
+	}
+	return directives
+}
+
+// collapseWhitespace replaces the runs of whitespace with single spaces.
+func collapseWhitespace(value string) string {
+	return strings.Join(strings.Fields(value), " ")
+}
@@ -0,0 +1,265 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingressnginx
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+func Test_parseSnippet(t *testing.T) {
+	snippet := `# headers
+more_set_headers "X-Frame-Options: DENY" 'X-Team: payments';
+add_header X-Escaped "a \"b\"" always;
+location /internal { deny all; }
+return 301 https://example.com`
+
+	expected := []snippetDirective{
+		{text: `more_set_headers "X-Frame-Options: DENY" 'X-Team: payments';`, args: []string{"more_set_headers", "X-Frame-Options: DENY", "X-Team: payments"}},
+		{text: `add_header X-Escaped "a \"b\"" always;`, args: []string{"add_header", "X-Escaped", `a "b"`, "always"}},
+		{text: "location /internal { deny all; }", args: []string{"location", "/internal"}, block: true},
+		{text: "return 301 https://example.com", args: []string{"return", "301", "https://example.com"}},
+	}
+	if diff := cmp.Diff(expected, parseSnippet(snippet), cmp.AllowUnexported(snippetDirective{})); diff != "" {
+		t.Errorf("Unexpected directives, diff (-want +got):\n%s", diff)
+	}
+}
+
+func Test_snippetFeature(t *testing.T) {
+	testCases := []struct {
+		name                  string
+		flags                 map[string]string
+		ingresses             []networkingv1.Ingress
+		expectedFilters       map[string][]gatewayv1.HTTPRouteFilter
+		expectedNotifications []string
+		// expectedTypes are the types of the notifications with the
+		// messages.
+		expectedTypes map[string]notifications.MessageType
+	}{{
+		name: "header directives",
+		ingresses: []networkingv1.Ingress{
+			testIngress("a", "example.com", "/", networkingv1.PathTypePrefix, map[string]string{
+				configurationSnippetAnnotation: `more_set_headers "X-Frame-Options: DENY";
+add_header Cache-Control no-store;
+proxy_set_header X-Team payments;
+proxy_set_header Authorization "";`,
+			}),
+		},
+		expectedFilters: map[string][]gatewayv1.HTTPRouteFilter{
+			"/": {{
+				Type: gatewayv1.HTTPRouteFilterRequestHeaderModifier,
+				RequestHeaderModifier: &gatewayv1.HTTPHeaderFilter{
+					Set:    []gatewayv1.HTTPHeader{{Name: "X-Team", Value: "payments"}},
+					Remove: []string{"Authorization"},
+				},
+			}, {
+				Type: gatewayv1.HTTPRouteFilterResponseHeaderModifier,
+				ResponseHeaderModifier: &gatewayv1.HTTPHeaderFilter{
+					Set: []gatewayv1.HTTPHeader{{Name: "X-Frame-Options", Value: "DENY"}},
+					Add: []gatewayv1.HTTPHeader{{Name: "Cache-Control", Value: "no-store"}},
+				},
+			}},
+		},
+		expectedNotifications: []string{
+			`the snippet directives ["more_set_headers \"X-Frame-Options: DENY\";"`,
+			"while nginx only adds it to the successful and redirect ones without always",
+		},
+	}, {
+		name: "server redirect and location headers",
+		ingresses: []networkingv1.Ingress{
+			testIngress("a", "example.com", "/", networkingv1.PathTypePrefix, map[string]string{
+				serverSnippetAnnotation: "return 308 https://www.example.com/;",
+			}),
+			testIngress("b", "example.com", "/api", networkingv1.PathTypePrefix, map[string]string{
+				configurationSnippetAnnotation: "add_header X-Api yes always;",
+			}),
+		},
+		expectedFilters: map[string][]gatewayv1.HTTPRouteFilter{
+			"/": {{
+				Type: gatewayv1.HTTPRouteFilterRequestRedirect,
+				RequestRedirect: &gatewayv1.HTTPRequestRedirectFilter{
+					Scheme:     ptr.To("https"),
+					Hostname:   ptr.To(gatewayv1.PreciseHostname("www.example.com")),
+					Path:       &gatewayv1.HTTPPathModifier{Type: gatewayv1.FullPathHTTPPathModifier, ReplaceFullPath: ptr.To("/")},
+					StatusCode: ptr.To(301),
+				},
+			}},
+			"/api": {{
+				Type: gatewayv1.HTTPRouteFilterRequestRedirect,
+				RequestRedirect: &gatewayv1.HTTPRequestRedirectFilter{
+					Scheme:     ptr.To("https"),
+					Hostname:   ptr.To(gatewayv1.PreciseHostname("www.example.com")),
+					Path:       &gatewayv1.HTTPPathModifier{Type: gatewayv1.FullPathHTTPPathModifier, ReplaceFullPath: ptr.To("/")},
+					StatusCode: ptr.To(301),
+				},
+			}},
+		},
+		expectedNotifications: []string{"status code 308 is converted to 301"},
+	}, {
+		name:  "untranslated directives, acknowledged",
+		flags: map[string]string{AllowUnprotectedRoutesFlag: "true"},
+		ingresses: []networkingv1.Ingress{
+			testIngress("a", "example.com", "/", networkingv1.PathTypePrefix, map[string]string{
+				configurationSnippetAnnotation: `rewrite ^/old/(.*)$ /new/$1 break;
+deny 10.0.0.0/8;
+more_set_headers -s 404 "X-Not-Found: true";
+expires 1h;`,
+				streamSnippetAnnotation: "server { listen 5000; }",
+			}),
+		},
+		expectedFilters: map[string][]gatewayv1.HTTPRouteFilter{"/": nil},
+		expectedNotifications: []string{
+			`[medium risk] nginx.ingress.kubernetes.io/configuration-snippet "rewrite ^/old/(.*)$ /new/$1 break;" is left out: nginx rewrites cannot be converted, use nginx.ingress.kubernetes.io/rewrite-target`,
+			`[high risk] nginx.ingress.kubernetes.io/configuration-snippet "deny 10.0.0.0/8;" is left out: the IP access restrictions of snippets cannot be converted`,
+			`the paths "/" of HTTPRoute default/a-example-com are converted without the access control of nginx.ingress.kubernetes.io/configuration-snippet, as "deny 10.0.0.0/8;" is left out of the conversion`,
+			"only the headers set on every response, with a value, can be converted",
+			`"expires 1h;" is left out: the directive has no equivalent`,
+			`[medium risk] nginx.ingress.kubernetes.io/stream-snippet "server { listen 5000; }" is left out`,
+		},
+		expectedTypes: map[string]notifications.MessageType{
+			`configuration-snippet "rewrite ^/old/(.*)$ /new/$1 break;" is left out`: notifications.WarningNotification,
+			`configuration-snippet "deny 10.0.0.0/8;" is left out`:                   notifications.ErrorNotification,
+			`configuration-snippet "expires 1h;" is left out`:                        notifications.WarningNotification,
+		},
+	}, {
+		name: "allow without deny all",
+		ingresses: []networkingv1.Ingress{
+			testIngress("a", "example.com", "/", networkingv1.PathTypePrefix, map[string]string{
+				configurationSnippetAnnotation: "allow all;",
+			}),
+		},
+		expectedFilters: map[string][]gatewayv1.HTTPRouteFilter{"/": nil},
+		expectedNotifications: []string{
+			`[medium risk] nginx.ingress.kubernetes.io/configuration-snippet "allow all;" is left out: allow directives without a following deny all restrict no client`,
+		},
+		expectedTypes: map[string]notifications.MessageType{
+			`"allow all;" is left out`: notifications.WarningNotification,
+		},
+	}, {
+		name: "allow followed by deny all",
+		ingresses: []networkingv1.Ingress{
+			testIngress("a", "example.com", "/", networkingv1.PathTypePrefix, map[string]string{
+				configurationSnippetAnnotation: `allow 10.0.0.0/8;
+deny all;`,
+			}),
+			testIngress("b", "example.com", "/public", networkingv1.PathTypePrefix, nil),
+		},
+		expectedFilters: map[string][]gatewayv1.HTTPRouteFilter{"/public": nil},
+		expectedNotifications: []string{
+			`[high risk] nginx.ingress.kubernetes.io/configuration-snippet "allow 10.0.0.0/8;" is left out: the IP access restrictions of snippets cannot be converted`,
+			`[high risk] nginx.ingress.kubernetes.io/configuration-snippet "deny all;" is left out: the IP access restrictions of snippets cannot be converted`,
+		},
+		expectedTypes: map[string]notifications.MessageType{
+			`"allow 10.0.0.0/8;" is left out`: notifications.ErrorNotification,
+			`"deny all;" is left out`:         notifications.ErrorNotification,
+		},
+	}, {
+		name:  "location block with deny, acknowledged",
+		flags: map[string]string{AllowUnprotectedRoutesFlag: "true"},
+		ingresses: []networkingv1.Ingress{
+			testIngress("a", "example.com", "/", networkingv1.PathTypePrefix, map[string]string{
+				configurationSnippetAnnotation: "location /internal { deny all; }",
+			}),
+		},
+		expectedFilters: map[string][]gatewayv1.HTTPRouteFilter{"/": nil},
+		expectedNotifications: []string{
+			`[high risk] nginx.ingress.kubernetes.io/configuration-snippet "location /internal { deny all; }" is left out: the IP access restrictions of snippets cannot be converted`,
+		},
+	}, {
+		name: "auth snippet",
+		ingresses: []networkingv1.Ingress{
+			testIngress("a", "example.com", "/", networkingv1.PathTypePrefix, map[string]string{
+				authSnippetAnnotation: "proxy_set_header X-Auth yes;",
+			}),
+			testIngress("b", "example.com", "/public", networkingv1.PathTypePrefix, nil),
+		},
+		expectedFilters: map[string][]gatewayv1.HTTPRouteFilter{"/public": nil},
+		expectedNotifications: []string{
+			`[high risk] nginx.ingress.kubernetes.io/auth-snippet "proxy_set_header X-Auth yes;" is left out: the requests to the authentication service cannot be customized`,
+			`the paths "/" are left out of HTTPRoute default/a-example-com, as the access control of nginx.ingress.kubernetes.io/auth-snippet cannot be converted`,
+		},
+	}, {
+		name: "forbidden return",
+		ingresses: []networkingv1.Ingress{
+			testIngress("a", "example.com", "/", networkingv1.PathTypePrefix, map[string]string{
+				serverSnippetAnnotation: "return 403;",
+			}),
+		},
+		expectedNotifications: []string{
+			`[high risk] nginx.ingress.kubernetes.io/server-snippet "return 403;" is left out: only the redirects to a URL can be converted`,
+			`the paths "/" are left out of HTTPRoute default/a-example-com, as the access control of nginx.ingress.kubernetes.io/server-snippet cannot be converted, as "return 403;" of default/a, which applies to host "example.com", is left out of the conversion`,
+		},
+	}, {
+		name:  "server deny, acknowledged",
+		flags: map[string]string{AllowUnprotectedRoutesFlag: "true"},
+		ingresses: []networkingv1.Ingress{
+			testIngress("a", "example.com", "/", networkingv1.PathTypePrefix, map[string]string{
+				serverSnippetAnnotation: "deny all;",
+			}),
+			testIngress("b", "example.com", "/api", networkingv1.PathTypePrefix, nil),
+		},
+		expectedFilters: map[string][]gatewayv1.HTTPRouteFilter{"/": nil, "/api": nil},
+		expectedNotifications: []string{
+			`the paths "/api" of HTTPRoute default/a-example-com are converted without the access control of nginx.ingress.kubernetes.io/server-snippet, as "deny all;" of default/a, which applies to host "example.com", is left out of the conversion`,
+		},
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			conf := &i2gw.ProviderConf{ProviderSpecificFlags: map[string]map[string]string{Name: tc.flags}}
+			gatewayResources, notificationTable := convertTestIngresses(t, conf, tc.ingresses...)
+
+			var filters map[string][]gatewayv1.HTTPRouteFilter
+			if httpRoute, ok := gatewayResources.HTTPRoutes[types.NamespacedName{Namespace: "default", Name: "a-example-com"}]; ok {
+				filters = map[string][]gatewayv1.HTTPRouteFilter{}
+				for _, rule := range httpRoute.Spec.Rules {
+					filters[*rule.Matches[0].Path.Value] = rule.Filters
+				}
+			}
+			if diff := cmp.Diff(tc.expectedFilters, filters); diff != "" {
+				t.Errorf("Unexpected filters, diff (-want +got):\n%s", diff)
+			}
+			for _, expected := range tc.expectedNotifications {
+				if !strings.Contains(notificationTable, expected) {
+					t.Errorf("Expected notification %q, got:\n%s", expected, notificationTable)
+				}
+			}
+			for message, expectedType := range tc.expectedTypes {
+				found := false
+				for _, line := range strings.Split(notificationTable, "\n") {
+					if strings.Contains(line, message) {
+						found = true
+						if !strings.HasPrefix(line, string(expectedType)+" ") {
+							t.Errorf("Expected notification %q to be of type %s, got: %s", message, expectedType, line)
+						}
+					}
+				}
+				if !found {
+					t.Errorf("Expected notification %q, got:\n%s", message, notificationTable)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,178 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingressnginx
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/providers/common"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/utils/ptr"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+	gatewayv1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
+)
+
+const sslPassthroughAnnotation = "nginx.ingress.kubernetes.io/ssl-passthrough"
+
+// sslPassthroughFeature converts the ssl-passthrough annotation.
+//
+// ingress-nginx forwards the TLS connections of a passthrough host, selected
+// by their SNI, to the backend of its root path without terminating them. The
+// HTTPS listener of the host is replaced by a TLS listener in Passthrough
+// mode, to which a TLSRoute of the host is attached. The HTTPRoute of the host
+// keeps serving plain HTTP requests, from the HTTP listener only.
+//
+// This must run before sslRedirect.planFeature, which redirects the HTTP
+// requests of passthrough hosts in place.
+func sslPassthroughFeature(ingresses []networkingv1.Ingress, gatewayResources *i2gw.GatewayResources) field.ErrorList {
+	var errs field.ErrorList
+	for _, rg := range sortedRuleGroups(ingresses) {
+		ingress, ok := sslPassthroughIngress(rg)
+		if !ok {
+			continue
+		}
+		if rg.Host == "" {
+			notifications.NotificationAggr.DispatchNotification(notifications.NewNotification(
+				notifications.WarningNotification,
+				fmt.Sprintf("%s is ignored for rules without host, as ingress-nginx selects passthrough connections by their SNI", sslPassthroughAnnotation),
+				&ingress,
+			), Name)
+			continue
+		}
+
+		backend, ok := sslPassthroughBackend(rg)
+		if !ok {
+			notifications.NotificationAggr.DispatchNotification(notifications.NewNotification(
+				notifications.ErrorNotification,
+				fmt.Sprintf("host %s uses %s without a root path or default backend to forward the TLS connections to, which must be configured manually with a TLSRoute", rg.Host, sslPassthroughAnnotation),
+				&ingress,
+			), Name)
+			continue
+		}
+		backendRef, err := common.ToBackendRef(backend, field.NewPath(ingress.Name, "spec", "rules", "http", "paths", "backend"))
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		gatewayKey := types.NamespacedName{Namespace: rg.Namespace, Name: rg.IngressClass}
+		gateway, ok := gatewayResources.Gateways[gatewayKey]
+		if !ok {
+			continue
+		}
+		listenerHostname := common.ListenerHostname(rg.Host, rg.TLS)
+		httpsListener := common.ListenerName(listenerHostname, "https")
+		tlsListener := common.ListenerName(listenerHostname, "tls")
+		var listeners []gatewayv1.Listener
+		for _, listener := range gateway.Spec.Listeners {
+			if listener.Name != httpsListener {
+				listeners = append(listeners, listener)
+			}
+		}
+		hostname := gatewayv1.Hostname(rg.Host)
+		listeners = append(listeners, gatewayv1.Listener{
+			Name:     tlsListener,
+			Hostname: &hostname,
+			Port:     443,
+			Protocol: gatewayv1.TLSProtocolType,
+			TLS:      &gatewayv1.GatewayTLSConfig{Mode: ptr.To(gatewayv1.TLSModePassthrough)},
+		})
+		gateway.Spec.Listeners = listeners
+		gatewayResources.Gateways[gatewayKey] = gateway
+
+		routeKey := types.NamespacedName{Namespace: rg.Namespace, Name: common.RouteName(rg.Name, rg.Host)}
+		tlsRoute := gatewayv1alpha2.TLSRoute{
+			ObjectMeta: metav1.ObjectMeta{Name: routeKey.Name, Namespace: routeKey.Namespace},
+			Spec: gatewayv1alpha2.TLSRouteSpec{
+				CommonRouteSpec: gatewayv1.CommonRouteSpec{
+					ParentRefs: []gatewayv1.ParentReference{{
+						Name:        gatewayv1.ObjectName(rg.IngressClass),
+						SectionName: ptr.To(tlsListener),
+					}},
+				},
+				Hostnames: []gatewayv1.Hostname{hostname},
+				Rules: []gatewayv1alpha2.TLSRouteRule{{
+					BackendRefs: []gatewayv1.BackendRef{*backendRef},
+				}},
+			},
+		}
+		tlsRoute.SetGroupVersionKind(common.TLSRouteGVK)
+		if gatewayResources.TLSRoutes == nil {
+			gatewayResources.TLSRoutes = map[types.NamespacedName]gatewayv1alpha2.TLSRoute{}
+		}
+		gatewayResources.TLSRoutes[routeKey] = tlsRoute
+
+		if httpRoute, ok := gatewayResources.HTTPRoutes[routeKey]; ok {
+			for i := range httpRoute.Spec.ParentRefs {
+				httpRoute.Spec.ParentRefs[i].SectionName = ptr.To(common.ListenerName(listenerHostname, "http"))
+			}
+			gatewayResources.HTTPRoutes[routeKey] = httpRoute
+		}
+
+		message := fmt.Sprintf("TLS connections to host %s are passed through to backend %s by TLSRoute %s, attached to listener %s, and the other annotations only apply to plain HTTP requests, served by HTTPRoute %s",
+			rg.Host, backendRef.Name, routeKey, tlsListener, routeKey)
+		if len(rg.TLS) > 0 {
+			message += ": the certificates of the tls section are not used"
+		}
+		notifications.NotificationAggr.DispatchNotification(notifications.NewNotification(
+			notifications.InfoNotification,
+			message,
+			&ingress,
+		), Name)
+	}
+
+	return errs
+}
+
+// sslPassthroughIngress returns the Ingress of the rule group enabling
+// ssl-passthrough, which ingress-nginx applies to the whole host.
+func sslPassthroughIngress(rg common.IngressRuleGroup) (networkingv1.Ingress, bool) {
+	for _, rule := range rg.Rules {
+		if passthrough, err := strconv.ParseBool(rule.Ingress.Annotations[sslPassthroughAnnotation]); err == nil && passthrough {
+			return rule.Ingress, true
+		}
+	}
+	return networkingv1.Ingress{}, false
+}
+
+// sslPassthroughBackend returns the backend of the root path of the rule group,
+// which ingress-nginx forwards passthrough connections to, falling back to the
+// default backend of its Ingresses.
+func sslPassthroughBackend(rg common.IngressRuleGroup) (networkingv1.IngressBackend, bool) {
+	for _, rule := range rg.Rules {
+		if rule.IngressRule.HTTP == nil || rule.Ingress.Annotations[canaryAnnotation] == "true" {
+			continue
+		}
+		for _, path := range rule.IngressRule.HTTP.Paths {
+			if path.Path == "/" {
+				return path.Backend, true
+			}
+		}
+	}
+	for _, rule := range rg.Rules {
+		if rule.Ingress.Spec.DefaultBackend != nil && rule.Ingress.Annotations[canaryAnnotation] != "true" {
+			return *rule.Ingress.Spec.DefaultBackend, true
+		}
+	}
+	return networkingv1.IngressBackend{}, false
+}
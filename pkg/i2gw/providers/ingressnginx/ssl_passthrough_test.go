@@ -0,0 +1,155 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingressnginx
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/types"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+	gatewayv1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
+)
+
+func Test_sslPassthroughFeature(t *testing.T) {
+	withTLS := func(ingress networkingv1.Ingress) networkingv1.Ingress {
+		ingress.Spec.TLS = []networkingv1.IngressTLS{{Hosts: []string{"example.com"}, SecretName: "example-com-tls"}}
+		return ingress
+	}
+	passthroughListener := gatewayv1.Listener{
+		Name:     "example-com-tls",
+		Hostname: ptrTo(gatewayv1.Hostname("example.com")),
+		Port:     443,
+		Protocol: gatewayv1.TLSProtocolType,
+		TLS:      &gatewayv1.GatewayTLSConfig{Mode: ptrTo(gatewayv1.TLSModePassthrough)},
+	}
+	httpListener := gatewayv1.Listener{
+		Name:     "example-com-http",
+		Hostname: ptrTo(gatewayv1.Hostname("example.com")),
+		Port:     80,
+		Protocol: gatewayv1.HTTPProtocolType,
+	}
+	passthroughRoute := gatewayv1alpha2.TLSRouteSpec{
+		CommonRouteSpec: gatewayv1.CommonRouteSpec{
+			ParentRefs: []gatewayv1.ParentReference{{Name: "ingress-nginx", SectionName: ptrTo(gatewayv1.SectionName("example-com-tls"))}},
+		},
+		Hostnames: []gatewayv1.Hostname{"example.com"},
+		Rules: []gatewayv1alpha2.TLSRouteRule{{
+			BackendRefs: []gatewayv1.BackendRef{{
+				BackendObjectReference: gatewayv1.BackendObjectReference{Name: "test", Port: ptrTo(gatewayv1.PortNumber(80))},
+			}},
+		}},
+	}
+
+	testCases := []struct {
+		name                  string
+		ingresses             []networkingv1.Ingress
+		expectedListeners     []gatewayv1.Listener
+		expectedTLSRoute      *gatewayv1alpha2.TLSRouteSpec
+		expectRedirect        bool
+		expectedNotifications []string
+	}{{
+		name: "passthrough host",
+		ingresses: []networkingv1.Ingress{
+			testIngress("a", "example.com", "/", networkingv1.PathTypePrefix, map[string]string{
+				sslPassthroughAnnotation: "true",
+			}),
+		},
+		expectedListeners: []gatewayv1.Listener{httpListener, passthroughListener},
+		expectedTLSRoute:  &passthroughRoute,
+	}, {
+		name: "passthrough host with TLS replaces the HTTPS listener and redirects HTTP",
+		ingresses: []networkingv1.Ingress{
+			withTLS(testIngress("a", "example.com", "/", networkingv1.PathTypePrefix, map[string]string{
+				sslPassthroughAnnotation: "true",
+			})),
+		},
+		expectedListeners:     []gatewayv1.Listener{httpListener, passthroughListener},
+		expectedTLSRoute:      &passthroughRoute,
+		expectRedirect:        true,
+		expectedNotifications: []string{"the certificates of the tls section are not used"},
+	}, {
+		name: "passthrough host without root path",
+		ingresses: []networkingv1.Ingress{
+			testIngress("a", "example.com", "/api", networkingv1.PathTypePrefix, map[string]string{
+				sslPassthroughAnnotation: "true",
+			}),
+		},
+		expectedListeners:     []gatewayv1.Listener{httpListener},
+		expectedNotifications: []string{"without a root path or default backend"},
+	}, {
+		name: "passthrough disabled",
+		ingresses: []networkingv1.Ingress{
+			withTLS(testIngress("a", "example.com", "/", networkingv1.PathTypePrefix, map[string]string{
+				sslPassthroughAnnotation: "false",
+			})),
+		},
+		expectedListeners: []gatewayv1.Listener{httpListener, {
+			Name:     "example-com-https",
+			Hostname: ptrTo(gatewayv1.Hostname("example.com")),
+			Port:     443,
+			Protocol: gatewayv1.HTTPSProtocolType,
+			TLS: &gatewayv1.GatewayTLSConfig{
+				CertificateRefs: []gatewayv1.SecretObjectReference{{Name: "example-com-tls"}},
+			},
+		}},
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			gatewayResources, notificationTable := convertTestIngresses(t, &i2gw.ProviderConf{}, tc.ingresses...)
+
+			gateway := gatewayResources.Gateways[types.NamespacedName{Namespace: "default", Name: "ingress-nginx"}]
+			if diff := cmp.Diff(tc.expectedListeners, gateway.Spec.Listeners); diff != "" {
+				t.Errorf("Unexpected listeners, diff (-want +got):\n%s", diff)
+			}
+
+			key := types.NamespacedName{Namespace: "default", Name: "a-example-com"}
+			tlsRoute, ok := gatewayResources.TLSRoutes[key]
+			if tc.expectedTLSRoute == nil {
+				if ok {
+					t.Errorf("Expected no TLSRoute %s, got %+v", key, tlsRoute)
+				}
+			} else {
+				if diff := cmp.Diff(*tc.expectedTLSRoute, tlsRoute.Spec); diff != "" {
+					t.Errorf("Unexpected TLSRoute, diff (-want +got):\n%s", diff)
+				}
+				httpRoute := gatewayResources.HTTPRoutes[key]
+				if diff := cmp.Diff(ptrTo(gatewayv1.SectionName("example-com-http")), httpRoute.Spec.ParentRefs[0].SectionName); diff != "" {
+					t.Errorf("Unexpected HTTPRoute section name, diff (-want +got):\n%s", diff)
+				}
+				redirected := len(httpRoute.Spec.Rules) == 1 && len(httpRoute.Spec.Rules[0].Filters) == 1 &&
+					httpRoute.Spec.Rules[0].Filters[0].Type == gatewayv1.HTTPRouteFilterRequestRedirect
+				if redirected != tc.expectRedirect {
+					t.Errorf("Expected redirect %t, got rules %+v", tc.expectRedirect, httpRoute.Spec.Rules)
+				}
+				if _, ok := gatewayResources.HTTPRoutes[types.NamespacedName{Namespace: "default", Name: "a-example-com-http"}]; ok {
+					t.Errorf("Expected no redirect copy of HTTPRoute %s", key)
+				}
+			}
+
+			for _, expected := range tc.expectedNotifications {
+				if !strings.Contains(notificationTable, expected) {
+					t.Errorf("Expected notification %q, got:\n%s", expected, notificationTable)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,305 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingressnginx
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/providers/common"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+const (
+	sslRedirectAnnotation      = "nginx.ingress.kubernetes.io/ssl-redirect"
+	forceSSLRedirectAnnotation = "nginx.ingress.kubernetes.io/force-ssl-redirect"
+
+	// DefaultSSLRedirectFlag is the provider-specific flag mirroring the
+	// ssl-redirect setting of the controller, which applies to the Ingresses
+	// not setting the ssl-redirect annotation.
+	DefaultSSLRedirectFlag = "default-ssl-redirect"
+
+	// sslRedirectCode is the status code of the generated redirects.
+	// ingress-nginx redirects with 308 by default, which the v1 HTTPRoute API
+	// does not accept, so the closest permanent redirect is used instead.
+	sslRedirectCode = 301
+)
+
+// sslRedirect converts the ssl-redirect and force-ssl-redirect annotations.
+//
+// Serving HTTP and redirecting it to HTTPS can only be told apart by the
+// listener a route is attached to, so the HTTPRoute of a redirected host is
+// attached to its HTTPS listener, and a copy attached to its HTTP listener
+// redirects the rules of the redirected Ingresses instead of forwarding them.
+//
+// The rules to redirect are found by planFeature, which must run before the
+// path matches are changed by other features, and the copies are made by
+// redirectFeature, which must run last so that they include every other
+// feature. The HTTPRoutes of passthrough hosts, which are only attached to
+// their HTTP listener, are redirected in place instead, and the HTTPRoutes of
+// hosts without an HTTPS listener are left as they are.
+type sslRedirect struct {
+	defaultSSLRedirect string
+	// config is the controller configuration, whose ssl-redirect setting
+	// takes precedence over defaultSSLRedirect.
+	config *controllerConfig
+	routes map[types.NamespacedName]sslRedirectRoute
+}
+
+// sslRedirectRoute is the plan of the HTTPRoute of a redirected host.
+type sslRedirectRoute struct {
+	httpListener  gatewayv1.SectionName
+	httpsListener gatewayv1.SectionName
+	// passthrough is true for the hosts converted by sslPassthroughFeature,
+	// whose HTTPRoute is redirected in place.
+	passthrough     bool
+	redirectedRules map[int]bool
+	ingresses       []networkingv1.Ingress
+}
+
+func newSSLRedirect(conf *i2gw.ProviderConf, config *controllerConfig) *sslRedirect {
+	return &sslRedirect{
+		defaultSSLRedirect: conf.ProviderSpecificFlags[Name][DefaultSSLRedirectFlag],
+		config:             config,
+	}
+}
+
+func (s *sslRedirect) planFeature(ingresses []networkingv1.Ingress, gatewayResources *i2gw.GatewayResources) field.ErrorList {
+	s.routes = map[types.NamespacedName]sslRedirectRoute{}
+
+	sslRedirectByDefault := true
+	if s.defaultSSLRedirect != "" {
+		var err error
+		sslRedirectByDefault, err = strconv.ParseBool(s.defaultSSLRedirect)
+		if err != nil {
+			return field.ErrorList{field.Invalid(field.NewPath("ProviderSpecificFlags", Name, DefaultSSLRedirectFlag), s.defaultSSLRedirect, "must be true or false")}
+		}
+	}
+	if s.config.sslRedirect != nil {
+		sslRedirectByDefault = *s.config.sslRedirect
+	}
+
+	for _, rg := range sortedRuleGroups(ingresses) {
+		key := types.NamespacedName{Namespace: rg.Namespace, Name: common.RouteName(rg.Name, rg.Host)}
+		httpRoute, ok := gatewayResources.HTTPRoutes[key]
+		if !ok {
+			continue
+		}
+
+		hasTLS := hostHasTLS(rg)
+		// common.ToGateway generates the HTTPS listener of the host when
+		// any of its Ingresses has TLS, even for other hosts.
+		hasHTTPSListener := len(rg.TLS) > 0
+		hostname := common.ListenerHostname(rg.Host, rg.TLS)
+		_, passthrough := sslPassthroughIngress(rg)
+		passthrough = passthrough && rg.Host != ""
+		plan := sslRedirectRoute{
+			httpListener:    common.ListenerName(hostname, "http"),
+			httpsListener:   common.ListenerName(hostname, "https"),
+			passthrough:     passthrough,
+			redirectedRules: map[int]bool{},
+		}
+		if passthrough {
+			plan.httpsListener = common.ListenerName(hostname, "tls")
+			hasHTTPSListener = true
+		}
+		redirectedIngresses := map[types.NamespacedName]bool{}
+		sourcesByRule := ruleSourcesByIndex(rg, httpRoute)
+		for _, ruleIdx := range sortedRuleIndexes(sourcesByRule) {
+			// The rules converted by redirectFeature are answered by
+			// ingress-nginx before redirecting to HTTPS.
+			if hasRequestRedirect(httpRoute.Spec.Rules[ruleIdx]) {
+				continue
+			}
+			for _, source := range annotationSources(sourcesByRule[ruleIdx]) {
+				if !redirectsToHTTPS(source.ingress, hasTLS, sslRedirectByDefault) {
+					continue
+				}
+				plan.redirectedRules[ruleIdx] = true
+				ingressKey := types.NamespacedName{Namespace: source.ingress.Namespace, Name: source.ingress.Name}
+				if !redirectedIngresses[ingressKey] {
+					redirectedIngresses[ingressKey] = true
+					plan.ingresses = append(plan.ingresses, source.ingress)
+				}
+			}
+		}
+		if len(plan.redirectedRules) == 0 {
+			continue
+		}
+		sort.Slice(plan.ingresses, func(i, j int) bool { return plan.ingresses[i].Name < plan.ingresses[j].Name })
+		if !hasHTTPSListener {
+			// Attaching the HTTPRoute to a listener that does not exist
+			// would drop its traffic, and redirecting on the HTTP
+			// listener alone would loop when TLS is terminated in front
+			// of the Gateway.
+			notifyNoHTTPSListener(key, plan)
+			continue
+		}
+		s.routes[key] = plan
+	}
+
+	return nil
+}
+
+func (s *sslRedirect) redirectFeature(_ []networkingv1.Ingress, gatewayResources *i2gw.GatewayResources) field.ErrorList {
+	routeKeys := make([]types.NamespacedName, 0, len(s.routes))
+	for key := range s.routes {
+		routeKeys = append(routeKeys, key)
+	}
+	sort.Slice(routeKeys, func(i, j int) bool { return routeKeys[i].String() < routeKeys[j].String() })
+
+	for _, key := range routeKeys {
+		httpRoute, ok := gatewayResources.HTTPRoutes[key]
+		if !ok {
+			continue
+		}
+		plan := s.routes[key]
+
+		redirectRoute := *httpRoute.DeepCopy()
+		if !plan.passthrough {
+			redirectRoute.Name = fmt.Sprintf("%s-http", httpRoute.Name)
+		}
+		for i := range redirectRoute.Spec.ParentRefs {
+			redirectRoute.Spec.ParentRefs[i].SectionName = ptr.To(plan.httpListener)
+		}
+		for i := range redirectRoute.Spec.Rules {
+			if !plan.redirectedRules[i] {
+				continue
+			}
+			redirectRoute.Spec.Rules[i] = gatewayv1.HTTPRouteRule{
+				Matches: redirectRoute.Spec.Rules[i].Matches,
+				Filters: []gatewayv1.HTTPRouteFilter{{
+					Type: gatewayv1.HTTPRouteFilterRequestRedirect,
+					RequestRedirect: &gatewayv1.HTTPRequestRedirectFilter{
+						Scheme:     ptr.To("https"),
+						StatusCode: ptr.To(sslRedirectCode),
+					},
+				}},
+			}
+		}
+		redirectKey := types.NamespacedName{Namespace: redirectRoute.Namespace, Name: redirectRoute.Name}
+		gatewayResources.HTTPRoutes[redirectKey] = redirectRoute
+
+		if !plan.passthrough {
+			for i := range httpRoute.Spec.ParentRefs {
+				httpRoute.Spec.ParentRefs[i].SectionName = ptr.To(plan.httpsListener)
+			}
+			gatewayResources.HTTPRoutes[key] = httpRoute
+		}
+
+		notifySSLRedirect(key, redirectKey, plan)
+	}
+
+	return nil
+}
+
+// redirectRouteKey returns the HTTPRoute attached to the HTTP listener for
+// the redirected HTTPRoute, which is the HTTPRoute itself when redirected in
+// place, and false if it is not redirected.
+func (s *sslRedirect) redirectRouteKey(key types.NamespacedName) (types.NamespacedName, bool) {
+	plan, ok := s.routes[key]
+	if !ok {
+		return types.NamespacedName{}, false
+	}
+	if plan.passthrough {
+		return key, true
+	}
+	return types.NamespacedName{Namespace: key.Namespace, Name: fmt.Sprintf("%s-http", key.Name)}, true
+}
+
+// redirectsToHTTPS returns whether ingress-nginx redirects the HTTP requests
+// of the Ingress to HTTPS: always with force-ssl-redirect, and unless opted
+// out with ssl-redirect when the host has TLS.
+func redirectsToHTTPS(ingress networkingv1.Ingress, hasTLS, sslRedirectByDefault bool) bool {
+	if force, err := strconv.ParseBool(ingress.Annotations[forceSSLRedirectAnnotation]); err == nil && force {
+		return true
+	}
+	if !hasTLS {
+		return false
+	}
+	if sslRedirect, err := strconv.ParseBool(ingress.Annotations[sslRedirectAnnotation]); err == nil {
+		return sslRedirect
+	}
+	return sslRedirectByDefault
+}
+
+// hostHasTLS returns whether a TLS entry of the rule group covers its host,
+// either by name or by wildcard. Entries without hosts cover every host, and
+// hosts left empty are covered by any entry.
+func hostHasTLS(rg common.IngressRuleGroup) bool {
+	for _, tls := range rg.TLS {
+		if rg.Host == "" || len(tls.Hosts) == 0 {
+			return true
+		}
+		for _, host := range tls.Hosts {
+			if strings.EqualFold(host, rg.Host) {
+				return true
+			}
+			if suffix, ok := strings.CutPrefix(host, "*."); ok {
+				if _, domain, found := strings.Cut(rg.Host, "."); found && strings.EqualFold(domain, suffix) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+func notifySSLRedirect(routeKey, redirectKey types.NamespacedName, plan sslRedirectRoute) {
+	var callingObjects []client.Object
+	var names []string
+	for i := range plan.ingresses {
+		callingObjects = append(callingObjects, &plan.ingresses[i])
+		names = append(names, fmt.Sprintf("%s/%s", plan.ingresses[i].Namespace, plan.ingresses[i].Name))
+	}
+
+	message := fmt.Sprintf("HTTP requests to the paths of %s are redirected to HTTPS by HTTPRoute %s, with status code %d instead of the 308 of ingress-nginx",
+		strings.Join(names, ", "), redirectKey, sslRedirectCode)
+	if !plan.passthrough {
+		message += fmt.Sprintf(", and HTTPRoute %s is attached to listener %s", routeKey, plan.httpsListener)
+	}
+	notifications.NotificationAggr.DispatchNotification(notifications.NewNotification(
+		notifications.InfoNotification,
+		message,
+		callingObjects...,
+	), Name)
+}
+
+func notifyNoHTTPSListener(routeKey types.NamespacedName, plan sslRedirectRoute) {
+	var callingObjects []client.Object
+	var names []string
+	for i := range plan.ingresses {
+		callingObjects = append(callingObjects, &plan.ingresses[i])
+		names = append(names, fmt.Sprintf("%s/%s", plan.ingresses[i].Namespace, plan.ingresses[i].Name))
+	}
+
+	notifications.NotificationAggr.DispatchNotification(notifications.NewNotification(
+		notifications.WarningNotification,
+		fmt.Sprintf("%s is set on %s without TLS: the Gateway has no HTTPS listener for HTTPRoute %s, which keeps serving the HTTP requests without redirecting them. Add an HTTPS listener %s, or redirect the requests where TLS is terminated in front of the Gateway",
+			forceSSLRedirectAnnotation, strings.Join(names, ", "), routeKey, plan.httpsListener),
+		callingObjects...,
+	), Name)
+}
@@ -0,0 +1,200 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingressnginx
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/types"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+func Test_sslRedirectFeature(t *testing.T) {
+	withTLS := func(ingress networkingv1.Ingress) networkingv1.Ingress {
+		ingress.Spec.TLS = []networkingv1.IngressTLS{{Hosts: []string{"example.com"}, SecretName: "example-com-tls"}}
+		return ingress
+	}
+	redirectRule := func(path string) gatewayv1.HTTPRouteRule {
+		return gatewayv1.HTTPRouteRule{
+			Matches: []gatewayv1.HTTPRouteMatch{{
+				Path: &gatewayv1.HTTPPathMatch{Type: ptrTo(gatewayv1.PathMatchPathPrefix), Value: ptrTo(path)},
+			}},
+			Filters: []gatewayv1.HTTPRouteFilter{{
+				Type: gatewayv1.HTTPRouteFilterRequestRedirect,
+				RequestRedirect: &gatewayv1.HTTPRequestRedirectFilter{
+					Scheme:     ptrTo("https"),
+					StatusCode: ptrTo(301),
+				},
+			}},
+		}
+	}
+	backendRule := func(path string) gatewayv1.HTTPRouteRule {
+		return gatewayv1.HTTPRouteRule{
+			Matches: []gatewayv1.HTTPRouteMatch{{
+				Path: &gatewayv1.HTTPPathMatch{Type: ptrTo(gatewayv1.PathMatchPathPrefix), Value: ptrTo(path)},
+			}},
+			BackendRefs: []gatewayv1.HTTPBackendRef{{
+				BackendRef: gatewayv1.BackendRef{
+					BackendObjectReference: gatewayv1.BackendObjectReference{Name: "test", Port: ptrTo(gatewayv1.PortNumber(80))},
+				},
+			}},
+		}
+	}
+
+	testCases := []struct {
+		name                  string
+		defaultSSLRedirect    string
+		ingresses             []networkingv1.Ingress
+		expectedSectionName   *gatewayv1.SectionName
+		expectedRedirectRules []gatewayv1.HTTPRouteRule
+		expectedNotification  string
+	}{{
+		name: "TLS host is redirected by default",
+		ingresses: []networkingv1.Ingress{
+			withTLS(testIngress("a", "example.com", "/", networkingv1.PathTypePrefix, nil)),
+		},
+		expectedSectionName:   ptrTo(gatewayv1.SectionName("example-com-https")),
+		expectedRedirectRules: []gatewayv1.HTTPRouteRule{redirectRule("/")},
+		expectedNotification:  "redirected to HTTPS by HTTPRoute default/a-example-com-http",
+	}, {
+		name: "ssl-redirect opt-out",
+		ingresses: []networkingv1.Ingress{
+			withTLS(testIngress("a", "example.com", "/", networkingv1.PathTypePrefix, map[string]string{
+				sslRedirectAnnotation: "false",
+			})),
+		},
+	}, {
+		name:               "ssl-redirect disabled by the controller setting",
+		defaultSSLRedirect: "false",
+		ingresses: []networkingv1.Ingress{
+			withTLS(testIngress("a", "example.com", "/", networkingv1.PathTypePrefix, nil)),
+		},
+	}, {
+		name:               "ssl-redirect annotation overrides the controller setting",
+		defaultSSLRedirect: "false",
+		ingresses: []networkingv1.Ingress{
+			withTLS(testIngress("a", "example.com", "/", networkingv1.PathTypePrefix, map[string]string{
+				sslRedirectAnnotation: "true",
+			})),
+		},
+		expectedSectionName:   ptrTo(gatewayv1.SectionName("example-com-https")),
+		expectedRedirectRules: []gatewayv1.HTTPRouteRule{redirectRule("/")},
+	}, {
+		name: "host without TLS is not redirected",
+		ingresses: []networkingv1.Ingress{
+			testIngress("a", "example.com", "/", networkingv1.PathTypePrefix, nil),
+		},
+	}, {
+		name: "force-ssl-redirect without TLS",
+		ingresses: []networkingv1.Ingress{
+			testIngress("a", "example.com", "/", networkingv1.PathTypePrefix, map[string]string{
+				forceSSLRedirectAnnotation: "true",
+			}),
+		},
+		expectedNotification: "which keeps serving the HTTP requests without redirecting them. Add an HTTPS listener example-com-https",
+	}, {
+		name: "TLS of another host",
+		ingresses: []networkingv1.Ingress{
+			func() networkingv1.Ingress {
+				ingress := testIngress("a", "example.com", "/", networkingv1.PathTypePrefix, nil)
+				ingress.Spec.TLS = []networkingv1.IngressTLS{{Hosts: []string{"other.example.com"}, SecretName: "other-tls"}}
+				return ingress
+			}(),
+		},
+	}, {
+		name: "wildcard TLS host is redirected",
+		ingresses: []networkingv1.Ingress{
+			func() networkingv1.Ingress {
+				ingress := testIngress("a", "example.com", "/", networkingv1.PathTypePrefix, nil)
+				ingress.Spec.TLS = []networkingv1.IngressTLS{{Hosts: []string{"*.com"}, SecretName: "wildcard-tls"}}
+				return ingress
+			}(),
+		},
+		expectedSectionName:   ptrTo(gatewayv1.SectionName("example-com-https")),
+		expectedRedirectRules: []gatewayv1.HTTPRouteRule{redirectRule("/")},
+	}, {
+		name: "opted out paths are still served over HTTP",
+		ingresses: []networkingv1.Ingress{
+			withTLS(testIngress("a", "example.com", "/secure", networkingv1.PathTypePrefix, nil)),
+			testIngress("b", "example.com", "/public", networkingv1.PathTypePrefix, map[string]string{
+				sslRedirectAnnotation: "false",
+			}),
+		},
+		expectedSectionName:   ptrTo(gatewayv1.SectionName("example-com-https")),
+		expectedRedirectRules: []gatewayv1.HTTPRouteRule{redirectRule("/secure"), backendRule("/public")},
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			conf := &i2gw.ProviderConf{
+				ProviderSpecificFlags: map[string]map[string]string{
+					Name: {DefaultSSLRedirectFlag: tc.defaultSSLRedirect},
+				},
+			}
+			gatewayResources, notificationTable := convertTestIngresses(t, conf, tc.ingresses...)
+
+			key := types.NamespacedName{Namespace: "default", Name: "a-example-com"}
+			httpRoute, ok := gatewayResources.HTTPRoutes[key]
+			if !ok {
+				t.Fatalf("Expected HTTPRoute %s, got %+v", key, gatewayResources.HTTPRoutes)
+			}
+			if diff := cmp.Diff(tc.expectedSectionName, httpRoute.Spec.ParentRefs[0].SectionName); diff != "" {
+				t.Errorf("Unexpected section name, diff (-want +got):\n%s", diff)
+			}
+
+			redirectKey := types.NamespacedName{Namespace: "default", Name: "a-example-com-http"}
+			redirectRoute, ok := gatewayResources.HTTPRoutes[redirectKey]
+			if tc.expectedRedirectRules == nil {
+				if ok {
+					t.Fatalf("Expected no HTTPRoute %s, got %+v", redirectKey, redirectRoute)
+				}
+				return
+			}
+			if !ok {
+				t.Fatalf("Expected HTTPRoute %s, got %+v", redirectKey, gatewayResources.HTTPRoutes)
+			}
+			if diff := cmp.Diff(ptrTo(gatewayv1.SectionName("example-com-http")), redirectRoute.Spec.ParentRefs[0].SectionName); diff != "" {
+				t.Errorf("Unexpected redirect section name, diff (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.expectedRedirectRules, redirectRoute.Spec.Rules); diff != "" {
+				t.Errorf("Unexpected redirect rules, diff (-want +got):\n%s", diff)
+			}
+			if tc.expectedNotification != "" && !strings.Contains(notificationTable, tc.expectedNotification) {
+				t.Errorf("Expected notification %q, got:\n%s", tc.expectedNotification, notificationTable)
+			}
+		})
+	}
+}
+
+func Test_sslRedirectFeature_invalidDefault(t *testing.T) {
+	conf := &i2gw.ProviderConf{
+		ProviderSpecificFlags: map[string]map[string]string{
+			Name: {DefaultSSLRedirectFlag: "maybe"},
+		},
+	}
+	provider := NewProvider(conf).(*Provider)
+	ingress := testIngress("a", "example.com", "/", networkingv1.PathTypePrefix, nil)
+	provider.storage.Ingresses.FromMap(map[types.NamespacedName]*networkingv1.Ingress{{Namespace: "default", Name: "a"}: &ingress})
+
+	if _, errs := provider.ToGatewayAPI(); len(errs) != 1 {
+		t.Errorf("Expected one error, got %+v", errs)
+	}
+}
@@ -19,6 +19,7 @@ package ingressnginx
 import (
 	"sort"
 
+	corev1 "k8s.io/api/core/v1"
 	networkingv1 "k8s.io/api/networking/v1"
 	"k8s.io/apimachinery/pkg/types"
 )
@@ -29,6 +30,26 @@ type OrderedIngressMap struct {
 }
 type storage struct {
 	Ingresses OrderedIngressMap
+	// Services are the Services of the Ingress backends, looked up to check
+	// their appProtocol against the backend protocol of the Ingresses, and
+	// the Services of the tcp-services and udp-services entries, looked up to
+	// resolve their named ports.
+	Services map[types.NamespacedName]*corev1.Service
+	// ControllerService is the Service of the ingress controller, nil unless
+	// given with the controller-service flag.
+	ControllerService *corev1.Service
+	// TCPServices and UDPServices are the tcp-services and udp-services
+	// ConfigMaps, nil unless given with their flag.
+	TCPServices *corev1.ConfigMap
+	UDPServices *corev1.ConfigMap
+	// ControllerConfigMap is the ConfigMap of the controller, nil unless given
+	// with its flag, and ProxySetHeaders the ConfigMap of its
+	// proxy-set-headers setting, nil unless set.
+	ControllerConfigMap *corev1.ConfigMap
+	ProxySetHeaders     *corev1.ConfigMap
+	// ConfigMaps are the ConfigMaps referenced by the annotations of the
+	// Ingresses which are found.
+	ConfigMaps map[types.NamespacedName]*corev1.ConfigMap
 }
 
 func newResourcesStorage() *storage {
@@ -37,6 +58,8 @@ func newResourcesStorage() *storage {
 			ingressNames:   []types.NamespacedName{},
 			ingressObjects: map[types.NamespacedName]*networkingv1.Ingress{},
 		},
+		Services:   map[types.NamespacedName]*corev1.Service{},
+		ConfigMaps: map[types.NamespacedName]*corev1.ConfigMap{},
 	}
 }
 
@@ -0,0 +1,242 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingressnginx
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/providers/common"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/utils/ptr"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+	gatewayv1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
+)
+
+const (
+	// TCPServicesConfigMapFlag is the provider-specific flag mirroring the
+	// --tcp-services-configmap flag of the controller, as namespace/name.
+	TCPServicesConfigMapFlag = "tcp-services-configmap"
+
+	// UDPServicesConfigMapFlag is the provider-specific flag mirroring the
+	// --udp-services-configmap flag of the controller, as namespace/name.
+	UDPServicesConfigMapFlag = "udp-services-configmap"
+
+	proxyProtocolField = "PROXY"
+)
+
+// streamService is an entry of the tcp-services or udp-services ConfigMap,
+// exposing a Service port on a port of the controller.
+type streamService struct {
+	port    int32
+	service types.NamespacedName
+	// servicePort is the number or name of the Service port.
+	servicePort string
+	// proxyProtocol are the PROXY fields of the entry, decoding and encoding
+	// the PROXY protocol.
+	proxyProtocol []string
+}
+
+// streamServicesFeature returns a FeatureParser converting the entries of the
+// tcp-services and udp-services ConfigMaps, either of which may be nil, to TCP
+// and UDP listeners, with a TCPRoute or UDPRoute forwarding the connections
+// to the Service. The listeners are added to the Gateway of the namespace of
+// the Service, so that the routes do not need a ReferenceGrant.
+func streamServicesFeature(tcpServices, udpServices *corev1.ConfigMap, services map[types.NamespacedName]*corev1.Service) i2gw.FeatureParser {
+	return func(_ []networkingv1.Ingress, gatewayResources *i2gw.GatewayResources) field.ErrorList {
+		var errs field.ErrorList
+		if tcpServices != nil {
+			errs = append(errs, convertStreamServices(tcpServices, gatewayv1.TCPProtocolType, services, gatewayResources)...)
+		}
+		if udpServices != nil {
+			errs = append(errs, convertStreamServices(udpServices, gatewayv1.UDPProtocolType, services, gatewayResources)...)
+		}
+		return errs
+	}
+}
+
+func convertStreamServices(configMap *corev1.ConfigMap, protocol gatewayv1.ProtocolType, services map[types.NamespacedName]*corev1.Service, gatewayResources *i2gw.GatewayResources) field.ErrorList {
+	var errs field.ErrorList
+	fieldPath := field.NewPath(fmt.Sprintf("%s/%s", configMap.Namespace, configMap.Name), "data")
+	protocolName := strings.ToLower(string(protocol))
+
+	ports := make([]string, 0, len(configMap.Data))
+	for port := range configMap.Data {
+		ports = append(ports, port)
+	}
+	sort.Strings(ports)
+
+	for _, port := range ports {
+		entry, err := parseStreamService(port, configMap.Data[port])
+		if err != nil {
+			errs = append(errs, field.Invalid(fieldPath.Key(port), configMap.Data[port], err.Error()))
+			continue
+		}
+		backendPort, err := resolveServicePort(entry, services)
+		if err != nil {
+			errs = append(errs, field.Invalid(fieldPath.Key(port), configMap.Data[port], err.Error()))
+			continue
+		}
+
+		listenerName := gatewayv1.SectionName(fmt.Sprintf("%s-%d", protocolName, entry.port))
+		gatewayKey := types.NamespacedName{Namespace: entry.service.Namespace, Name: NginxIngressClass}
+		gateway, ok := gatewayResources.Gateways[gatewayKey]
+		if !ok {
+			gateway = gatewayv1.Gateway{
+				ObjectMeta: metav1.ObjectMeta{Namespace: gatewayKey.Namespace, Name: gatewayKey.Name},
+				Spec:       gatewayv1.GatewaySpec{GatewayClassName: NginxIngressClass},
+			}
+			gateway.SetGroupVersionKind(common.GatewayGVK)
+		}
+		if conflict, ok := conflictingListener(gateway, entry.port, protocol); ok {
+			notifications.NotificationAggr.DispatchNotification(notifications.NewNotification(
+				notifications.ErrorNotification,
+				fmt.Sprintf("port %s of ConfigMap %s/%s cannot be converted, as it is already used by the %s listener %s of Gateway %s: expose %s with another port",
+					port, configMap.Namespace, configMap.Name, conflict.Protocol, conflict.Name, gatewayKey, entry.service),
+				configMap,
+			), Name)
+			continue
+		}
+		gateway.Spec.Listeners = append(gateway.Spec.Listeners, gatewayv1.Listener{
+			Name:     listenerName,
+			Port:     gatewayv1.PortNumber(entry.port),
+			Protocol: protocol,
+		})
+		gatewayResources.Gateways[gatewayKey] = gateway
+
+		commonRouteSpec := gatewayv1.CommonRouteSpec{
+			ParentRefs: []gatewayv1.ParentReference{{
+				Name:        gatewayv1.ObjectName(gatewayKey.Name),
+				SectionName: ptr.To(listenerName),
+			}},
+		}
+		backendRefs := []gatewayv1.BackendRef{{
+			BackendObjectReference: gatewayv1.BackendObjectReference{
+				Name: gatewayv1.ObjectName(entry.service.Name),
+				Port: ptr.To(gatewayv1.PortNumber(backendPort)),
+			},
+		}}
+		routeKey := types.NamespacedName{Namespace: entry.service.Namespace, Name: fmt.Sprintf("%s-%s-%d", entry.service.Name, protocolName, entry.port)}
+		routeMeta := metav1.ObjectMeta{Namespace: routeKey.Namespace, Name: routeKey.Name}
+		if protocol == gatewayv1.TCPProtocolType {
+			tcpRoute := gatewayv1alpha2.TCPRoute{
+				ObjectMeta: routeMeta,
+				Spec: gatewayv1alpha2.TCPRouteSpec{
+					CommonRouteSpec: commonRouteSpec,
+					Rules:           []gatewayv1alpha2.TCPRouteRule{{BackendRefs: backendRefs}},
+				},
+			}
+			tcpRoute.SetGroupVersionKind(common.TCPRouteGVK)
+			if gatewayResources.TCPRoutes == nil {
+				gatewayResources.TCPRoutes = map[types.NamespacedName]gatewayv1alpha2.TCPRoute{}
+			}
+			gatewayResources.TCPRoutes[routeKey] = tcpRoute
+		} else {
+			udpRoute := gatewayv1alpha2.UDPRoute{
+				ObjectMeta: routeMeta,
+				Spec: gatewayv1alpha2.UDPRouteSpec{
+					CommonRouteSpec: commonRouteSpec,
+					Rules:           []gatewayv1alpha2.UDPRouteRule{{BackendRefs: backendRefs}},
+				},
+			}
+			udpRoute.SetGroupVersionKind(common.UDPRouteGVK)
+			if gatewayResources.UDPRoutes == nil {
+				gatewayResources.UDPRoutes = map[types.NamespacedName]gatewayv1alpha2.UDPRoute{}
+			}
+			gatewayResources.UDPRoutes[routeKey] = udpRoute
+		}
+
+		if len(entry.proxyProtocol) > 0 {
+			notifications.NotificationAggr.DispatchNotification(notifications.NewNotification(
+				notifications.WarningNotification,
+				fmt.Sprintf("port %s of ConfigMap %s/%s uses the PROXY protocol, which has no Gateway API equivalent and must be configured with the Gateway implementation", port, configMap.Namespace, configMap.Name),
+				configMap,
+			), Name)
+		}
+	}
+
+	return errs
+}
+
+// conflictingListener returns the listener of the Gateway using the port with
+// the same transport protocol as protocol, UDP or TCP for the others.
+func conflictingListener(gateway gatewayv1.Gateway, port int32, protocol gatewayv1.ProtocolType) (gatewayv1.Listener, bool) {
+	isUDP := protocol == gatewayv1.UDPProtocolType
+	for _, listener := range gateway.Spec.Listeners {
+		if listener.Port == gatewayv1.PortNumber(port) && (listener.Protocol == gatewayv1.UDPProtocolType) == isUDP {
+			return listener, true
+		}
+	}
+	return gatewayv1.Listener{}, false
+}
+
+// parseStreamService parses an entry of the tcp-services or udp-services
+// ConfigMap, mapping a port to <namespace>/<service>:<port>, optionally
+// followed by :PROXY to decode, and :PROXY to encode the PROXY protocol.
+func parseStreamService(port, value string) (streamService, error) {
+	number, err := strconv.ParseInt(port, 10, 32)
+	if err != nil || number < 1 || number > 65535 {
+		return streamService{}, fmt.Errorf("key %q is not a valid port number", port)
+	}
+
+	fields := strings.Split(value, ":")
+	if len(fields) < 2 || len(fields) > 4 || fields[1] == "" {
+		return streamService{}, fmt.Errorf("must be in the <namespace>/<service>:<port>[:PROXY][:PROXY] format")
+	}
+	service, err := common.ParseNamespacedName(fields[0])
+	if err != nil {
+		return streamService{}, err
+	}
+	entry := streamService{
+		port:        int32(number),
+		service:     service,
+		servicePort: fields[1],
+	}
+	for _, proxy := range fields[2:] {
+		if proxy == proxyProtocolField {
+			entry.proxyProtocol = append(entry.proxyProtocol, proxy)
+		} else if proxy != "" {
+			return streamService{}, fmt.Errorf("unknown field %q, only %s is supported", proxy, proxyProtocolField)
+		}
+	}
+	return entry, nil
+}
+
+// resolveServicePort returns the number of the Service port of the entry,
+// looking up named ports in the Services.
+func resolveServicePort(entry streamService, services map[types.NamespacedName]*corev1.Service) (int32, error) {
+	if number, err := strconv.ParseInt(entry.servicePort, 10, 32); err == nil {
+		return int32(number), nil
+	}
+	service, ok := services[entry.service]
+	if !ok {
+		return 0, fmt.Errorf("named port %q of Service %s cannot be resolved, as the Service is not found", entry.servicePort, entry.service)
+	}
+	servicePort, ok := findServicePort(service, networkingv1.ServiceBackendPort{Name: entry.servicePort})
+	if !ok {
+		return 0, fmt.Errorf("service %s has no port named %q", entry.service, entry.servicePort)
+	}
+	return servicePort.Port, nil
+}
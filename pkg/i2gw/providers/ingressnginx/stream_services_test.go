@@ -0,0 +1,163 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingressnginx
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+func Test_streamServicesFeature(t *testing.T) {
+	configMap := func(name string, data map[string]string) *corev1.ConfigMap {
+		return &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ingress-nginx", Name: name},
+			Data:       data,
+		}
+	}
+	services := map[types.NamespacedName]*corev1.Service{
+		{Namespace: "dns", Name: "coredns"}: {
+			ObjectMeta: metav1.ObjectMeta{Namespace: "dns", Name: "coredns"},
+			Spec:       corev1.ServiceSpec{Ports: []corev1.ServicePort{{Name: "dns", Port: 53}}},
+		},
+	}
+
+	testCases := []struct {
+		name              string
+		gateways          map[types.NamespacedName]gatewayv1.Gateway
+		tcpServices       *corev1.ConfigMap
+		udpServices       *corev1.ConfigMap
+		expectedGateways  map[types.NamespacedName][]gatewayv1.Listener
+		expectedTCPRoutes []types.NamespacedName
+		expectedUDPRoutes []types.NamespacedName
+		expectedErrors    int
+	}{{
+		name: "tcp and udp services",
+		tcpServices: configMap("tcp-services", map[string]string{
+			"9000": "default/db:5432",
+			"9001": "default/cache:6379:PROXY",
+		}),
+		udpServices: configMap("udp-services", map[string]string{
+			"53": "dns/coredns:dns",
+		}),
+		expectedGateways: map[types.NamespacedName][]gatewayv1.Listener{
+			{Namespace: "default", Name: "nginx"}: {
+				{Name: "tcp-9000", Port: 9000, Protocol: gatewayv1.TCPProtocolType},
+				{Name: "tcp-9001", Port: 9001, Protocol: gatewayv1.TCPProtocolType},
+			},
+			{Namespace: "dns", Name: "nginx"}: {
+				{Name: "udp-53", Port: 53, Protocol: gatewayv1.UDPProtocolType},
+			},
+		},
+		expectedTCPRoutes: []types.NamespacedName{
+			{Namespace: "default", Name: "cache-tcp-9001"},
+			{Namespace: "default", Name: "db-tcp-9000"},
+		},
+		expectedUDPRoutes: []types.NamespacedName{
+			{Namespace: "dns", Name: "coredns-udp-53"},
+		},
+	}, {
+		name: "ports of the HTTP and HTTPS listeners",
+		gateways: map[types.NamespacedName]gatewayv1.Gateway{
+			{Namespace: "default", Name: "nginx"}: {
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "nginx"},
+				Spec: gatewayv1.GatewaySpec{Listeners: []gatewayv1.Listener{
+					{Name: "example-com-http", Port: 80, Protocol: gatewayv1.HTTPProtocolType},
+					{Name: "example-com-https", Port: 443, Protocol: gatewayv1.HTTPSProtocolType},
+				}},
+			},
+		},
+		tcpServices: configMap("tcp-services", map[string]string{
+			"443":  "default/db:5432",
+			"9000": "default/db:5432",
+		}),
+		udpServices: configMap("udp-services", map[string]string{
+			"443": "default/quic:443",
+		}),
+		expectedGateways: map[types.NamespacedName][]gatewayv1.Listener{
+			{Namespace: "default", Name: "nginx"}: {
+				{Name: "example-com-http", Port: 80, Protocol: gatewayv1.HTTPProtocolType},
+				{Name: "example-com-https", Port: 443, Protocol: gatewayv1.HTTPSProtocolType},
+				{Name: "tcp-9000", Port: 9000, Protocol: gatewayv1.TCPProtocolType},
+				{Name: "udp-443", Port: 443, Protocol: gatewayv1.UDPProtocolType},
+			},
+		},
+		expectedTCPRoutes: []types.NamespacedName{
+			{Namespace: "default", Name: "db-tcp-9000"},
+		},
+		expectedUDPRoutes: []types.NamespacedName{
+			{Namespace: "default", Name: "quic-udp-443"},
+		},
+	}, {
+		name: "invalid entries",
+		tcpServices: configMap("tcp-services", map[string]string{
+			"http":  "default/db:5432",
+			"9000":  "db:5432",
+			"9001":  "default/db:postgres",
+			"70000": "default/db:5432",
+		}),
+		expectedGateways: map[types.NamespacedName][]gatewayv1.Listener{},
+		expectedErrors:   4,
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			gatewayResources := i2gw.GatewayResources{
+				Gateways: map[types.NamespacedName]gatewayv1.Gateway{},
+			}
+			for key, gateway := range tc.gateways {
+				gatewayResources.Gateways[key] = gateway
+			}
+			errs := streamServicesFeature(tc.tcpServices, tc.udpServices, services)(nil, &gatewayResources)
+			if len(errs) != tc.expectedErrors {
+				t.Errorf("Expected %d errors, got %+v", tc.expectedErrors, errs)
+			}
+
+			listeners := map[types.NamespacedName][]gatewayv1.Listener{}
+			for key, gateway := range gatewayResources.Gateways {
+				listeners[key] = gateway.Spec.Listeners
+			}
+			if diff := cmp.Diff(tc.expectedGateways, listeners); diff != "" {
+				t.Errorf("Unexpected listeners, diff (-want +got):\n%s", diff)
+			}
+
+			var tcpRoutes, udpRoutes []types.NamespacedName
+			for key, route := range gatewayResources.TCPRoutes {
+				tcpRoutes = append(tcpRoutes, key)
+				if route.Spec.ParentRefs[0].SectionName == nil || len(route.Spec.Rules) != 1 {
+					t.Errorf("Unexpected TCPRoute %+v", route)
+				}
+			}
+			for key := range gatewayResources.UDPRoutes {
+				udpRoutes = append(udpRoutes, key)
+			}
+			sort.Slice(tcpRoutes, func(i, j int) bool { return tcpRoutes[i].String() < tcpRoutes[j].String() })
+			if diff := cmp.Diff(tc.expectedTCPRoutes, tcpRoutes); diff != "" {
+				t.Errorf("Unexpected TCPRoutes, diff (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.expectedUDPRoutes, udpRoutes); diff != "" {
+				t.Errorf("Unexpected UDPRoutes, diff (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,197 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingressnginx
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/providers/common"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+const (
+	proxyConnectTimeoutAnnotation = "nginx.ingress.kubernetes.io/proxy-connect-timeout"
+	proxyReadTimeoutAnnotation    = "nginx.ingress.kubernetes.io/proxy-read-timeout"
+	proxySendTimeoutAnnotation    = "nginx.ingress.kubernetes.io/proxy-send-timeout"
+)
+
+// proxyTimeouts are the proxy timeouts of an Ingress, in seconds, nil when
+// not set.
+type proxyTimeouts struct {
+	connect *int
+	read    *int
+	send    *int
+}
+
+// timeoutsFeature converts the proxy timeout annotations.
+//
+// proxy-read-timeout limits the time nginx waits for the backend to send the
+// response, which is converted to the request and backendRequest timeouts of
+// the rule. nginx only limits the time between two successive reads though,
+// while the converted timeouts are total deadlines, so responses streamed for
+// longer are cut, which is warned about. The proxy-read-timeout setting of the
+// controller ConfigMap applies to the Ingresses without the annotation, and is
+// converted likewise. proxy-connect-timeout and proxy-send-timeout have no
+// Gateway API equivalent and are reported.
+//
+// Like ingress-nginx, invalid annotations are ignored.
+func timeoutsFeature(config *controllerConfig) i2gw.FeatureParser {
+	return func(ingresses []networkingv1.Ingress, gatewayResources *i2gw.GatewayResources) field.ErrorList {
+		return convertTimeouts(ingresses, gatewayResources, config.proxyReadTimeout)
+	}
+}
+
+func convertTimeouts(ingresses []networkingv1.Ingress, gatewayResources *i2gw.GatewayResources, defaultRead *int) field.ErrorList {
+	timeoutsByIngress := map[types.NamespacedName]proxyTimeouts{}
+	var defaultedIngresses []client.Object
+	for i := range ingresses {
+		timeouts, parseErrs := parseProxyTimeouts(ingresses[i])
+		for _, err := range parseErrs {
+			notifications.NotificationAggr.DispatchNotification(notifications.NewNotification(
+				notifications.WarningNotification,
+				fmt.Sprintf("%s: the annotation is ignored, like ingress-nginx does", err.Error()),
+				&ingresses[i],
+			), Name)
+		}
+		notifyProxyTimeouts(&ingresses[i], timeouts)
+		if timeouts.read == nil && defaultRead != nil {
+			timeouts.read = defaultRead
+			defaultedIngresses = append(defaultedIngresses, &ingresses[i])
+		}
+		timeoutsByIngress[types.NamespacedName{Namespace: ingresses[i].Namespace, Name: ingresses[i].Name}] = timeouts
+	}
+	if len(defaultedIngresses) > 0 {
+		notifications.NotificationAggr.DispatchNotification(notifications.NewNotification(
+			notifications.WarningNotification,
+			readTimeoutMessage(fmt.Sprintf("the %s %ds setting of the controller ConfigMap", proxyReadTimeoutKey, *defaultRead), *defaultRead),
+			defaultedIngresses...,
+		), Name)
+	}
+
+	for _, rg := range sortedRuleGroups(ingresses) {
+		key := types.NamespacedName{Namespace: rg.Namespace, Name: common.RouteName(rg.Name, rg.Host)}
+		httpRoute, ok := gatewayResources.HTTPRoutes[key]
+		if !ok {
+			continue
+		}
+
+		sourcesByRule := ruleSourcesByIndex(rg, httpRoute)
+		for _, ruleIdx := range sortedRuleIndexes(sourcesByRule) {
+			sources := annotationSources(sourcesByRule[ruleIdx])
+			var read *int
+			var callingObjects []client.Object
+			sameRead := true
+			for i, source := range sources {
+				callingObjects = append(callingObjects, &sources[i].ingress)
+				sourceRead := timeoutsByIngress[types.NamespacedName{Namespace: source.ingress.Namespace, Name: source.ingress.Name}].read
+				if i == 0 {
+					read = sourceRead
+				} else if (read == nil) != (sourceRead == nil) || (read != nil && *read != *sourceRead) {
+					sameRead = false
+				}
+			}
+			if !sameRead {
+				notifications.NotificationAggr.DispatchNotification(notifications.NewNotification(
+					notifications.WarningNotification,
+					fmt.Sprintf("Ingresses sharing path %q set different %s annotations, the one of %s/%s is used", sources[0].path.Path, proxyReadTimeoutAnnotation, sources[0].ingress.Namespace, sources[0].ingress.Name),
+					callingObjects...,
+				), Name)
+			}
+			if read == nil {
+				continue
+			}
+
+			timeout := toDuration(*read)
+			httpRoute.Spec.Rules[ruleIdx].Timeouts = &gatewayv1.HTTPRouteTimeouts{
+				Request:        &timeout,
+				BackendRequest: &timeout,
+			}
+		}
+		gatewayResources.HTTPRoutes[key] = httpRoute
+	}
+
+	return nil
+}
+
+func parseProxyTimeouts(ingress networkingv1.Ingress) (proxyTimeouts, field.ErrorList) {
+	var errs field.ErrorList
+	fieldPath := field.NewPath(ingress.Name).Child("metadata").Child("annotations")
+
+	parse := func(annotation string) *int {
+		value, ok := ingress.Annotations[annotation]
+		if !ok {
+			return nil
+		}
+		seconds, err := strconv.Atoi(value)
+		if err != nil || seconds <= 0 {
+			errs = append(errs, field.Invalid(fieldPath.Key(annotation), value, "must be a positive number of seconds"))
+			return nil
+		}
+		return &seconds
+	}
+
+	timeouts := proxyTimeouts{
+		connect: parse(proxyConnectTimeoutAnnotation),
+		read:    parse(proxyReadTimeoutAnnotation),
+		send:    parse(proxySendTimeoutAnnotation),
+	}
+	return timeouts, errs
+}
+
+func notifyProxyTimeouts(ingress *networkingv1.Ingress, timeouts proxyTimeouts) {
+	if timeouts.read != nil {
+		notifications.NotificationAggr.DispatchNotification(notifications.NewNotification(
+			notifications.WarningNotification,
+			readTimeoutMessage(fmt.Sprintf("%s %ds", proxyReadTimeoutAnnotation, *timeouts.read), *timeouts.read),
+			ingress,
+		), Name)
+	}
+	if timeouts.send != nil {
+		notifications.NotificationAggr.DispatchNotification(notifications.NewNotification(
+			notifications.WarningNotification,
+			fmt.Sprintf("%s %ds cannot be converted: it limits the time between two successive writes of the request to the backend, which has no Gateway API equivalent",
+				proxySendTimeoutAnnotation, *timeouts.send),
+			ingress,
+		), Name)
+	}
+	if timeouts.connect != nil {
+		notifications.NotificationAggr.DispatchNotification(notifications.NewNotification(
+			notifications.WarningNotification,
+			fmt.Sprintf("%s %ds cannot be converted: it limits the time to establish the connection to the backend, which has no Gateway API equivalent and must be configured with the Gateway implementation",
+				proxyConnectTimeoutAnnotation, *timeouts.connect),
+			ingress,
+		), Name)
+	}
+}
+
+// readTimeoutMessage warns that the read timeout of the given setting is
+// converted to total deadlines.
+func readTimeoutMessage(setting string, seconds int) string {
+	return fmt.Sprintf("%s is converted to request and backendRequest timeouts, which are total deadlines of the request, while nginx only limits the time between two successive reads of the response: responses streamed for longer than %ds are cut",
+		setting, seconds)
+}
+
+func toDuration(seconds int) gatewayv1.Duration {
+	return gatewayv1.Duration(strconv.Itoa(seconds) + "s")
+}
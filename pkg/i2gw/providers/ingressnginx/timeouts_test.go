@@ -0,0 +1,116 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingressnginx
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/providers/common"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/types"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+func Test_timeoutsFeature(t *testing.T) {
+	testCases := []struct {
+		name                  string
+		ingresses             []networkingv1.Ingress
+		expectedTimeouts      *gatewayv1.HTTPRouteTimeouts
+		expectedNotifications []string
+	}{{
+		name: "read timeout",
+		ingresses: []networkingv1.Ingress{
+			testIngress("a", "example.com", "/", networkingv1.PathTypePrefix, map[string]string{
+				proxyReadTimeoutAnnotation: "3600",
+			}),
+		},
+		expectedTimeouts: &gatewayv1.HTTPRouteTimeouts{
+			Request:        ptrTo(gatewayv1.Duration("3600s")),
+			BackendRequest: ptrTo(gatewayv1.Duration("3600s")),
+		},
+		expectedNotifications: []string{"responses streamed for longer than 3600s are cut"},
+	}, {
+		name: "invalid timeouts are ignored",
+		ingresses: []networkingv1.Ingress{
+			testIngress("a", "example.com", "/", networkingv1.PathTypePrefix, map[string]string{
+				proxyReadTimeoutAnnotation: "60s",
+			}),
+		},
+		expectedNotifications: []string{"must be a positive number of seconds: the annotation is ignored"},
+	}, {
+		name: "connect and send timeouts",
+		ingresses: []networkingv1.Ingress{
+			testIngress("a", "example.com", "/", networkingv1.PathTypePrefix, map[string]string{
+				proxyConnectTimeoutAnnotation: "10",
+				proxySendTimeoutAnnotation:    "120",
+			}),
+		},
+		expectedNotifications: []string{
+			"nginx.ingress.kubernetes.io/proxy-connect-timeout 10s cannot be converted",
+			"nginx.ingress.kubernetes.io/proxy-send-timeout 120s cannot be converted",
+		},
+	}, {
+		name: "canary ingress timeouts are ignored",
+		ingresses: []networkingv1.Ingress{
+			testIngress("a", "example.com", "/", networkingv1.PathTypePrefix, map[string]string{
+				proxyReadTimeoutAnnotation: "30",
+			}),
+			testIngress("b", "example.com", "/", networkingv1.PathTypePrefix, map[string]string{
+				"nginx.ingress.kubernetes.io/canary":        "true",
+				"nginx.ingress.kubernetes.io/canary-weight": "10",
+				proxyReadTimeoutAnnotation:                  "90",
+			}),
+		},
+		expectedTimeouts: &gatewayv1.HTTPRouteTimeouts{
+			Request:        ptrTo(gatewayv1.Duration("30s")),
+			BackendRequest: ptrTo(gatewayv1.Duration("30s")),
+		},
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			gatewayResources, notificationTable := convertTestIngresses(t, &i2gw.ProviderConf{}, tc.ingresses...)
+
+			key := types.NamespacedName{Namespace: "default", Name: common.RouteName(tc.ingresses[0].Name, "example.com")}
+			httpRoute, ok := gatewayResources.HTTPRoutes[key]
+			if !ok || len(httpRoute.Spec.Rules) != 1 {
+				t.Fatalf("Expected HTTPRoute %s with a single rule, got %+v", key, gatewayResources.HTTPRoutes)
+			}
+			if diff := cmp.Diff(tc.expectedTimeouts, httpRoute.Spec.Rules[0].Timeouts); diff != "" {
+				t.Errorf("Unexpected timeouts, diff (-want +got):\n%s", diff)
+			}
+			for _, expected := range tc.expectedNotifications {
+				if !strings.Contains(notificationTable, expected) {
+					t.Errorf("Expected notification %q, got:\n%s", expected, notificationTable)
+				}
+			}
+		})
+	}
+}
+
+func Test_parseProxyTimeouts(t *testing.T) {
+	ingress := testIngress("a", "example.com", "/", networkingv1.PathTypePrefix, map[string]string{
+		proxyReadTimeoutAnnotation: "60s",
+		proxySendTimeoutAnnotation: "0",
+	})
+	if _, errs := parseProxyTimeouts(ingress); len(errs) != 2 {
+		t.Errorf("Expected 2 errors, got %+v", errs)
+	}
+}
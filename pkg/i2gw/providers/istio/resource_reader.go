@@ -21,13 +21,13 @@ import (
 	"context"
 	"fmt"
 	"log"
-	"os"
 
 	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw"
 	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/providers/common"
 	istiov1beta1 "istio.io/client-go/pkg/apis/networking/v1beta1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 )
 
@@ -62,7 +62,7 @@ func (r *reader) readResourcesFromCluster(ctx context.Context) (*storage, error)
 }
 
 func (r *reader) readResourcesFromFile(_ context.Context, filename string) (*storage, error) {
-	stream, err := os.ReadFile(filename)
+	stream, err := r.conf.ReadInputFile(filename)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read file %v: %w", filename, err)
 	}
@@ -120,53 +120,42 @@ func (r *reader) readUnstructuredObjects(objects []*unstructured.Unstructured) (
 }
 
 func (r *reader) readGatewaysFromCluster(ctx context.Context) (map[types.NamespacedName]*istiov1beta1.Gateway, error) {
-	gatewayList := &unstructured.UnstructuredList{}
-	gatewayList.SetAPIVersion(APIVersion)
-	gatewayList.SetKind(GatewayKind)
-
-	err := r.conf.Client.List(ctx, gatewayList)
-	if err != nil {
-		return nil, fmt.Errorf("failed to list istio gateways: %w", err)
-	}
-
 	res := map[types.NamespacedName]*istiov1beta1.Gateway{}
-	for _, obj := range gatewayList.Items {
+	err := r.conf.Cache.Stream(ctx, schema.FromAPIVersionAndKind(APIVersion, GatewayKind), func(obj *unstructured.Unstructured) error {
 		var gw istiov1beta1.Gateway
 		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.UnstructuredContent(), &gw); err != nil {
-			return nil, fmt.Errorf("failed to parse istio gateway object: %w", err)
+			return fmt.Errorf("failed to parse istio gateway object: %w", err)
 		}
 
 		res[types.NamespacedName{
 			Namespace: gw.Namespace,
 			Name:      gw.Name,
 		}] = &gw
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list istio gateways: %w", err)
 	}
 
 	return res, nil
 }
 
 func (r *reader) readVirtualServicesFromCluster(ctx context.Context) (map[types.NamespacedName]*istiov1beta1.VirtualService, error) {
-	virtualServicesList := &unstructured.UnstructuredList{}
-	virtualServicesList.SetAPIVersion(APIVersion)
-	virtualServicesList.SetKind(VirtualServiceKind)
-
-	err := r.conf.Client.List(ctx, virtualServicesList)
-	if err != nil {
-		return nil, fmt.Errorf("failed to list istio virtual services: %w", err)
-	}
-
 	res := map[types.NamespacedName]*istiov1beta1.VirtualService{}
-
-	for _, obj := range virtualServicesList.Items {
+	err := r.conf.Cache.Stream(ctx, schema.FromAPIVersionAndKind(APIVersion, VirtualServiceKind), func(obj *unstructured.Unstructured) error {
 		var vs istiov1beta1.VirtualService
 		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.UnstructuredContent(), &vs); err != nil {
-			return nil, fmt.Errorf("failed to parse istio virtual service object: %w", err)
+			return fmt.Errorf("failed to parse istio virtual service object: %w", err)
 		}
 
 		res[types.NamespacedName{
 			Namespace: vs.Namespace,
 			Name:      vs.Name,
 		}] = &vs
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list istio virtual services: %w", err)
 	}
 
 	return res, nil
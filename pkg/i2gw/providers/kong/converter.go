@@ -32,8 +32,8 @@ type converter struct {
 }
 
 // newConverter returns an kong converter instance.
-func newConverter() *converter {
-	return &converter{
+func newConverter(conf *i2gw.ProviderConf) *converter {
+	c := &converter{
 		featureParsers: []i2gw.FeatureParser{
 			headerMatchingFeature,
 			methodMatchingFeature,
@@ -43,6 +43,10 @@ func newConverter() *converter {
 			ToImplementationSpecificHTTPPathTypeMatch: implementationSpecificHTTPPathTypeMatch,
 		},
 	}
+	if conf.PreserveIngressAddresses {
+		c.featureParsers = append(c.featureParsers, common.IngressStatusAddressesFeature(Name))
+	}
+	return c
 }
 
 func (c *converter) convert(storage *storage) (i2gw.GatewayResources, field.ErrorList) {
@@ -81,5 +85,9 @@ func (c *converter) convert(storage *storage) (i2gw.GatewayResources, field.Erro
 		errorList = append(errorList, errs...)
 	}
 
+	if storage.ControllerService != nil {
+		errorList = append(errorList, common.ControllerServiceFeature(Name, storage.ControllerService)(ingressList, &gatewayResources)...)
+	}
+
 	return gatewayResources, errorList
 }
@@ -20,7 +20,6 @@ import (
 	"bytes"
 	"context"
 	"fmt"
-	"os"
 
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -50,7 +49,7 @@ func newResourceReader(conf *i2gw.ProviderConf) *resourceReader {
 func (r *resourceReader) readResourcesFromCluster(ctx context.Context) (*storage, error) {
 	storage := newResourceStorage()
 
-	ingresses, err := common.ReadIngressesFromCluster(ctx, r.conf.Client, sets.New(KongIngressClass))
+	ingresses, err := common.ReadIngressesFromCluster(ctx, r.conf.Cache, sets.New(KongIngressClass))
 	if err != nil {
 		return nil, err
 	}
@@ -62,13 +61,18 @@ func (r *resourceReader) readResourcesFromCluster(ctx context.Context) (*storage
 	}
 	storage.TCPIngresses = tcpIngresses
 
+	storage.ControllerService, err = common.ReadControllerServiceFromCluster(ctx, r.conf, Name)
+	if err != nil {
+		return nil, err
+	}
+
 	return storage, nil
 }
 
 func (r *resourceReader) readResourcesFromFile(filename string) (*storage, error) {
 	storage := newResourceStorage()
 
-	ingresses, err := common.ReadIngressesFromFile(filename, r.conf.Namespace, sets.New(KongIngressClass))
+	ingresses, err := common.ReadIngressesFromFile(filename, r.conf, sets.New(KongIngressClass))
 	if err != nil {
 		return nil, err
 	}
@@ -80,6 +84,11 @@ func (r *resourceReader) readResourcesFromFile(filename string) (*storage, error
 	}
 	storage.TCPIngresses = tcpIngresses
 
+	storage.ControllerService, err = common.ReadControllerServiceFromFile(filename, r.conf, Name)
+	if err != nil {
+		return nil, err
+	}
+
 	return storage, nil
 }
 
@@ -88,29 +97,25 @@ func (r *resourceReader) readResourcesFromFile(filename string) (*storage, error
 // -----------------------------------------------------------------------------
 
 func (r *resourceReader) readTCPIngressesFromCluster(ctx context.Context) ([]kongv1beta1.TCPIngress, error) {
-	tcpIngressList := &unstructured.UnstructuredList{}
-	tcpIngressList.SetGroupVersionKind(tcpIngressGVK)
-
-	err := r.conf.Client.List(ctx, tcpIngressList)
-	if err != nil {
-		return nil, fmt.Errorf("failed to list %s: %w", tcpIngressGVK.GroupKind().String(), err)
-	}
-
 	tcpIngresses := []kongv1beta1.TCPIngress{}
-	for _, obj := range tcpIngressList.Items {
+	err := r.conf.Cache.Stream(ctx, tcpIngressGVK, func(obj *unstructured.Unstructured) error {
 		var tcpIngress kongv1beta1.TCPIngress
 		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.UnstructuredContent(), &tcpIngress); err != nil {
-			return nil, fmt.Errorf("failed to parse Kong TCPIngress object: %w", err)
+			return fmt.Errorf("failed to parse Kong TCPIngress object: %w", err)
 		}
 
 		tcpIngresses = append(tcpIngresses, tcpIngress)
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	return tcpIngresses, nil
 }
 
 func (r *resourceReader) readTCPIngressesFromFile(filename string) ([]kongv1beta1.TCPIngress, error) {
-	stream, err := os.ReadFile(filename)
+	stream, err := r.conf.ReadInputFile(filename)
 	if err != nil {
 		return nil, err
 	}
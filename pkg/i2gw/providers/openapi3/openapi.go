@@ -55,6 +55,7 @@ func init() {
 }
 
 type Provider struct {
+	conf      *i2gw.ProviderConf
 	storage   Storage
 	converter Converter
 }
@@ -64,6 +65,7 @@ var _ i2gw.Provider = &Provider{}
 // NewProvider returns an implementation of i2gw.Provider that converts OpenAPI specs to Gateway API resources.
 func NewProvider(conf *i2gw.ProviderConf) i2gw.Provider {
 	return &Provider{
+		conf:      conf,
 		storage:   NewResourceStorage(),
 		converter: NewConverter(conf),
 	}
@@ -76,7 +78,7 @@ func (p *Provider) ReadResourcesFromCluster(_ context.Context) error {
 
 // ReadResourcesFromFile reads OpenAPI specs from a JSON or YAML file.
 func (p *Provider) ReadResourcesFromFile(ctx context.Context, filename string) error {
-	spec, err := readSpecFromFile(ctx, filename)
+	spec, err := readSpecFromFile(ctx, p.conf, filename)
 	if err != nil {
 		return fmt.Errorf("failed to read resources from file: %w", err)
 	}
@@ -94,9 +96,17 @@ func (p *Provider) ToGatewayAPI() (i2gw.GatewayResources, field.ErrorList) {
 	return p.converter.Convert(p.storage)
 }
 
-func readSpecFromFile(ctx context.Context, filename string) (*openapi3.T, error) {
+func readSpecFromFile(ctx context.Context, conf *i2gw.ProviderConf, filename string) (*openapi3.T, error) {
 	loader := openapi3.NewLoader()
-	spec, err := loader.LoadFromFile(filename)
+	var (
+		spec *openapi3.T
+		err  error
+	)
+	if conf.InputManifest != nil {
+		spec, err = loader.LoadFromData(conf.InputManifest)
+	} else {
+		spec, err = loader.LoadFromFile(filename)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to load OpenAPI spec: %w", err)
 	}